@@ -0,0 +1,37 @@
+package blobstore
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when the blob with given key doesn't exist.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey is returned when a blob key contains characters other than letters, digits, '-' and '_'.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore keeps opaque blobs by their keys. LocalStore is the only implementation for now, other implementations
+// (e.g. object storages) can be added without changing the callers.
+type BlobStore interface {
+	// Put streams r into the blob with given key and returns the number of written bytes. An existing blob with the
+	// same key is overwritten.
+	Put(key string, r io.Reader) (int64, error)
+	// Open returns a reader of the blob with given key. The caller must close the reader.
+	Open(key string) (io.ReadCloser, error)
+	// Delete deletes the blob with given key. Deleting a blob which doesn't exist is not an error.
+	Delete(key string) error
+}
+
+// validateKey ensures keys can be used as file names and object names as they are.
+func validateKey(key string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
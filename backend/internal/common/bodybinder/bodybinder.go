@@ -19,6 +19,15 @@ type validationErrorResponseItem struct {
 	Reason string `json:"reason" binding:"required"`
 }
 
+// FieldError is a validation failure of a request field which is found by the controller after binding, such as a
+// check which needs the database or the configuration. Tag and Param are the name and the parameter of the failed
+// rule like the binding tags, e.g. Tag "min" and Param "12".
+type FieldError struct {
+	Field string
+	Tag   string
+	Param string
+}
+
 // Bind runs gin.Context.ShouldBind() function underneath, returns true if binding is successful. If binding
 // is unsuccessful, writes appropriate error responses to gin context and returns false. Controller functions
 // typically just returns itself when this function returns false.
@@ -35,15 +44,29 @@ func Bind(obj any, c *gin.Context) (ok bool) {
 	return true
 }
 
+// RespondFieldErrors writes given field errors to gin context with HTTP 422 Unprocessable Entity, in the same format
+// as the validation errors of Bind.
+func RespondFieldErrors(c *gin.Context, fieldErrors []FieldError) {
+	var errs []validationErrorResponseItem
+	for _, f := range fieldErrors {
+		errs = append(errs, validationErrorResponseItem{Field: f.Field, Reason: formatReason(f.Tag, f.Param)})
+	}
+	c.JSON(http.StatusUnprocessableEntity, validationErrorResponse{Errors: errs})
+}
+
 // marshalValErrors returns list of fields, which failed on validation, and their fail reasons.
 func marshalValErrors(valErrors validator.ValidationErrors) []validationErrorResponseItem {
 	var errs []validationErrorResponseItem
 	for _, f := range valErrors {
-		err := f.ActualTag()
-		if f.Param() != "" {
-			err = fmt.Sprintf("%s=%s", err, f.Param())
-		}
-		errs = append(errs, validationErrorResponseItem{Field: f.Field(), Reason: err})
+		errs = append(errs, validationErrorResponseItem{Field: f.Field(), Reason: formatReason(f.ActualTag(), f.Param())})
 	}
 	return errs
 }
+
+// formatReason returns the fail reason of a validation rule, the parameter of the rule is appended if it has one.
+func formatReason(tag, param string) string {
+	if param != "" {
+		return fmt.Sprintf("%s=%s", tag, param)
+	}
+	return tag
+}
@@ -0,0 +1,258 @@
+package mailer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMailer records the sent mails. Sending blocks until unblock is closed if it is set.
+type recordingMailer struct {
+	mu      sync.Mutex
+	sent    []Message
+	unblock chan struct{}
+	err     error
+}
+
+func (m *recordingMailer) Send(msg Message) error {
+	if m.unblock != nil {
+		<-m.unblock
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return m.err
+}
+
+func (m *recordingMailer) sentCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+func TestNewMessage(t *testing.T) {
+	msg, err := NewMessage("user@example.com", "verify_email", map[string]any{
+		"Name": "User", "VerifyURL": "https://example.com/verify?token=abc", "ExpiresInHours": 24,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Verify your LetusPass email address" {
+		t.Fatalf("subject is %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Body, "https://example.com/verify?token=abc") {
+		t.Fatalf("body doesn't contain the verification URL: %q", msg.Body)
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mails.log")
+	mailer := NewFileMailer(path)
+
+	for _, to := range []string{"first@example.com", "second@example.com"} {
+		if err := mailer.Send(Message{To: to, Subject: "Subject", Body: "Body"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"To: first@example.com\nSubject: Subject\n\nBody\n", "To: second@example.com\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("mail file doesn't contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestFileMailerRejectsHeaderInjection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mails.log")
+	mailer := NewFileMailer(path)
+
+	if err := mailer.Send(Message{To: "user@example.com\r\nBcc: attacker@example.com", Subject: "Subject"}); err == nil {
+		t.Fatal("mail with a line break in the recipient is sent")
+	}
+	if err := mailer.Send(Message{To: "user@example.com", Subject: "Subject\nBcc: attacker@example.com"}); err == nil {
+		t.Fatal("mail with a line break in the subject is sent")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("mail file is created: %v", err)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	mailer := &recordingMailer{err: errors.New("send failed")}
+	failed := make(chan Message, 3)
+	queue := NewQueue(mailer, 3, 2, func(msg Message, err error) { failed <- msg })
+
+	for range 3 {
+		if err := queue.Send(Message{To: "user@example.com", Subject: "Subject"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 3 {
+		select {
+		case <-failed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("onError isn't called for every failed mail")
+		}
+	}
+	if mailer.sentCount() != 3 {
+		t.Fatalf("%d mails are sent, want 3", mailer.sentCount())
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	mailer := &recordingMailer{unblock: make(chan struct{})}
+	queue := NewQueue(mailer, 1, 1, func(msg Message, err error) { t.Error(err) })
+
+	// the worker takes the first mail and blocks, the second one waits in the queue
+	if err := queue.Send(Message{To: "user@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(queue.messages) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker doesn't take the mail")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := queue.Send(Message{To: "user@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Send(Message{To: "user@example.com"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Send returned %v, want ErrQueueFull", err)
+	}
+	if err := queue.Send(Message{To: "user@example.com\nBcc: attacker@example.com"}); err == nil {
+		t.Fatal("mail with a line break in the recipient is queued")
+	}
+
+	close(mailer.unblock)
+	deadline = time.Now().Add(5 * time.Second)
+	for mailer.sentCount() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d mails are sent, want 2", mailer.sentCount())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// serveFakeSMTP accepts a single connection on a random port and replies to it like a minimal SMTP server. Returns
+// the address of the server and a channel which receives the data of the sent mail.
+func serveFakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, received := serveFakeSMTP(t)
+	mailer, err := NewSMTPMailer("127.0.0.1", 25, "", "", "LetusPass <no-reply@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailer.addr = addr
+
+	if err := mailer.Send(Message{To: "user@example.com", Subject: "Subject", Body: "Line 1\nLine 2\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data := <-received
+	for _, want := range []string{"From: \"LetusPass\" <no-reply@example.com>\r\n", "To: user@example.com\r\n",
+		"Subject: Subject\r\n", "\r\n\r\nLine 1\r\nLine 2\r\n"} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("mail doesn't contain %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestSMTPMailerTimeout(t *testing.T) {
+	// the server accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+	}()
+
+	mailer, err := NewSMTPMailer("127.0.0.1", 25, "", "", "no-reply@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailer.addr = listener.Addr().String()
+	mailer.timeout = 100 * time.Millisecond
+
+	done := make(chan error, 1)
+	go func() { done <- mailer.Send(Message{To: "user@example.com", Subject: "Subject"}) }()
+	select {
+	case err := <-done:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("Send returned %v, want a timeout error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send doesn't time out")
+	}
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"errors"
+)
+
+// ErrQueueFull is returned when a mail can't be queued because the queue is full.
+var ErrQueueFull = errors.New("mail queue is full")
+
+// Queue sends mails through another Mailer in the background with a fixed number of workers, so a slow mail server
+// neither delays the requests nor piles up goroutines. Mails are dropped with ErrQueueFull if the queue is full.
+type Queue struct {
+	mailer   Mailer
+	messages chan Message
+	onError  func(msg Message, err error)
+}
+
+// NewQueue starts workers which send the queued mails through mailer. At most size mails wait in the queue. onError
+// is called from the workers when sending a mail fails.
+func NewQueue(mailer Mailer, size, workers int, onError func(msg Message, err error)) *Queue {
+	q := &Queue{mailer: mailer, messages: make(chan Message, size), onError: onError}
+	for range workers {
+		go q.work()
+	}
+	return q
+}
+
+// Send queues the mail and returns without waiting for it to be sent.
+func (q *Queue) Send(msg Message) error {
+	if err := validateHeaders(msg); err != nil {
+		return err
+	}
+	select {
+	case q.messages <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *Queue) work() {
+	for msg := range q.messages {
+		if err := q.mailer.Send(msg); err != nil {
+			q.onError(msg, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	Entry
+	expiresAt time.Time
+}
+
+// MemoryStore is a Store which keeps the failure records in the process memory. Records are lost on restart and
+// aren't shared between instances of the application.
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]memoryEntry
+	lastPruneAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+func (s *MemoryStore) Reserve(keys []string, now time.Time, ttl time.Duration, wait func(Entry) time.Duration,
+) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now, ttl)
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		retryAfter = max(retryAfter, wait(s.get(key, now).Entry))
+	}
+	if retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	for _, key := range keys {
+		entry := s.get(key, now)
+		entry.Failures++
+		entry.LastFailureAt = now
+		entry.expiresAt = now.Add(ttl)
+		s.entries[key] = entry
+	}
+	return 0, nil
+}
+
+func (s *MemoryStore) Release(key string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.get(key, now)
+	if entry.Failures == 0 {
+		return nil
+	}
+	entry.Failures--
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// get returns the record of key, or a zero record if key has no failures or its record expired. s.mu must be held.
+func (s *MemoryStore) get(key string, now time.Time) memoryEntry {
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return memoryEntry{}
+	}
+	return entry
+}
+
+// prune removes the expired records at most once every ttl so that keys which are never used again don't stay in
+// the memory.
+func (s *MemoryStore) prune(now time.Time, ttl time.Duration) {
+	if now.Sub(s.lastPruneAt) < ttl {
+		return
+	}
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.lastPruneAt = now
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+// Entry is the failure record of a key.
+type Entry struct {
+	Failures      int
+	LastFailureAt time.Time
+}
+
+// Store keeps failure records of keys. MemoryStore keeps them in the process memory, a store shared between
+// instances of the application can be used by implementing this interface.
+type Store interface {
+	// Reserve returns the longest wait of the records of keys calculated by wait, and adds a failure at now to every
+	// record if none of them has to wait. Checking and adding must be atomic. Zero Entry is passed to wait if a key
+	// has no failures or its record expired. Records expire if no failure is added to them for ttl.
+	Reserve(keys []string, now time.Time, ttl time.Duration, wait func(Entry) time.Duration) (time.Duration, error)
+	// Release removes a failure from the record of key.
+	Release(key string, now time.Time) error
+	// Reset removes the failure record of key.
+	Reset(key string) error
+}
+
+// Config configures the backoff of a Limiter.
+type Config struct {
+	// FreeFailures is the number of failures allowed before delaying the next attempt.
+	FreeFailures int
+	// BaseDelay is the delay after the first failure exceeding FreeFailures, it doubles with every failure after
+	// that up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window is the duration without failures after which the failures of a key are forgotten.
+	Window time.Duration
+}
+
+// Limiter delays attempts of keys with exponential backoff based on their failures.
+type Limiter struct {
+	store  Store
+	config Config
+}
+
+func NewLimiter(store Store, config Config) *Limiter {
+	return &Limiter{store: store, config: config}
+}
+
+// Reserve counts an attempt with given keys as a failure before it's made, so concurrent attempts can't get past the
+// limit together. If one of the keys has to wait, nothing is counted and how long should be waited is returned. Zero
+// is returned if the attempt is allowed now. Release should be called if the attempt succeeds.
+func (l *Limiter) Reserve(now time.Time, keys ...string) (time.Duration, error) {
+	return l.store.Reserve(keys, now, l.config.Window, func(entry Entry) time.Duration {
+		return entry.LastFailureAt.Add(l.delay(entry.Failures)).Sub(now)
+	})
+}
+
+// Release takes back the failure counted by Reserve from every given key.
+func (l *Limiter) Release(now time.Time, keys ...string) error {
+	for _, key := range keys {
+		if err := l.store.Release(key, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset forgets the failures of given keys.
+func (l *Limiter) Reset(keys ...string) error {
+	for _, key := range keys {
+		if err := l.store.Reset(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) delay(failures int) time.Duration {
+	if failures <= l.config.FreeFailures {
+		return 0
+	}
+	delay := l.config.BaseDelay
+	for i := l.config.FreeFailures + 1; i < failures && delay < l.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, l.config.MaxDelay)
+}
@@ -9,6 +9,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	RegistrationModeOpen   = "open"
+	RegistrationModeClosed = "closed"
+	RegistrationModeInvite = "invite"
+)
+
 type RestapiConfig struct {
 	GinMode string
 	LogFile string
@@ -24,7 +30,78 @@ type RestapiConfig struct {
 	SessionTokenCookieName    string
 	SessionTokenExpireSeconds int
 
+	WebAuthnRPID          string
+	WebAuthnRPDisplayName string
+	WebAuthnRPOrigins     []string
+
+	// Login attempts are delayed with exponential backoff per IP and per email after LoginRateLimitFreeFailures
+	// failures. Accounts are locked for LoginLockoutSeconds after LoginLockoutThreshold consecutive failures.
+	LoginRateLimitFreeFailures     int
+	LoginRateLimitBaseDelaySeconds int
+	LoginRateLimitMaxDelaySeconds  int
+	LoginRateLimitWindowSeconds    int
+	LoginLockoutThreshold          int
+	LoginLockoutSeconds            int
+
+	// New passwords must be at least PasswordMinLength characters long and have an estimated entropy of at least
+	// PasswordMinEntropyBits. If BreachedPasswordsDir is set, they are checked against the breached password hashes
+	// in it, see authservice.NewPasswordPolicy for the format.
+	PasswordMinLength      int
+	PasswordMinEntropyBits int
+	BreachedPasswordsDir   string
+
+	// OIDC login is enabled when OIDCIssuerURL is set. OIDCRedirectURL is the callback URL of the backend registered
+	// at the identity provider and the browser is redirected to OIDCFrontendURL after the callback. Users without an
+	// account are created on their first login if OIDCAutoProvision is true and the registration policy allows them to
+	// register, i.e. RegistrationMode is open and their email domain is allowed.
+	OIDCIssuerURL     string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCFrontendURL   string
+	OIDCAutoProvision bool
+
+	// Mails are sent through the SMTP server if MailDriver is "smtp" and appended to MailFile if it is "file".
+	// FrontendURL is used to build the links in the mails.
+	MailDriver   string
+	MailFrom     string
+	MailFile     string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	FrontendURL  string
+
+	// Registered users aren't active until they verify their email address if EmailVerificationRequired is true.
+	EmailVerificationRequired           bool
+	EmailVerificationTokenExpireSeconds int
+
+	// Users with AdminEmails get the admin role when they verify their email address and on startup if they already
+	// verified it. Bootstrap admins need to verify their email even if EmailVerificationRequired is false.
+	AdminEmails []string
+
+	// RegistrationMode is one of RegistrationModeOpen, RegistrationModeClosed and RegistrationModeInvite, invite mode
+	// requires an invite code issued by an admin. If RegistrationAllowedDomains isn't empty, only emails of those
+	// domains can register.
+	RegistrationMode           string
+	RegistrationAllowedDomains []string
+
 	CORSAllowOrigins []string
+
+	// TrustedProxies are the IPs and CIDRs of the reverse proxies in front of the backend. Client IP is read from the
+	// X-Forwarded-For header only if the request comes from one of them, it's used for rate limiting login attempts.
+	TrustedProxies []string
+
+	TrashRetentionSeconds int
+
+	// AttachmentsDir is the directory the encrypted attachment files are kept in. AttachmentMaxSizeBytes limits the
+	// size of a single attachment and VaultAttachmentQuotaBytes the total size of the attachments of a vault.
+	AttachmentsDir            string
+	AttachmentMaxSizeBytes    int64
+	VaultAttachmentQuotaBytes int64
+
+	// Share links of vault items expire at most SecretShareMaxLifetimeSeconds after they are created.
+	SecretShareMaxLifetimeSeconds int
 }
 
 // NewRestapiConfigFromEnv creates a RestapiConfig from environment variables. It panics if converting types
@@ -35,6 +112,11 @@ func NewRestapiConfigFromEnv() RestapiConfig {
 		log.Fatal("SESSION_TOKEN_EXPIRE_SECONDS env must be a valid integer")
 	}
 
+	trashRetentionSeconds, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_SECONDS"))
+	if err != nil {
+		log.Fatal("TRASH_RETENTION_SECONDS env must be a valid integer")
+	}
+
 	var ginMode string
 	switch os.Getenv("GIN_MODE") {
 	case "debug":
@@ -47,6 +129,13 @@ func NewRestapiConfigFromEnv() RestapiConfig {
 		log.Fatal("GIN_MODE must be one of these: debug, test, release.")
 	}
 
+	registrationMode := os.Getenv("REGISTRATION_MODE")
+	switch registrationMode {
+	case RegistrationModeOpen, RegistrationModeClosed, RegistrationModeInvite:
+	default:
+		log.Fatal("REGISTRATION_MODE must be one of these: open, closed, invite.")
+	}
+
 	return RestapiConfig{
 		GinMode: ginMode,
 		LogFile: os.Getenv("LOG_FILE"),
@@ -62,6 +151,86 @@ func NewRestapiConfigFromEnv() RestapiConfig {
 		SessionTokenCookieName:    os.Getenv("SESSION_TOKEN_COOKIE_NAME"),
 		SessionTokenExpireSeconds: sessionTokenExpireSeconds,
 
+		WebAuthnRPID:          os.Getenv("WEBAUTHN_RP_ID"),
+		WebAuthnRPDisplayName: os.Getenv("WEBAUTHN_RP_DISPLAY_NAME"),
+		WebAuthnRPOrigins:     strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ","),
+
+		LoginRateLimitFreeFailures:     mustAtoiEnv("LOGIN_RATE_LIMIT_FREE_FAILURES"),
+		LoginRateLimitBaseDelaySeconds: mustAtoiEnv("LOGIN_RATE_LIMIT_BASE_DELAY_SECONDS"),
+		LoginRateLimitMaxDelaySeconds:  mustAtoiEnv("LOGIN_RATE_LIMIT_MAX_DELAY_SECONDS"),
+		LoginRateLimitWindowSeconds:    mustAtoiEnv("LOGIN_RATE_LIMIT_WINDOW_SECONDS"),
+		LoginLockoutThreshold:          mustAtoiEnv("LOGIN_LOCKOUT_THRESHOLD"),
+		LoginLockoutSeconds:            mustAtoiEnv("LOGIN_LOCKOUT_SECONDS"),
+
+		PasswordMinLength:      mustAtoiEnv("PASSWORD_MIN_LENGTH"),
+		PasswordMinEntropyBits: mustAtoiEnv("PASSWORD_MIN_ENTROPY_BITS"),
+		BreachedPasswordsDir:   os.Getenv("BREACHED_PASSWORDS_DIR"),
+
+		OIDCIssuerURL:     os.Getenv("OIDC_ISSUER_URL"),
+		OIDCClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCFrontendURL:   os.Getenv("OIDC_FRONTEND_URL"),
+		OIDCAutoProvision: mustParseBoolEnv("OIDC_AUTO_PROVISION"),
+
+		MailDriver:   os.Getenv("MAIL_DRIVER"),
+		MailFrom:     os.Getenv("MAIL_FROM"),
+		MailFile:     os.Getenv("MAIL_FILE"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     mustAtoiEnv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		FrontendURL:  os.Getenv("FRONTEND_URL"),
+
+		EmailVerificationRequired:           mustParseBoolEnv("EMAIL_VERIFICATION_REQUIRED"),
+		EmailVerificationTokenExpireSeconds: mustAtoiEnv("EMAIL_VERIFICATION_TOKEN_EXPIRE_SECONDS"),
+
+		AdminEmails: splitNonEmpty(os.Getenv("ADMIN_EMAILS"), ","),
+
+		RegistrationMode:           registrationMode,
+		RegistrationAllowedDomains: splitNonEmpty(strings.ToLower(os.Getenv("REGISTRATION_ALLOWED_DOMAINS")), ","),
+
 		CORSAllowOrigins: strings.Split(os.Getenv("CORS_ALLOW_ORIGINS"), ","),
+
+		TrustedProxies: splitNonEmpty(os.Getenv("TRUSTED_PROXIES"), ","),
+
+		TrashRetentionSeconds: trashRetentionSeconds,
+
+		AttachmentsDir:            os.Getenv("ATTACHMENTS_DIR"),
+		AttachmentMaxSizeBytes:    int64(mustAtoiEnv("ATTACHMENT_MAX_SIZE_BYTES")),
+		VaultAttachmentQuotaBytes: int64(mustAtoiEnv("VAULT_ATTACHMENT_QUOTA_BYTES")),
+
+		SecretShareMaxLifetimeSeconds: mustAtoiEnv("SECRET_SHARE_MAX_LIFETIME_SECONDS"),
+	}
+}
+
+// mustAtoiEnv returns the integer value of the environment variable with given name. It exits if the value isn't a
+// valid integer.
+func mustAtoiEnv(name string) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		log.Fatal(name + " env must be a valid integer")
+	}
+	return value
+}
+
+// mustParseBoolEnv returns the boolean value of the environment variable with given name. It exits if the value isn't
+// a valid boolean.
+func mustParseBoolEnv(name string) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		log.Fatal(name + " env must be a valid boolean")
+	}
+	return value
+}
+
+// splitNonEmpty splits s by sep and leaves out the empty parts, so an empty string results in an empty slice.
+func splitNonEmpty(s, sep string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(s, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
 	}
+	return parts
 }
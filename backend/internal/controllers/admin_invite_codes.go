@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/orderbyparam"
+	"github.com/berk-karaal/letuspass/backend/internal/common/pagination"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleAdminInviteCodesList
+//
+//	@Summary	List invite codes
+//	@Tags		admin
+//	@Id			adminInviteCodesList
+//	@Produce	json
+//	@Param		page		query		int		false	"Page number"			default(1)	minimum(1)
+//	@Param		page_size	query		int		false	"Item count per page"	default(10)
+//	@Param		ordering	query		string	false	"Ordering"				Enums(created_at, -created_at, expires_at, -expires_at)
+//	@Success	200			{object}	pagination.StandardPaginationResponse[controllers.HandleAdminInviteCodesList.InviteCodeResponseItem]
+//	@Failure	401
+//	@Failure	403
+//	@Failure	500
+//	@Router		/admin/invite-codes [get]
+func HandleAdminInviteCodesList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type InviteCodeResponseItem struct {
+		Id              uint       `json:"id" binding:"required"`
+		CreatedByUserId uint       `json:"created_by_user_id" binding:"required"`
+		CreatedAt       time.Time  `json:"created_at" binding:"required"`
+		ExpiresAt       time.Time  `json:"expires_at" binding:"required"`
+		UsedByUserId    *uint      `json:"used_by_user_id"`
+		UsedAt          *time.Time `json:"used_at"`
+	}
+
+	return func(c *gin.Context) {
+		ordering, err := orderbyparam.GenerateOrdering(c, map[string]string{
+			"created_at": "invite_codes.created_at",
+			"expires_at": "invite_codes.expires_at",
+		}, "-created_at")
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Generating query ordering from params failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var count int64
+		err = db.Model(&models.InviteCode{}).Count(&count).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying invite codes count failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []InviteCodeResponseItem{}
+		err = db.Model(&models.InviteCode{}).Scopes(pagination.Paginate(c)).
+			Select("invite_codes.id, invite_codes.created_by_user_id, invite_codes.created_at, " +
+				"invite_codes.expires_at, invite_codes.used_by_user_id, invite_codes.used_at").
+			Order(ordering).Scan(&results).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying invite codes failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, pagination.StandardPaginationResponse[InviteCodeResponseItem]{
+			Results: results,
+			Count:   int(count),
+		})
+	}
+}
+
+// HandleAdminInviteCodesCreate
+//
+//	@Summary		Create invite code
+//	@Description	The invite code can be used once to register while the registration mode is invite. The code is
+//	@Description	only returned in this response.
+//	@Tags			admin
+//	@Id				adminInviteCodesCreate
+//	@Param			request	body	controllers.HandleAdminInviteCodesCreate.CreateInviteCodeRequest	true	"Invite code data"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleAdminInviteCodesCreate.CreateInviteCodeResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/admin/invite-codes [post]
+func HandleAdminInviteCodesCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type CreateInviteCodeRequest struct {
+		ExpiresInDays int `json:"expires_in_days" binding:"required,min=1,max=90"`
+	}
+
+	type CreateInviteCodeResponse struct {
+		Id        uint      `json:"id" binding:"required"`
+		Code      string    `json:"code" binding:"required"`
+		ExpiresAt time.Time `json:"expires_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		admin, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData CreateInviteCodeRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		inviteCode, rawCode, err := authservice.CreateInviteCode(db, admin,
+			time.Now().AddDate(0, 0, requestData.ExpiresInDays))
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating invite code failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", admin.ID).Uint("invite_code_id", inviteCode.ID).
+			Msg("Invite code created.")
+
+		c.JSON(http.StatusCreated, CreateInviteCodeResponse{Id: inviteCode.ID, Code: rawCode,
+			ExpiresAt: inviteCode.ExpiresAt})
+	}
+}
+
+// HandleAdminInviteCodesRevoke
+//
+//	@Summary		Revoke invite code
+//	@Description	Used invite codes can't be revoked.
+//	@Tags			admin
+//	@Id				adminInviteCodesRevoke
+//	@Param			id	path	int	true	"Invite code id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		500
+//	@Router			/admin/invite-codes/{id} [delete]
+func HandleAdminInviteCodesRevoke(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		inviteCodeId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		admin, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = authservice.RevokeInviteCode(db, uint(inviteCodeId))
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.InviteCodeNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Invite code not found."})
+			case errors.Is(err, authservice.InviteCodeAlreadyUsedErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{Error: "Invite code is already used."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Revoking invite code failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", admin.ID).Int("invite_code_id", inviteCodeId).
+			Msg("Invite code revoked.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/orderbyparam"
+	"github.com/berk-karaal/letuspass/backend/internal/common/pagination"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	adminservice "github.com/berk-karaal/letuspass/backend/internal/services/admin"
+	groupservice "github.com/berk-karaal/letuspass/backend/internal/services/group"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// likePatternEscaper escapes the wildcards of LIKE patterns, so search terms are matched literally.
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// HandleAdminUsersList
+//
+//	@Summary		List users
+//	@Description	search matches the email or the name of the user.
+//	@Tags			admin
+//	@Id				adminUsersList
+//	@Produce		json
+//	@Param			search		query		string	false	"Search term"
+//	@Param			is_active	query		bool	false	"Filter by active status"
+//	@Param			page		query		int		false	"Page number"			default(1)	minimum(1)
+//	@Param			page_size	query		int		false	"Item count per page"	default(10)
+//	@Param			ordering	query		string	false	"Ordering"				Enums(email, -email, created_at, -created_at)
+//	@Success		200			{object}	pagination.StandardPaginationResponse[controllers.HandleAdminUsersList.UserResponseItem]
+//	@Failure		400			{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500
+//	@Router			/admin/users [get]
+func HandleAdminUsersList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type UserResponseItem struct {
+		Id              uint       `json:"id" binding:"required"`
+		Email           string     `json:"email" binding:"required"`
+		Name            string     `json:"name" binding:"required"`
+		Role            string     `json:"role" binding:"required"`
+		IsActive        bool       `json:"is_active" binding:"required"`
+		EmailVerifiedAt *time.Time `json:"email_verified_at"`
+		DeactivatedAt   *time.Time `json:"deactivated_at"`
+		TOTPEnabled     bool       `json:"totp_enabled" binding:"required"`
+		CreatedAt       time.Time  `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		ordering, err := orderbyparam.GenerateOrdering(c, map[string]string{
+			"email":      "users.email",
+			"created_at": "users.created_at",
+		}, "email")
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Generating query ordering from params failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		query := db.Model(&models.User{})
+		if search := c.Query("search"); search != "" {
+			pattern := "%" + likePatternEscaper.Replace(strings.ToLower(search)) + "%"
+			query = query.Where("LOWER(users.email) LIKE ? OR LOWER(users.name) LIKE ?", pattern, pattern)
+		}
+		if isActiveParam := c.Query("is_active"); isActiveParam != "" {
+			isActive, err := strconv.ParseBool(isActiveParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "is_active must be a boolean."})
+				return
+			}
+			query = query.Where("users.is_active = ?", isActive)
+		}
+
+		var count int64
+		err = query.Session(&gorm.Session{}).Count(&count).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying users count failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []UserResponseItem{}
+		err = query.Scopes(pagination.Paginate(c)).
+			Select("users.id, users.email, users.name, users.role, users.is_active, users.email_verified_at, " +
+				"users.deactivated_at, users.totp_enabled, users.created_at").
+			Order(ordering).Scan(&results).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying users failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, pagination.StandardPaginationResponse[UserResponseItem]{
+			Results: results,
+			Count:   int(count),
+		})
+	}
+}
+
+// HandleAdminUsersDeactivate
+//
+//	@Summary		Deactivate user
+//	@Description	Every session of the user is revoked and the user can't login or use their personal access tokens
+//	@Description	until they are reactivated.
+//	@Tags			admin
+//	@Id				adminUsersDeactivate
+//	@Param			id	path	int	true	"User id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/admin/users/{id}/deactivate [post]
+func HandleAdminUsersDeactivate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		userId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		admin, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		_, err = adminservice.DeactivateUser(db, admin, uint(userId))
+		if err != nil {
+			switch {
+			case errors.Is(err, adminservice.SelfManagementErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Admins can't deactivate themselves."})
+			case errors.Is(err, adminservice.UserNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "User not found."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deactivating user failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", admin.ID).Int("target_user_id", userId).
+			Msg("User deactivated.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleAdminUsersReactivate
+//
+//	@Summary		Reactivate user
+//	@Description	If email verification is required, users who haven't verified their email address stay inactive
+//	@Description	until they verify it. 400 is returned if the user isn't deactivated.
+//	@Tags			admin
+//	@Id				adminUsersReactivate
+//	@Param			id	path	int	true	"User id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/admin/users/{id}/reactivate [post]
+func HandleAdminUsersReactivate(apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		userId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		admin, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		_, err = adminservice.ReactivateUser(db, uint(userId), apiConfig.EmailVerificationRequired)
+		if err != nil {
+			switch {
+			case errors.Is(err, adminservice.UserNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "User not found."})
+			case errors.Is(err, adminservice.UserNotDeactivatedErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User isn't deactivated."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Reactivating user failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", admin.ID).Int("target_user_id", userId).
+			Msg("User reactivated.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleAdminUsersDelete
+//
+//	@Summary		Delete user
+//	@Description	The user is removed from their vaults and groups, vaults and groups which don't have any other
+//	@Description	member are deleted. 409 is returned if the user is the last manager of a vault or a group which
+//	@Description	has other members, another member must be given the manager permission first.
+//	@Tags			admin
+//	@Id				adminUsersDelete
+//	@Param			id	path	int	true	"User id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		500
+//	@Router			/admin/users/{id} [delete]
+func HandleAdminUsersDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		userId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		admin, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = adminservice.DeleteUser(db, admin, uint(userId))
+		if err != nil {
+			switch {
+			case errors.Is(err, adminservice.SelfManagementErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Admins can't delete themselves."})
+			case errors.Is(err, adminservice.UserNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "User not found."})
+			case errors.Is(err, vaultservice.LastVaultManagerErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{
+					Error: "User is the last manager of a vault which has other members."})
+			case errors.Is(err, groupservice.LastGroupManagerErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{
+					Error: "User is the last manager of a group which has other members."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting user failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", admin.ID).Int("target_user_id", userId).
+			Msg("User deleted.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
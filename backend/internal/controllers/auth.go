@@ -3,48 +3,105 @@ package controllers
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
 	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/common/ratelimit"
 	"github.com/berk-karaal/letuspass/backend/internal/config"
 	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
 	"github.com/berk-karaal/letuspass/backend/internal/models"
 	"github.com/berk-karaal/letuspass/backend/internal/schemas"
 	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 )
 
+// privateKeyEnvelope is the private key of the user encrypted on the client, see authservice.PrivateKeyEnvelope.
+type privateKeyEnvelope struct {
+	Version             int    `json:"version" binding:"required"`
+	EncryptionIV        string `json:"encryption_iv" binding:"required"`
+	EncryptedPrivateKey string `json:"encrypted_private_key" binding:"required"`
+}
+
+// newPrivateKeyEnvelope returns the stored private key envelope of the user, or nil if the user hasn't stored one.
+func newPrivateKeyEnvelope(user models.User) *privateKeyEnvelope {
+	envelope, ok := authservice.GetPrivateKeyEnvelope(user)
+	if !ok {
+		return nil
+	}
+	return &privateKeyEnvelope{
+		Version:             envelope.Version,
+		EncryptionIV:        envelope.EncryptionIV,
+		EncryptedPrivateKey: envelope.EncryptedPrivateKey,
+	}
+}
+
+func (e *privateKeyEnvelope) toPrivateKeyEnvelope() *authservice.PrivateKeyEnvelope {
+	if e == nil {
+		return nil
+	}
+	return &authservice.PrivateKeyEnvelope{
+		Version:             e.Version,
+		EncryptionIV:        e.EncryptionIV,
+		EncryptedPrivateKey: e.EncryptedPrivateKey,
+	}
+}
+
 // HandleAuthLogin
 //
-//	@Summary	Login user
-//	@Tags		auth
-//	@Id			authLogin
-//	@Param		request	body	controllers.HandleAuthLogin.LoginRequest	true	"Login credentials"
-//	@Produce	json
-//	@Success	200	{object}	controllers.HandleAuthLogin.LoginResponse
-//	@Failure	400	{object}	schemas.BadRequestResponse
-//	@Failure	422	{object}	bodybinder.validationErrorResponse
-//	@Failure	500
-//	@Router		/auth/login [post]
-func HandleAuthLogin(apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+//	@Summary		Login user
+//	@Description	If the user has two-factor authentication enabled, no session is created and 202 is returned with
+//	@Description	a token which should be sent to the second step of the login together with the second factor. The
+//	@Description	attempt counts as failed until the second factor is verified. webauthn_options are passed to navigator.credentials.get() if the user has a passkey.
+//	@Description	Failed logins are delayed per IP and email, 429 is returned with Retry-After header when the next
+//	@Description	attempt is delayed. The account is locked temporarily after too many consecutive failures, logins
+//	@Description	to a locked account fail with wrong credentials like logins with an unknown email.
+//	@Description	403 is returned if the user is deactivated or hasn't verified their email address yet.
+//	@Tags			auth
+//	@Id				authLogin
+//	@Param			request	body	controllers.HandleAuthLogin.LoginRequest	true	"Login credentials"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleAuthLogin.LoginResponse
+//	@Success		202	{object}	controllers.HandleAuthLogin.TwoFactorRequiredResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		403	{object}	schemas.ForbiddenResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/auth/login [post]
+func HandleAuthLogin(apiConfig *config.RestapiConfig, webAuthn *webauthn.WebAuthn, loginLimiter *ratelimit.Limiter, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type LoginRequest struct {
 		Email    string `json:"email" binding:"required"`
 		Password string `json:"password" binding:"required"`
 	}
 
 	type LoginResponse struct {
-		Email             string `json:"email" binding:"required"`
-		Name              string `json:"name" binding:"required"`
-		KeyDerivationSalt string `json:"key_derivation_salt" binding:"required"`
+		Email             string              `json:"email" binding:"required"`
+		Name              string              `json:"name" binding:"required"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+	}
+
+	type TwoFactorRequiredResponse struct {
+		TwoFactorToken string   `json:"two_factor_token" binding:"required"`
+		Methods        []string `json:"methods" binding:"required"`
+		// WebAuthnOptions is set if webauthn is one of the methods.
+		WebAuthnOptions *protocol.CredentialAssertion `json:"webauthn_options" swaggertype:"object"`
 	}
 
 	return func(c *gin.Context) {
 		isAlreadyAuthenticated := true
-		_, _, err := middlewares.GetCurrentUser(c, apiConfig, db)
+		_, _, _, err := middlewares.GetCurrentUser(c, apiConfig, db)
 		if err != nil {
 			if errors.Is(err, middlewares.UserNotAuthenticatedErr{}) {
 				isAlreadyAuthenticated = false
@@ -64,10 +121,19 @@ func HandleAuthLogin(apiConfig *config.RestapiConfig, logger *logging.Logger, db
 			return
 		}
 
+		timeNow := time.Now()
+		ipKey, emailKey := loginRateLimitKeys(c, requestData.Email)
+		if !reserveRateLimit(c, loginLimiter, logger, timeNow, "Too many failed login attempts, try again later.",
+			ipKey, emailKey) {
+			return
+		}
+
 		var user models.User
 		err = db.First(&user, "email = ?", requestData.Email).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// take as long as checking the password of an existing user
+				authservice.CompareDummyPassword(requestData.Password)
 				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong credentials."})
 				return
 			}
@@ -82,58 +148,304 @@ func HandleAuthLogin(apiConfig *config.RestapiConfig, logger *logging.Logger, db
 			c.Status(http.StatusInternalServerError)
 			return
 		}
+		// locked accounts respond like unknown emails, so the lock doesn't reveal which emails are registered
+		if authservice.IsUserLocked(user, timeNow) {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong credentials."})
+			return
+		}
 		if !ok {
+			recordFailedLogin(c, apiConfig, logger, db, user)
 			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong credentials."})
 			return
 		}
 
-		session := models.UserSession{
-			Token:     authservice.GenerateSessionToken(),
-			UserID:    user.ID,
-			ExpiresAt: time.Now().Add(time.Second * time.Duration(apiConfig.SessionTokenExpireSeconds)),
-			UserAgent: c.Request.UserAgent(),
+		if !checkUserIsActive(c, user) {
+			return
 		}
 
-		err = db.Create(&session).Error
+		twoFactorMethods, err := authservice.GetTwoFactorMethods(db, user)
 		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting two-factor methods failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if len(twoFactorMethods) > 0 {
+			// the attempt stays counted until the second factor is verified, so a known password doesn't give free
+			// guesses of the second factor
+			twoFactorToken, webAuthnOptions, err := authservice.CreateLoginChallenge(db, webAuthn, user, twoFactorMethods)
+			if err != nil {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating login challenge failed.")
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			c.JSON(http.StatusAccepted, TwoFactorRequiredResponse{
+				TwoFactorToken:  twoFactorToken,
+				Methods:         twoFactorMethods,
+				WebAuthnOptions: webAuthnOptions,
+			})
+			return
+		}
+
+		clearFailedLogins(c, loginLimiter, logger, db, user, timeNow)
+		if err := startUserSession(c, apiConfig, mail, logger, db, user); err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating user session failed.")
 			c.Status(http.StatusInternalServerError)
 			return
 		}
 
-		c.SetSameSite(http.SameSiteLaxMode)
-		c.SetCookie(apiConfig.SessionTokenCookieName, session.Token, apiConfig.SessionTokenExpireSeconds, "/", "localhost", false, true)
+		c.JSON(http.StatusOK, LoginResponse{
+			Email:             user.Email,
+			Name:              user.Name,
+			KeyDerivationSalt: user.KeyDerivationSalt,
+			PrivateKey:        newPrivateKeyEnvelope(user),
+		})
+	}
+}
 
-		c.JSON(http.StatusOK, LoginResponse{Email: user.Email, Name: user.Name, KeyDerivationSalt: user.KeyDerivationSalt})
+// checkUserIsActive responds with 403 if the user is deactivated by an admin or hasn't verified their email address
+// yet. Returns true if the login can continue.
+func checkUserIsActive(c *gin.Context, user models.User) bool {
+	if user.IsActive {
+		return true
+	}
+	if user.DeactivatedAt != nil {
+		c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{Error: "Account is deactivated."})
+	} else {
+		c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{
+			Error: "Email address isn't verified, use the link in the verification mail."})
 	}
+	return false
+}
+
+// loginRateLimitKeys returns the rate limit keys of the login attempts from the IP of the request and to given email.
+func loginRateLimitKeys(c *gin.Context, email string) (ipKey, emailKey string) {
+	return "login:ip:" + c.ClientIP(), "login:email:" + strings.ToLower(email)
+}
+
+// recordFailedLogin saves a failed password or second factor attempt of the user, which locks the account after too
+// many consecutive failures.
+func recordFailedLogin(c *gin.Context, apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB, user models.User) {
+	locked, err := authservice.RecordFailedLogin(db, user, c.ClientIP(), c.Request.UserAgent(),
+		apiConfig.LoginLockoutThreshold, time.Second*time.Duration(apiConfig.LoginLockoutSeconds))
+	if err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Recording failed login failed.")
+	}
+	if locked {
+		logger.RequestEvent(zerolog.WarnLevel, c).Uint("user_id", user.ID).Msg("Account locked because of failed logins.")
+	}
+}
+
+// clearFailedLogins releases the attempt reserved for the IP of the request, and resets the rate limit of the email
+// and the failed login count of the user. It is called once the user passed every login factor.
+func clearFailedLogins(c *gin.Context, loginLimiter *ratelimit.Limiter, logger *logging.Logger, db *gorm.DB, user models.User, now time.Time) {
+	ipKey, emailKey := loginRateLimitKeys(c, user.Email)
+	if err := loginLimiter.Release(now, ipKey); err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Releasing login rate limit failed.")
+	}
+	if err := loginLimiter.Reset(emailKey); err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Resetting login rate limit failed.")
+	}
+	if err := authservice.ResetFailedLoginCount(db, user); err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Resetting failed login count failed.")
+	}
+}
+
+// reserveRateLimit counts the request as a failed attempt of the keys in advance, or responds with 429 and given
+// message if any of the keys is rate limited. Returns true if the request can continue.
+func reserveRateLimit(c *gin.Context, limiter *ratelimit.Limiter, logger *logging.Logger, now time.Time, message string, keys ...string) bool {
+	retryAfter, err := limiter.Reserve(now, keys...)
+	if err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking rate limit failed.")
+		c.Status(http.StatusInternalServerError)
+		return false
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, schemas.TooManyRequestsResponse{Error: message})
+		return false
+	}
+	return true
+}
+
+// HandleAuthLoginTOTP
+//
+//	@Summary		Complete login with a TOTP or recovery code
+//	@Description	Wrong codes count as failed logins of the user and lock the account like wrong passwords. Attempts
+//	@Description	are delayed per IP and email like the logins, codes of a locked account are rejected as wrong.
+//	@Tags			auth
+//	@Id				authLoginTOTP
+//	@Param			request	body	controllers.HandleAuthLoginTOTP.LoginTOTPRequest	true	"Login challenge token and the code"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleAuthLoginTOTP.LoginResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		403	{object}	schemas.ForbiddenResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/auth/login/totp [post]
+func HandleAuthLoginTOTP(apiConfig *config.RestapiConfig, loginLimiter *ratelimit.Limiter, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type LoginTOTPRequest struct {
+		TwoFactorToken string `json:"two_factor_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+
+	type LoginResponse struct {
+		Email             string              `json:"email" binding:"required"`
+		Name              string              `json:"name" binding:"required"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData LoginTOTPRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		challengeUser, err := authservice.GetLoginChallengeUser(db, requestData.TwoFactorToken)
+		if err != nil {
+			if errors.Is(err, authservice.LoginChallengeNotFoundErr{}) {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Login session expired, login again."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting user of login challenge failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		// codes are limited like passwords since a challenge only allows a few attempts but new ones are free
+		timeNow := time.Now()
+		ipKey, emailKey := loginRateLimitKeys(c, challengeUser.Email)
+		if !reserveRateLimit(c, loginLimiter, logger, timeNow, "Too many failed login attempts, try again later.",
+			ipKey, emailKey) {
+			return
+		}
+		if authservice.IsUserLocked(challengeUser, timeNow) {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong code."})
+			return
+		}
+
+		user, err := authservice.CompleteLoginChallengeWithTOTP(db, requestData.TwoFactorToken, requestData.Code)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.LoginChallengeNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Login session expired, login again."})
+			case errors.Is(err, authservice.InvalidTOTPCodeErr{}):
+				recordFailedLogin(c, apiConfig, logger, db, challengeUser)
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong code."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Completing login challenge failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if !checkUserIsActive(c, user) {
+			return
+		}
+
+		clearFailedLogins(c, loginLimiter, logger, db, user, timeNow)
+		if err := startUserSession(c, apiConfig, mail, logger, db, user); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating user session failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, LoginResponse{
+			Email:             user.Email,
+			Name:              user.Name,
+			KeyDerivationSalt: user.KeyDerivationSalt,
+			PrivateKey:        newPrivateKeyEnvelope(user),
+		})
+	}
+}
+
+// startUserSession creates a new session for the user and sets the session cookie. The user is notified by mail if
+// the login is from a new device.
+func startUserSession(c *gin.Context, apiConfig *config.RestapiConfig, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB, user models.User) error {
+	timeNow := time.Now()
+	sendNewLoginMail(c, mail, logger, db, user, timeNow)
+
+	session := models.UserSession{
+		Token:      authservice.GenerateSessionToken(),
+		UserID:     user.ID,
+		ExpiresAt:  timeNow.Add(time.Second * time.Duration(apiConfig.SessionTokenExpireSeconds)),
+		UserAgent:  c.Request.UserAgent(),
+		LastSeenAt: timeNow,
+		LastSeenIP: c.ClientIP(),
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return err
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(apiConfig.SessionTokenCookieName, session.Token, apiConfig.SessionTokenExpireSeconds, "/", "localhost", false, true)
+	return nil
 }
 
 // HandleAuthRegister
 //
-//	@Summary	Register user
-//	@Tags		auth
-//	@Id			authRegister
-//	@Param		request	body	controllers.HandleAuthRegister.RegisterRequest	true	"User Registration Data"
-//	@Produce	json
-//	@Success	201
-//	@Failure	400	{object}	schemas.BadRequestResponse
-//	@Failure	422	{object}	bodybinder.validationErrorResponse
-//	@Failure	500
-//	@Router		/auth/register [post]
-func HandleAuthRegister(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+//	@Summary		Register user
+//	@Description	If email verification is required, a verification mail is sent and the user can't login until the
+//	@Description	email is verified. 403 is returned if the registration policy doesn't allow the registration, see
+//	@Description	/auth/registration-policy. invite_code is required if the registration mode is invite. The password
+//	@Description	must meet the password policy.
+//	@Tags			auth
+//	@Id				authRegister
+//	@Param			request	body	controllers.HandleAuthRegister.RegisterRequest	true	"User Registration Data"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleAuthRegister.RegisterResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		403	{object}	schemas.ForbiddenResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/auth/register [post]
+func HandleAuthRegister(apiConfig *config.RestapiConfig, passwordPolicy *authservice.PasswordPolicy, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type RegisterRequest struct {
-		Email             string `json:"email" binding:"required,email"`
-		Password          string `json:"password" binding:"required"`
-		Name              string `json:"name" binding:"required"`
-		KeyDerivationSalt string `json:"key_derivation_salt" binding:"required"`
-		PublicKey         string `json:"public_key" binding:"required"`
+		Email             string              `json:"email" binding:"required,email"`
+		Password          string              `json:"password" binding:"required,max=72"`
+		Name              string              `json:"name" binding:"required"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PublicKey         string              `json:"public_key" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+		InviteCode        string              `json:"invite_code"`
+	}
+
+	type RegisterResponse struct {
+		EmailVerificationRequired bool `json:"email_verification_required" binding:"required"`
 	}
 
 	return func(c *gin.Context) {
+		if apiConfig.RegistrationMode == config.RegistrationModeClosed {
+			c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{Error: "Registration is closed."})
+			return
+		}
+
 		var requestData RegisterRequest
 		if !bodybinder.Bind(&requestData, c) {
 			return
 		}
+		if !checkPasswordPolicy(c, passwordPolicy, logger, "password", requestData.Password) {
+			return
+		}
+
+		if !isRegistrationEmailDomainAllowed(apiConfig, requestData.Email) {
+			c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{
+				Error: "Registration isn't allowed for the domain of this email address."})
+			return
+		}
+		if apiConfig.RegistrationMode == config.RegistrationModeInvite && requestData.InviteCode == "" {
+			c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{Error: "Registration requires an invite code."})
+			return
+		}
+
+		if requestData.PrivateKey != nil {
+			err := authservice.ValidatePrivateKeyEnvelope(*requestData.PrivateKey.toPrivateKeyEnvelope())
+			if err != nil {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Private key envelope version is not supported."})
+				return
+			}
+		}
 
 		var exists bool
 		err := db.Model(&models.User{}).Select("count(*) > 0").Where("email = ?", requestData.Email).Scan(&exists).Error
@@ -158,18 +470,83 @@ func HandleAuthRegister(logger *logging.Logger, db *gorm.DB) func(c *gin.Context
 			Email:             requestData.Email,
 			Password:          hashedPassword,
 			Name:              requestData.Name,
+			Role:              models.UserRoleUser,
 			IsActive:          true,
 			KeyDerivationSalt: requestData.KeyDerivationSalt,
 			PublicKey:         requestData.PublicKey,
 		}
-		err = db.Create(&newUser).Error
+		if requestData.PrivateKey != nil {
+			newUser.PrivateKeyEnvelopeVersion = requestData.PrivateKey.Version
+			newUser.PrivateKeyEncryptionIV = requestData.PrivateKey.EncryptionIV
+			newUser.EncryptedPrivateKey = requestData.PrivateKey.EncryptedPrivateKey
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&newUser).Error; err != nil {
+				return err
+			}
+			if apiConfig.RegistrationMode == config.RegistrationModeInvite {
+				if err := authservice.UseInviteCode(tx, requestData.InviteCode, newUser.ID); err != nil {
+					return err
+				}
+			}
+			if !apiConfig.EmailVerificationRequired {
+				return nil
+			}
+			// Create doesn't insert false since is_active has a default value
+			newUser.IsActive = false
+			return tx.Model(&newUser).Update("is_active", false).Error
+		})
 		if err != nil {
+			if errors.Is(err, authservice.InvalidInviteCodeErr{}) {
+				c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{
+					Error: "Invite code is invalid, expired or already used."})
+				return
+			}
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating new user failed.")
 			c.Status(http.StatusInternalServerError)
 			return
 		}
 
-		c.Status(http.StatusCreated)
+		if apiConfig.EmailVerificationRequired {
+			// the user can request the mail again if this fails
+			if err := sendVerificationMail(c, apiConfig, mail, logger, db, newUser); err != nil {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Sending verification mail failed.")
+			}
+		}
+
+		c.JSON(http.StatusCreated, RegisterResponse{EmailVerificationRequired: apiConfig.EmailVerificationRequired})
+	}
+}
+
+// isRegistrationEmailDomainAllowed returns true if the registration policy allows the domain of given email address.
+func isRegistrationEmailDomainAllowed(apiConfig *config.RestapiConfig, email string) bool {
+	if len(apiConfig.RegistrationAllowedDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	return at != -1 && slices.Contains(apiConfig.RegistrationAllowedDomains, strings.ToLower(email[at+1:]))
+}
+
+// HandleAuthRegistrationPolicy
+//
+//	@Summary		Get registration policy
+//	@Description	mode is open, closed or invite. Only emails of allowed_domains can register if it isn't empty.
+//	@Tags			auth
+//	@Id				authRegistrationPolicy
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleAuthRegistrationPolicy.RegistrationPolicyResponse
+//	@Router			/auth/registration-policy [get]
+func HandleAuthRegistrationPolicy(apiConfig *config.RestapiConfig) func(c *gin.Context) {
+	type RegistrationPolicyResponse struct {
+		Mode           string   `json:"mode" binding:"required"`
+		AllowedDomains []string `json:"allowed_domains" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, RegistrationPolicyResponse{
+			Mode:           apiConfig.RegistrationMode,
+			AllowedDomains: apiConfig.RegistrationAllowedDomains,
+		})
 	}
 }
 
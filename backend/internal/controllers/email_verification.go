@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/common/ratelimit"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	adminservice "github.com/berk-karaal/letuspass/backend/internal/services/admin"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// sendVerificationMail creates a new email verification token for the user and mails the verification link.
+func sendVerificationMail(c *gin.Context, apiConfig *config.RestapiConfig, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB, user models.User) error {
+	ttl := time.Second * time.Duration(apiConfig.EmailVerificationTokenExpireSeconds)
+	token, err := authservice.CreateEmailVerificationToken(db, user, ttl)
+	if err != nil {
+		return err
+	}
+
+	sendMail(c, mail, logger, user.Email, "verify_email", map[string]any{
+		"Name":           user.Name,
+		"VerifyURL":      apiConfig.FrontendURL + "/verify-email?token=" + url.QueryEscape(token),
+		"ExpiresInHours": int(ttl.Hours()),
+	})
+	return nil
+}
+
+// HandleAuthVerifyEmail
+//
+//	@Summary		Verify email address
+//	@Description	Activates the user with the token from the verification mail.
+//	@Tags			auth
+//	@Id				authVerifyEmail
+//	@Param			request	body	controllers.HandleAuthVerifyEmail.VerifyEmailRequest	true	"Token from the verification mail"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/auth/verify-email [post]
+func HandleAuthVerifyEmail(apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type VerifyEmailRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData VerifyEmailRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		user, err := authservice.VerifyEmail(db, requestData.Token)
+		if err != nil {
+			if errors.Is(err, authservice.EmailVerificationTokenNotFoundErr{}) {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Verification link is invalid or expired."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Verifying email failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Email verified.")
+
+		if slices.Contains(apiConfig.AdminEmails, user.Email) {
+			if err := adminservice.PromoteAdmins(db, []string{user.Email}); err != nil {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Promoting admin failed.")
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Admin promoted.")
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleAuthVerifyEmailResend
+//
+//	@Summary		Send the verification mail again
+//	@Description	Previous verification links stop working. 204 is returned whether a mail is sent or not, so the
+//	@Description	endpoint can't be used to find out registered emails. Requests are delayed per IP and email.
+//	@Tags			auth
+//	@Id				authVerifyEmailResend
+//	@Param			request	body	controllers.HandleAuthVerifyEmailResend.VerifyEmailResendRequest	true	"Email of the user"
+//	@Success		204
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/auth/verify-email/resend [post]
+func HandleAuthVerifyEmailResend(apiConfig *config.RestapiConfig, loginLimiter *ratelimit.Limiter, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type VerifyEmailResendRequest struct {
+		Email string `json:"email" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData VerifyEmailResendRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		timeNow := time.Now()
+		ipKey := "verify-email:ip:" + c.ClientIP()
+		emailKey := "verify-email:email:" + strings.ToLower(requestData.Email)
+		// every request counts, not only the failed ones
+		if !reserveRateLimit(c, loginLimiter, logger, timeNow, "Too many verification mails requested, try again later.",
+			ipKey, emailKey) {
+			return
+		}
+
+		var user models.User
+		err := db.First(&user, "email = ?", requestData.Email).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.Status(http.StatusNoContent)
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting user by email failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = sendVerificationMail(c, apiConfig, mail, logger, db, user)
+		if err != nil && !errors.Is(err, authservice.EmailAlreadyVerifiedErr{}) {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Sending verification mail failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
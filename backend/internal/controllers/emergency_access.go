@@ -0,0 +1,574 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleUsersMeEmergencyContactsList
+//
+//	@Summary	List emergency contacts of the user
+//	@Tags		emergency access
+//	@Id			usersMeEmergencyContactsList
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleUsersMeEmergencyContactsList.EmergencyContactResponse
+//	@Failure	401
+//	@Failure	500
+//	@Router		/users/me/emergency-contacts [get]
+func HandleUsersMeEmergencyContactsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type EmergencyContactResponse struct {
+		Id                uint       `json:"id" binding:"required"`
+		GranteeEmail      string     `json:"grantee_email" binding:"required"`
+		GranteeName       string     `json:"grantee_name" binding:"required"`
+		WaitingPeriodDays int        `json:"waiting_period_days" binding:"required"`
+		Status            string     `json:"status" binding:"required"`
+		RequestedAt       *time.Time `json:"requested_at"`
+		IsGranted         bool       `json:"is_granted" binding:"required"`
+		CreatedAt         time.Time  `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		accesses, err := authservice.ListEmergencyContacts(db, user.ID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Listing emergency contacts failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		timeNow := time.Now()
+		c.JSON(http.StatusOK, common.Map(accesses, func(access models.EmergencyAccess) EmergencyContactResponse {
+			return EmergencyContactResponse{
+				Id:                access.ID,
+				GranteeEmail:      access.GranteeUser.Email,
+				GranteeName:       access.GranteeUser.Name,
+				WaitingPeriodDays: access.WaitingPeriodDays,
+				Status:            access.Status,
+				RequestedAt:       access.RequestedAt,
+				IsGranted:         authservice.IsEmergencyAccessGranted(access, timeNow),
+				CreatedAt:         access.CreatedAt,
+			}
+		}))
+	}
+}
+
+// HandleUsersMeEmergencyContactsCreate
+//
+//	@Summary		Add an emergency contact
+//	@Description	The emergency contact can request access to the account, which is granted automatically after
+//	@Description	waiting_period_days unless the user denies the request. encrypted_private_key is the private key
+//	@Description	of the user encrypted with the private key of the user and the public key of the contact, like
+//	@Description	vault keys.
+//	@Tags			emergency access
+//	@Id				usersMeEmergencyContactsCreate
+//	@Param			request	body	controllers.HandleUsersMeEmergencyContactsCreate.CreateRequest	true	"Emergency contact data"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleUsersMeEmergencyContactsCreate.CreateResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/emergency-contacts [post]
+func HandleUsersMeEmergencyContactsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type CreateRequest struct {
+		Password            string `json:"password" binding:"required"`
+		GranteeEmail        string `json:"grantee_email" binding:"required,email"`
+		WaitingPeriodDays   int    `json:"waiting_period_days" binding:"required,min=1,max=90"`
+		EncryptionIV        string `json:"encryption_iv" binding:"required"`
+		EncryptedPrivateKey string `json:"encrypted_private_key" binding:"required"`
+	}
+
+	type CreateResponse struct {
+		Id uint `json:"id" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData CreateRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err := authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		access, err := authservice.CreateEmergencyAccess(db, user, requestData.GranteeEmail,
+			requestData.WaitingPeriodDays, requestData.EncryptionIV, requestData.EncryptedPrivateKey)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.EmergencyAccessGranteeNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User with given email not found."})
+			case errors.Is(err, authservice.EmergencyAccessSelfGrantErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "You can't be your own emergency contact."})
+			case errors.Is(err, authservice.EmergencyAccessAlreadyExistsErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{Error: "User is already an emergency contact."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating emergency access failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Uint("grantee_user_id", access.GranteeUserID).
+			Msg("Emergency contact added.")
+
+		c.JSON(http.StatusCreated, CreateResponse{Id: access.ID})
+	}
+}
+
+// HandleUsersMeEmergencyContactsDelete
+//
+//	@Summary	Remove an emergency contact
+//	@Tags		emergency access
+//	@Id			usersMeEmergencyContactsDelete
+//	@Param		id	path	int	true	"Emergency access id"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/users/me/emergency-contacts/{id} [delete]
+func HandleUsersMeEmergencyContactsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return handleEmergencyAccessDelete(logger, db)
+}
+
+// HandleUsersMeEmergencyContactsApprove
+//
+//	@Summary		Approve access request of an emergency contact
+//	@Description	Access is granted immediately instead of waiting for the waiting period to pass.
+//	@Tags			emergency access
+//	@Id				usersMeEmergencyContactsApprove
+//	@Param			id	path	int	true	"Emergency access id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/users/me/emergency-contacts/{id}/approve [post]
+func HandleUsersMeEmergencyContactsApprove(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		accessId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = authservice.ApproveEmergencyAccess(db, user, uint(accessId))
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.EmergencyAccessNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Emergency contact not found."})
+			case errors.As(err, &authservice.EmergencyAccessStatusErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Access isn't requested."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Approving emergency access failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Int("emergency_access_id", accessId).
+			Msg("Emergency access approved.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeEmergencyContactsDeny
+//
+//	@Summary		Deny access request of an emergency contact
+//	@Description	Access which is already granted is revoked too. The contact can request access again later.
+//	@Tags			emergency access
+//	@Id				usersMeEmergencyContactsDeny
+//	@Param			id	path	int	true	"Emergency access id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/users/me/emergency-contacts/{id}/deny [post]
+func HandleUsersMeEmergencyContactsDeny(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		accessId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = authservice.DenyEmergencyAccess(db, user, uint(accessId))
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.EmergencyAccessNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Emergency contact not found."})
+			case errors.As(err, &authservice.EmergencyAccessStatusErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Access isn't requested."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Denying emergency access failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Int("emergency_access_id", accessId).
+			Msg("Emergency access denied.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeEmergencyGrantsList
+//
+//	@Summary	List emergency accesses granted to the user
+//	@Tags		emergency access
+//	@Id			usersMeEmergencyGrantsList
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleUsersMeEmergencyGrantsList.EmergencyGrantResponse
+//	@Failure	401
+//	@Failure	500
+//	@Router		/users/me/emergency-grants [get]
+func HandleUsersMeEmergencyGrantsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type EmergencyGrantResponse struct {
+		Id                uint       `json:"id" binding:"required"`
+		GrantorEmail      string     `json:"grantor_email" binding:"required"`
+		GrantorName       string     `json:"grantor_name" binding:"required"`
+		WaitingPeriodDays int        `json:"waiting_period_days" binding:"required"`
+		Status            string     `json:"status" binding:"required"`
+		RequestedAt       *time.Time `json:"requested_at"`
+		IsGranted         bool       `json:"is_granted" binding:"required"`
+		CreatedAt         time.Time  `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		accesses, err := authservice.ListEmergencyGrants(db, user.ID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Listing emergency grants failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		timeNow := time.Now()
+		c.JSON(http.StatusOK, common.Map(accesses, func(access models.EmergencyAccess) EmergencyGrantResponse {
+			return EmergencyGrantResponse{
+				Id:                access.ID,
+				GrantorEmail:      access.GrantorUser.Email,
+				GrantorName:       access.GrantorUser.Name,
+				WaitingPeriodDays: access.WaitingPeriodDays,
+				Status:            access.Status,
+				RequestedAt:       access.RequestedAt,
+				IsGranted:         authservice.IsEmergencyAccessGranted(access, timeNow),
+				CreatedAt:         access.CreatedAt,
+			}
+		}))
+	}
+}
+
+// HandleUsersMeEmergencyGrantsDelete
+//
+//	@Summary	Give up an emergency access granted to the user
+//	@Tags		emergency access
+//	@Id			usersMeEmergencyGrantsDelete
+//	@Param		id	path	int	true	"Emergency access id"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/users/me/emergency-grants/{id} [delete]
+func HandleUsersMeEmergencyGrantsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return handleEmergencyAccessDelete(logger, db)
+}
+
+// handleEmergencyAccessDelete deletes the emergency access with the id in the path. Both the grantor and the grantee
+// can delete it.
+func handleEmergencyAccessDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		accessId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = authservice.DeleteEmergencyAccess(db, user.ID, uint(accessId))
+		if err != nil {
+			if errors.Is(err, authservice.EmergencyAccessNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Emergency access not found."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting emergency access failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Int("emergency_access_id", accessId).
+			Msg("Emergency access deleted.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeEmergencyGrantsRequest
+//
+//	@Summary		Request emergency access to an account
+//	@Description	Access is granted when the grantor approves the request or the waiting period passes without the
+//	@Description	grantor denying it.
+//	@Tags			emergency access
+//	@Id				usersMeEmergencyGrantsRequest
+//	@Param			id	path	int	true	"Emergency access id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/users/me/emergency-grants/{id}/request [post]
+func HandleUsersMeEmergencyGrantsRequest(mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		accessId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		access, err := authservice.RequestEmergencyAccess(db, user, uint(accessId))
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.EmergencyAccessNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Emergency access not found."})
+			case errors.As(err, &authservice.EmergencyAccessStatusErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Access is already requested."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Requesting emergency access failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Uint("grantor_user_id", access.GrantorUserID).
+			Msg("Emergency access requested.")
+
+		var grantor models.User
+		if err := db.First(&grantor, access.GrantorUserID).Error; err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting grantor user failed.")
+		} else {
+			sendMail(c, mail, logger, grantor.Email, "emergency_access_requested", map[string]any{
+				"Name":              grantor.Name,
+				"GranteeEmail":      user.Email,
+				"WaitingPeriodDays": access.WaitingPeriodDays,
+			})
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeEmergencyGrantsTakeoverGet
+//
+//	@Summary		Get the keys needed to take over an account
+//	@Description	Returns the private key of the grantor encrypted for the user and the vault keys of the grantor
+//	@Description	which must be encrypted again with the new key pair of the grantor on takeover. Access must be
+//	@Description	granted.
+//	@Tags			emergency access
+//	@Id				usersMeEmergencyGrantsTakeoverGet
+//	@Param			id	path	int	true	"Emergency access id"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeEmergencyGrantsTakeoverGet.TakeoverResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/users/me/emergency-grants/{id}/takeover [get]
+func HandleUsersMeEmergencyGrantsTakeoverGet(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type TakeoverResponse struct {
+		GrantorPublicKey    string           `json:"grantor_public_key" binding:"required"`
+		EncryptionIV        string           `json:"encryption_iv" binding:"required"`
+		EncryptedPrivateKey string           `json:"encrypted_private_key" binding:"required"`
+		Owned               []ownedVaultKey  `json:"owned" binding:"required"`
+		Issued              []issuedVaultKey `json:"issued" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		accessId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		access, err := authservice.GetGrantedEmergencyAccess(db, user, uint(accessId))
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.EmergencyAccessNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Emergency access not found."})
+			case errors.Is(err, authservice.EmergencyAccessNotGrantedErr{}):
+				c.Status(http.StatusForbidden)
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting emergency access failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		owned, issued, err := authservice.GetUserVaultKeysToRewrap(db, access.GrantorUserID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault keys of grantor failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, TakeoverResponse{
+			GrantorPublicKey:    access.GrantorUser.PublicKey,
+			EncryptionIV:        access.EncryptionIV,
+			EncryptedPrivateKey: access.EncryptedPrivateKey,
+			Owned:               common.Map(owned, newOwnedVaultKey),
+			Issued:              common.Map(issued, newIssuedVaultKey),
+		})
+	}
+}
+
+// HandleUsersMeEmergencyGrantsTakeover
+//
+//	@Summary		Take over an account by setting a new password
+//	@Description	Works like password change of the grantor, every vault key returned from the takeover GET endpoint
+//	@Description	must be encrypted again with the new key pair of the grantor. Every session, the recovery key and
+//	@Description	the emergency accesses of the grantor are removed. Access must be granted.
+//	@Tags			emergency access
+//	@Id				usersMeEmergencyGrantsTakeover
+//	@Param			id		path	int																	true	"Emergency access id"
+//	@Param			request	body	controllers.HandleUsersMeEmergencyGrantsTakeover.TakeoverRequest	true	"New password of the grantor with the re-encrypted vault keys"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/emergency-grants/{id}/takeover [post]
+func HandleUsersMeEmergencyGrantsTakeover(passwordPolicy *authservice.PasswordPolicy, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type TakeoverRequest struct {
+		NewPassword       string              `json:"new_password" binding:"required,max=72"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PublicKey         string              `json:"public_key" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+		VaultKeys         []rewrappedVaultKey `json:"vault_keys" binding:"required,dive"`
+	}
+
+	return func(c *gin.Context) {
+		accessId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData TakeoverRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+		if !checkPasswordPolicy(c, passwordPolicy, logger, "new_password", requestData.NewPassword) {
+			return
+		}
+
+		grantor, err := authservice.TakeOverAccount(db, user, uint(accessId), requestData.NewPassword,
+			requestData.KeyDerivationSalt, requestData.PublicKey, requestData.PrivateKey.toPrivateKeyEnvelope(),
+			toRewrappedVaultKeys(requestData.VaultKeys))
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.EmergencyAccessNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Emergency access not found."})
+			case errors.Is(err, authservice.EmergencyAccessNotGrantedErr{}):
+				c.Status(http.StatusForbidden)
+			default:
+				respondChangePasswordError(c, logger, err)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.WarnLevel, c).Uint("user_id", user.ID).Uint("grantor_user_id", grantor.ID).
+			Msg("Account taken over with emergency access.")
+		sendPasswordChangedMail(c, mail, logger, grantor, "by your emergency contact "+user.Email)
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	groupservice "github.com/berk-karaal/letuspass/backend/internal/services/group"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleGroupsCreate
+//
+//	@Summary	Create a new group
+//	@Tags		groups
+//	@Id			createGroup
+//	@Param		request	body	controllers.HandleGroupsCreate.GroupCreateRequest	true	"New group data"
+//	@Produce	json
+//	@Success	201	{object}	controllers.HandleGroupsCreate.GroupCreateResponse
+//	@Failure	401
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/groups [post]
+func HandleGroupsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type GroupCreateRequest struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	type GroupCreateResponse struct {
+		Id   uint   `json:"id" binding:"required"`
+		Name string `json:"name" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData GroupCreateRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		group, err := groupservice.CreateGroup(db, user, requestData.Name)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating group failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, GroupCreateResponse{Id: group.ID, Name: group.Name})
+	}
+}
+
+// HandleGroupsList
+//
+//	@Summary	List groups that user is member of
+//	@Tags		groups
+//	@Id			listGroups
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleGroupsList.GroupResponseItem
+//	@Failure	401
+//	@Failure	500
+//	@Router		/groups [get]
+func HandleGroupsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type GroupResponseItem struct {
+		Id        uint   `json:"id" binding:"required"`
+		Name      string `json:"name" binding:"required"`
+		IsManager bool   `json:"is_manager" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []GroupResponseItem{}
+		err := db.Select("groups.id, groups.name, group_members.is_manager").
+			Model(&models.GroupMember{}).
+			Joins("INNER JOIN groups ON group_members.group_id = groups.id AND groups.deleted_at IS NULL").
+			Where("group_members.user_id = ?", user.ID).
+			Order("groups.name ASC").Scan(&results).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying user's groups failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// HandleGroupsRetrieve
+//
+//	@Summary	Retrieve group by id with its members
+//	@Tags		groups
+//	@Id			retrieveGroup
+//	@Param		id	path	int	true	"Group id"
+//	@Produce	json
+//	@Success	200	{object}	controllers.HandleGroupsRetrieve.GroupResponse
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	500
+//	@Router		/groups/{id} [get]
+func HandleGroupsRetrieve(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type GroupMemberResponseItem struct {
+		Id        uint   `json:"id" binding:"required"`
+		Email     string `json:"email" binding:"required"`
+		Name      string `json:"name" binding:"required"`
+		IsManager bool   `json:"is_manager" binding:"required"`
+	}
+
+	type GroupResponse struct {
+		Id      uint                      `json:"id" binding:"required"`
+		Name    string                    `json:"name" binding:"required"`
+		Members []GroupMemberResponseItem `json:"members" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		groupId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		isMember, _, err := groupservice.CheckUserIsGroupMember(db, user.ID, uint(groupId))
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking group membership of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !isMember {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var group models.Group
+		err = db.Preload("Members.User").First(&group, groupId).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying group failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		response := GroupResponse{Id: group.ID, Name: group.Name, Members: []GroupMemberResponseItem{}}
+		for _, member := range group.Members {
+			response.Members = append(response.Members, GroupMemberResponseItem{
+				Id:        member.User.ID,
+				Email:     member.User.Email,
+				Name:      member.User.Name,
+				IsManager: member.IsManager,
+			})
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// HandleGroupsDelete
+//
+//	@Summary	Delete group
+//	@Tags		groups
+//	@Id			deleteGroup
+//	@Param		id	path	int	true	"Group id"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404
+//	@Failure	500
+//	@Router		/groups/{id} [delete]
+func HandleGroupsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		groupId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		_, isManager, err := groupservice.CheckUserIsGroupMember(db, user.ID, uint(groupId))
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking group membership of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !isManager {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		err = groupservice.DeleteGroup(db, uint(groupId), user)
+		if err != nil {
+			switch {
+			case errors.Is(err, groupservice.GroupNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.LastVaultManagerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "Group members are the last managers of a vault."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting group failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleGroupsAddMember
+//
+//	@Summary		Add user to group
+//	@Description	New member gets access to the vaults of the group once a manager of each vault shares the vault key
+//	@Description	with them.
+//	@Tags			groups
+//	@Id				addGroupMember
+//	@Param			id		path	int													true	"Group id"
+//	@Param			request	body	controllers.HandleGroupsAddMember.AddMemberRequest	true	"New member data"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/groups/{id}/members [post]
+func HandleGroupsAddMember(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type AddMemberRequest struct {
+		Email     string `json:"email" binding:"required"`
+		IsManager bool   `json:"is_manager"`
+	}
+
+	return func(c *gin.Context) {
+		groupId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		_, isManager, err := groupservice.CheckUserIsGroupMember(db, user.ID, uint(groupId))
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking group membership of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !isManager {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData AddMemberRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		_, err = groupservice.AddGroupMember(db, uint(groupId), requestData.Email, requestData.IsManager)
+		if err != nil {
+			switch {
+			case errors.Is(err, groupservice.GroupNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, groupservice.UserNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User with given email not found."})
+			case errors.Is(err, groupservice.UserAlreadyGroupMemberErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User is already a member of the group."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Adding user to group failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleGroupsRemoveMember
+//
+//	@Summary		Remove user from group
+//	@Description	Group managers can remove any member, other members can only remove themselves.
+//	@Tags			groups
+//	@Id				removeGroupMember
+//	@Param			id		path	int	true	"Group id"
+//	@Param			userId	path	int	true	"User id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Failure		500
+//	@Router			/groups/{id}/members/{userId} [delete]
+func HandleGroupsRemoveMember(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		groupId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		memberId, err := strconv.Atoi(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		_, isManager, err := groupservice.CheckUserIsGroupMember(db, user.ID, uint(groupId))
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking group membership of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !isManager && user.ID != uint(memberId) {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		err = groupservice.RemoveGroupMember(db, uint(groupId), uint(memberId))
+		if err != nil {
+			switch {
+			case errors.Is(err, groupservice.GroupNotFoundErr{}), errors.Is(err, groupservice.UserNotGroupMemberErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, groupservice.LastGroupManagerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Group must have at least one manager."})
+			case errors.Is(err, vaultservice.LastVaultManagerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "User is the last manager of a vault through the group."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Removing user from group failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// sendMail renders the mail template and sends the mail. mail is a mailer.Queue which sends it in the background, so
+// a slow mail server doesn't delay the response. Failures are only logged since none of the requests depend on the
+// mail being delivered.
+func sendMail(c *gin.Context, mail mailer.Mailer, logger *logging.Logger, to, templateName string, data any) {
+	msg, err := mailer.NewMessage(to, templateName, data)
+	if err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Str("template", templateName).Msg("Rendering mail failed.")
+		return
+	}
+	if err := mail.Send(msg); err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Str("template", templateName).Msg("Sending mail failed.")
+	}
+}
+
+// sendPasswordChangedMail notifies the user that their password was changed. reason completes the sentence "The
+// password of your account was changed ...".
+func sendPasswordChangedMail(c *gin.Context, mail mailer.Mailer, logger *logging.Logger, user models.User, reason string) {
+	sendMail(c, mail, logger, user.Email, "password_changed", map[string]any{
+		"Name":   user.Name,
+		"Reason": reason,
+	})
+}
+
+// sendAddedToVaultMail notifies the user that they were given access to the vault by inviter.
+func sendAddedToVaultMail(c *gin.Context, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB, vaultId uint, inviter, user models.User) {
+	var vault models.Vault
+	if err := db.Select("name").First(&vault, vaultId).Error; err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault failed.")
+		return
+	}
+	sendMail(c, mail, logger, user.Email, "added_to_vault", map[string]any{
+		"Name":         user.Name,
+		"InviterEmail": inviter.Email,
+		"VaultName":    vault.Name,
+	})
+}
+
+// sendNewLoginMail notifies the user about the login if none of their sessions were created from the same IP address
+// and user agent. It must be called before the session of the login is created.
+func sendNewLoginMail(c *gin.Context, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB, user models.User, now time.Time) {
+	isNewDevice, err := authservice.IsNewLoginDevice(db, user.ID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking login device failed.")
+		return
+	}
+	if !isNewDevice {
+		return
+	}
+	sendMail(c, mail, logger, user.Email, "new_login", map[string]any{
+		"Name":      user.Name,
+		"Time":      now,
+		"IP":        c.ClientIP(),
+		"UserAgent": c.Request.UserAgent(),
+	})
+}
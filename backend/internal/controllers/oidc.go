@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStateCookieName   = "oidc_state"
+	oidcStateCookiePath   = "/api/v1/auth/oidc"
+	oidcStateCookieMaxAge = 10 * 60
+)
+
+// HandleAuthOIDCLogin
+//
+//	@Summary		Start login with the OpenID Connect identity provider
+//	@Description	Redirects the browser to the identity provider which redirects back to /auth/oidc/callback.
+//	@Tags			auth
+//	@Id				authOIDCLogin
+//	@Success		302
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/auth/oidc/login [get]
+func HandleAuthOIDCLogin(oidcProvider *authservice.OIDCProvider, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if oidcProvider == nil {
+			c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "OpenID Connect login is not configured."})
+			return
+		}
+
+		state, authURL, err := authservice.BeginOIDCLogin(db, oidcProvider)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Beginning OIDC login failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(oidcStateCookieName, state, oidcStateCookieMaxAge, oidcStateCookiePath, "localhost", false, true)
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// HandleAuthOIDCCallback
+//
+//	@Summary		Complete login with the OpenID Connect identity provider
+//	@Description	Called by the identity provider. Starts a session and redirects the browser to the frontend, with
+//	@Description	oidc_error query parameter if the login fails. Users created on their first login must set up their
+//	@Description	keys before using vaults. If the identity matches a user it can't be linked to automatically,
+//	@Description	oidc_error is link_required and oidc_link_token is given, the user should log in and confirm the
+//	@Description	link with /users/me/oidc/link. If the user has a second factor, no session is created and
+//	@Description	oidc_error is two_factor_required with two_factor_token, two_factor_methods (comma separated) and
+//	@Description	webauthn_options (base64url encoded JSON, if webauthn is one of the methods) given like the 202
+//	@Description	response of /auth/login. The login is completed with /auth/login/totp or /auth/login/webauthn.
+//	@Tags			auth
+//	@Id				authOIDCCallback
+//	@Param			state	query	string	true	"State given to the identity provider"
+//	@Param			code	query	string	false	"Authorization code"
+//	@Success		302
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Router			/auth/oidc/callback [get]
+func HandleAuthOIDCCallback(apiConfig *config.RestapiConfig, webAuthn *webauthn.WebAuthn, oidcProvider *authservice.OIDCProvider, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if oidcProvider == nil {
+			c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "OpenID Connect login is not configured."})
+			return
+		}
+
+		state := c.Query("state")
+		stateCookie, err := c.Cookie(oidcStateCookieName)
+		c.SetCookie(oidcStateCookieName, "", -1, oidcStateCookiePath, "localhost", false, true)
+		// state must come from the browser which started the login
+		if err != nil || state == "" || state != stateCookie {
+			redirectOIDCCallback(c, apiConfig, "invalid_state")
+			return
+		}
+		if c.Query("error") != "" || c.Query("code") == "" {
+			redirectOIDCCallback(c, apiConfig, "login_failed")
+			return
+		}
+
+		// users are created only if the registration policy would let them register, invite codes can't be given
+		// through the identity provider so invite mode doesn't allow it either
+		canAutoProvision := func(email string) bool {
+			return apiConfig.OIDCAutoProvision && apiConfig.RegistrationMode == config.RegistrationModeOpen &&
+				isRegistrationEmailDomainAllowed(apiConfig, email)
+		}
+		user, err := authservice.CompleteOIDCLogin(c.Request.Context(), db, oidcProvider, state, c.Query("code"),
+			canAutoProvision)
+		if err != nil {
+			var linkRequiredErr authservice.OIDCLinkRequiredErr
+			switch {
+			case errors.As(err, &linkRequiredErr):
+				logger.RequestEvent(zerolog.InfoLevel, c).Msg("OIDC identity requires confirmation to be linked.")
+				redirectOIDCCallbackWithQuery(c, apiConfig, url.Values{
+					"oidc_error":      {"link_required"},
+					"oidc_link_token": {linkRequiredErr.Token},
+				})
+			case errors.Is(err, authservice.OIDCLoginStateNotFoundErr{}):
+				redirectOIDCCallback(c, apiConfig, "invalid_state")
+			case errors.Is(err, authservice.InvalidOIDCResponseErr{}):
+				redirectOIDCCallback(c, apiConfig, "login_failed")
+			case errors.Is(err, authservice.OIDCUserNotFoundErr{}):
+				redirectOIDCCallback(c, apiConfig, "user_not_found")
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Completing OIDC login failed.")
+				redirectOIDCCallback(c, apiConfig, "server_error")
+			}
+			return
+		}
+
+		if !user.IsActive {
+			redirectOIDCCallback(c, apiConfig, "user_inactive")
+			return
+		}
+
+		// the identity replaces only the password, the second factor is required like in the password login
+		twoFactorMethods, err := authservice.GetTwoFactorMethods(db, user)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting two-factor methods failed.")
+			redirectOIDCCallback(c, apiConfig, "server_error")
+			return
+		}
+		if len(twoFactorMethods) > 0 {
+			twoFactorToken, webAuthnOptions, err := authservice.CreateLoginChallenge(db, webAuthn, user, twoFactorMethods)
+			if err != nil {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating login challenge failed.")
+				redirectOIDCCallback(c, apiConfig, "server_error")
+				return
+			}
+			query := url.Values{
+				"oidc_error":         {"two_factor_required"},
+				"two_factor_token":   {twoFactorToken},
+				"two_factor_methods": {strings.Join(twoFactorMethods, ",")},
+			}
+			if webAuthnOptions != nil {
+				options, err := json.Marshal(webAuthnOptions)
+				if err != nil {
+					logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Encoding WebAuthn options failed.")
+					redirectOIDCCallback(c, apiConfig, "server_error")
+					return
+				}
+				query.Set("webauthn_options", base64.RawURLEncoding.EncodeToString(options))
+			}
+			redirectOIDCCallbackWithQuery(c, apiConfig, query)
+			return
+		}
+
+		if err := startUserSession(c, apiConfig, mail, logger, db, user); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating user session failed.")
+			redirectOIDCCallback(c, apiConfig, "server_error")
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Logged in with OIDC.")
+
+		redirectOIDCCallback(c, apiConfig, "")
+	}
+}
+
+// redirectOIDCCallback redirects the browser to the frontend after OpenID Connect login. oidcError is added to the
+// query if it isn't empty.
+func redirectOIDCCallback(c *gin.Context, apiConfig *config.RestapiConfig, oidcError string) {
+	query := url.Values{}
+	if oidcError != "" {
+		query.Set("oidc_error", oidcError)
+	}
+	redirectOIDCCallbackWithQuery(c, apiConfig, query)
+}
+
+// redirectOIDCCallbackWithQuery redirects the browser to the frontend after OpenID Connect login with given query
+// parameters added.
+func redirectOIDCCallbackWithQuery(c *gin.Context, apiConfig *config.RestapiConfig, extraQuery url.Values) {
+	frontendURL, err := url.Parse(apiConfig.OIDCFrontendURL)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if len(extraQuery) > 0 {
+		query := frontendURL.Query()
+		for key, values := range extraQuery {
+			query[key] = values
+		}
+		frontendURL.RawQuery = query.Encode()
+	}
+	c.Redirect(http.StatusFound, frontendURL.String())
+}
+
+// HandleUsersMeOIDCLinkPasskeyChallenge
+//
+//	@Summary		Start confirming an OpenID Connect link with a passkey
+//	@Description	options are passed to navigator.credentials.get(), the result is sent to /users/me/oidc/link
+//	@Description	together with the token.
+//	@Tags			users
+//	@Id				usersMeOIDCLinkPasskeyChallenge
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeOIDCLinkPasskeyChallenge.PasskeyChallengeResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		500
+//	@Router			/users/me/oidc/link/passkey-challenge [post]
+func HandleUsersMeOIDCLinkPasskeyChallenge(webAuthn *webauthn.WebAuthn, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type PasskeyChallengeResponse struct {
+		Token   string                        `json:"token" binding:"required"`
+		Options *protocol.CredentialAssertion `json:"options" binding:"required" swaggertype:"object"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		twoFactorMethods, err := authservice.GetTwoFactorMethods(db, user)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting two factor methods failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !slices.Contains(twoFactorMethods, authservice.TwoFactorMethodWebAuthn) {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User doesn't have a passkey."})
+			return
+		}
+
+		token, options, err := authservice.CreateLoginChallenge(db, webAuthn, user,
+			[]string{authservice.TwoFactorMethodWebAuthn})
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating login challenge failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, PasskeyChallengeResponse{Token: token, Options: options})
+	}
+}
+
+// HandleUsersMeOIDCLink
+//
+//	@Summary		Link an OpenID Connect identity to the user
+//	@Description	link_token is given to the frontend when the identity can't be linked automatically on login. The
+//	@Description	password of the user is required. If the user has a second factor, either code must be a TOTP or
+//	@Description	recovery code, or challenge_token and credential must be the result of a passkey challenge, see
+//	@Description	/users/me/oidc/link/passkey-challenge.
+//	@Tags			users
+//	@Id				usersMeOIDCLink
+//	@Param			request	body	controllers.HandleUsersMeOIDCLink.LinkRequest	true	"Link token, password and second factor"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/oidc/link [post]
+func HandleUsersMeOIDCLink(webAuthn *webauthn.WebAuthn, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type LinkRequest struct {
+		LinkToken      string          `json:"link_token" binding:"required"`
+		Password       string          `json:"password" binding:"required"`
+		Code           string          `json:"code"`
+		ChallengeToken string          `json:"challenge_token"`
+		Credential     json.RawMessage `json:"credential" swaggertype:"object"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData LinkRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err := authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		err = authservice.ConfirmOIDCLink(db, webAuthn, user, requestData.LinkToken, requestData.Code,
+			requestData.ChallengeToken, requestData.Credential)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.SecondFactorRequiredErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Second factor is required."})
+			case errors.Is(err, authservice.InvalidTOTPCodeErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong code."})
+			case errors.Is(err, authservice.LoginChallengeNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Passkey challenge expired."})
+			case errors.Is(err, authservice.InvalidWebAuthnResponseErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Passkey verification failed."})
+			case errors.Is(err, authservice.OIDCLinkRequestNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Link request doesn't exist or has expired."})
+			case errors.Is(err, authservice.OIDCAlreadyLinkedErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{Error: "Identity or user is already linked."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Linking OIDC identity failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("OIDC identity linked.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
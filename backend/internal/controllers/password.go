@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/common/ratelimit"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// checkCurrentPassword compares the given password with the password of the logged-in user and responds with an
+// error and returns false if it is wrong. Checks are rate limited per user, so a stolen session can't be used to
+// brute-force the password.
+func checkCurrentPassword(c *gin.Context, loginLimiter *ratelimit.Limiter, logger *logging.Logger, user models.User, password string) bool {
+	userKey := "password-check:user:" + strconv.Itoa(int(user.ID))
+	if !reserveRateLimit(c, loginLimiter, logger, time.Now(), "Too many wrong passwords, try again later.", userKey) {
+		return false
+	}
+
+	ok, err := authservice.ComparePassword(user.Password, password)
+	if err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+		c.Status(http.StatusInternalServerError)
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+		return false
+	}
+
+	if err := loginLimiter.Reset(userKey); err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Resetting password check rate limit failed.")
+	}
+	return true
+}
+
+// checkPasswordPolicy responds with validation errors of given field and returns false if the password doesn't meet
+// the password policy.
+func checkPasswordPolicy(c *gin.Context, passwordPolicy *authservice.PasswordPolicy, logger *logging.Logger, field, password string) bool {
+	violations, err := passwordPolicy.CheckPassword(password)
+	if err != nil {
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking password policy failed.")
+		c.Status(http.StatusInternalServerError)
+		return false
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	bodybinder.RespondFieldErrors(c, common.Map(violations, func(v authservice.PasswordPolicyViolation) bodybinder.FieldError {
+		return bodybinder.FieldError{Field: field, Tag: v.Rule, Param: v.Param}
+	}))
+	return false
+}
+
+// ownedVaultKey is a vault key of the user, it is decrypted with the private key of the user and the public key of
+// its inviter.
+type ownedVaultKey struct {
+	Id                   uint   `json:"id" binding:"required"`
+	VaultId              uint   `json:"vault_id" binding:"required"`
+	EncryptionIV         string `json:"encryption_iv" binding:"required"`
+	EncryptedVaultKey    string `json:"encrypted_vault_key" binding:"required"`
+	InviterUserPublicKey string `json:"inviter_user_public_key" binding:"required"`
+}
+
+func newOwnedVaultKey(vaultKey models.VaultKey) ownedVaultKey {
+	return ownedVaultKey{
+		Id:                   vaultKey.ID,
+		VaultId:              vaultKey.VaultID,
+		EncryptionIV:         vaultKey.EncryptionIV,
+		EncryptedVaultKey:    vaultKey.EncryptedVaultKey,
+		InviterUserPublicKey: vaultKey.InviterUser.PublicKey,
+	}
+}
+
+// issuedVaultKey is a vault key the user encrypted for another user.
+type issuedVaultKey struct {
+	Id                    uint   `json:"id" binding:"required"`
+	VaultId               uint   `json:"vault_id" binding:"required"`
+	KeyOwnerUserId        uint   `json:"key_owner_user_id" binding:"required"`
+	KeyOwnerUserPublicKey string `json:"key_owner_user_public_key" binding:"required"`
+}
+
+func newIssuedVaultKey(vaultKey models.VaultKey) issuedVaultKey {
+	return issuedVaultKey{
+		Id:                    vaultKey.ID,
+		VaultId:               vaultKey.VaultID,
+		KeyOwnerUserId:        vaultKey.KeyOwnerUserID,
+		KeyOwnerUserPublicKey: vaultKey.KeyOwnerUser.PublicKey,
+	}
+}
+
+// rewrappedVaultKey is an owned or issued vault key encrypted again with the new key pair of the user.
+type rewrappedVaultKey struct {
+	VaultKeyId        uint   `json:"vault_key_id" binding:"required"`
+	EncryptionIV      string `json:"encryption_iv" binding:"required"`
+	EncryptedVaultKey string `json:"encrypted_vault_key" binding:"required"`
+}
+
+func toRewrappedVaultKeys(vaultKeys []rewrappedVaultKey) []authservice.RewrappedVaultKey {
+	return common.Map(vaultKeys, func(k rewrappedVaultKey) authservice.RewrappedVaultKey {
+		return authservice.RewrappedVaultKey{
+			VaultKeyId:        k.VaultKeyId,
+			EncryptionIV:      k.EncryptionIV,
+			EncryptedVaultKey: k.EncryptedVaultKey,
+		}
+	})
+}
+
+// respondChangePasswordError responds to the error returned from changing the password of a user with
+// authservice.ChangePassword.
+func respondChangePasswordError(c *gin.Context, logger *logging.Logger, err error) {
+	var mismatchErr authservice.VaultKeyMismatchErr
+	var envelopeErr authservice.UnsupportedPrivateKeyEnvelopeErr
+	switch {
+	case errors.As(err, &mismatchErr):
+		c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+			Error: fmt.Sprintf("Vault key %d is missing or not expected, list vault keys again.", mismatchErr.VaultKeyId)})
+	case errors.As(err, &envelopeErr):
+		c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Private key envelope version is not supported."})
+	default:
+		logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Changing password failed.")
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+// HandleUsersMeVaultKeysList
+//
+//	@Summary		List vault keys encrypted with the key pair of the user
+//	@Description	owned vault keys are decrypted with the inviter's public key and issued vault keys are the ones the
+//	@Description	user encrypted for other users. All of them must be encrypted again with the new key pair on
+//	@Description	password change.
+//	@Tags			users
+//	@Id				usersMeVaultKeysList
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeVaultKeysList.VaultKeysResponse
+//	@Failure		401
+//	@Failure		500
+//	@Router			/users/me/vault-keys [get]
+func HandleUsersMeVaultKeysList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type VaultKeysResponse struct {
+		Owned  []ownedVaultKey  `json:"owned" binding:"required"`
+		Issued []issuedVaultKey `json:"issued" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		owned, issued, err := authservice.GetUserVaultKeysToRewrap(db, user.ID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault keys of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, VaultKeysResponse{
+			Owned:  common.Map(owned, newOwnedVaultKey),
+			Issued: common.Map(issued, newIssuedVaultKey),
+		})
+	}
+}
+
+// HandleUsersMeChangePassword
+//
+//	@Summary		Change password
+//	@Description	Key pair of the user is derived from the password, so every vault key listed in
+//	@Description	/users/me/vault-keys must be encrypted again with the new key pair. Owned vault keys are encrypted
+//	@Description	with the user's own new public key. private_key is the new private key encrypted with the new
+//	@Description	password, stored private key is removed if it isn't given. Recovery key and emergency accesses of
+//	@Description	the user are removed since they are encrypted with the old key pair. Every other session of the
+//	@Description	user is logged out. The new password must meet the password policy. Wrong old passwords are rate
+//	@Description	limited per user.
+//	@Tags			users
+//	@Id				usersMeChangePassword
+//	@Param			request	body	controllers.HandleUsersMeChangePassword.ChangePasswordRequest	true	"Old and new password with the re-encrypted vault keys"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/users/me/change-password [post]
+func HandleUsersMeChangePassword(loginLimiter *ratelimit.Limiter, passwordPolicy *authservice.PasswordPolicy, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type ChangePasswordRequest struct {
+		OldPassword       string              `json:"old_password" binding:"required"`
+		NewPassword       string              `json:"new_password" binding:"required,max=72"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PublicKey         string              `json:"public_key" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+		VaultKeys         []rewrappedVaultKey `json:"vault_keys" binding:"required,dive"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		currentSession, ok := middlewares.ExtractUserSessionFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user session from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData ChangePasswordRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+		if !checkPasswordPolicy(c, passwordPolicy, logger, "new_password", requestData.NewPassword) {
+			return
+		}
+
+		if !checkCurrentPassword(c, loginLimiter, logger, user, requestData.OldPassword) {
+			return
+		}
+
+		err := authservice.ChangePassword(db, user, currentSession.ID, requestData.NewPassword,
+			requestData.KeyDerivationSalt, requestData.PublicKey, requestData.PrivateKey.toPrivateKeyEnvelope(),
+			toRewrappedVaultKeys(requestData.VaultKeys))
+		if err != nil {
+			respondChangePasswordError(c, logger, err)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Password changed.")
+		sendPasswordChangedMail(c, mail, logger, user, "from your account settings")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMePrivateKeyUpdate
+//
+//	@Summary		Store encrypted private key
+//	@Description	Stores the private key of the user encrypted on the client, so it is returned on login from a new
+//	@Description	device. Can be used by users registered before private keys were stored and to move the private key
+//	@Description	to a newer envelope version.
+//	@Tags			users
+//	@Id				usersMePrivateKeyUpdate
+//	@Param			request	body	controllers.HandleUsersMePrivateKeyUpdate.PrivateKeyUpdateRequest	true	"Password and private key envelope"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/users/me/private-key [put]
+func HandleUsersMePrivateKeyUpdate(loginLimiter *ratelimit.Limiter, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type PrivateKeyUpdateRequest struct {
+		Password   string             `json:"password" binding:"required"`
+		PrivateKey privateKeyEnvelope `json:"private_key" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData PrivateKeyUpdateRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		if !checkCurrentPassword(c, loginLimiter, logger, user, requestData.Password) {
+			return
+		}
+
+		err := authservice.SetPrivateKeyEnvelope(db, user, *requestData.PrivateKey.toPrivateKeyEnvelope())
+		if err != nil {
+			if errors.As(err, &authservice.UnsupportedPrivateKeyEnvelopeErr{}) {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Private key envelope version is not supported."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Storing private key failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Private key stored.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeKeysGet
+//
+//	@Summary		Get keys of the user
+//	@Description	Returns the same key data as login, for users who logged in without a password like with OpenID
+//	@Description	Connect. has_keys is false if the user hasn't set up their keys yet.
+//	@Tags			users
+//	@Id				usersMeKeysGet
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeKeysGet.KeysResponse
+//	@Failure		401
+//	@Failure		500
+//	@Router			/users/me/keys [get]
+func HandleUsersMeKeysGet(logger *logging.Logger) func(c *gin.Context) {
+	type KeysResponse struct {
+		HasKeys           bool                `json:"has_keys" binding:"required"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, KeysResponse{
+			HasKeys:           authservice.HasKeys(user),
+			KeyDerivationSalt: user.KeyDerivationSalt,
+			PrivateKey:        newPrivateKeyEnvelope(user),
+		})
+	}
+}
+
+// HandleUsersMeKeysSetUp
+//
+//	@Summary		Set up keys of the user
+//	@Description	Users created on their first OpenID Connect login don't have a password and keys. The password is
+//	@Description	used to derive the key pair on the client like on registration and to confirm sensitive operations.
+//	@Tags			users
+//	@Id				usersMeKeysSetUp
+//	@Param			request	body	controllers.HandleUsersMeKeysSetUp.KeysSetUpRequest	true	"Password and keys"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/keys [post]
+func HandleUsersMeKeysSetUp(passwordPolicy *authservice.PasswordPolicy, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type KeysSetUpRequest struct {
+		Password          string              `json:"password" binding:"required,max=72"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PublicKey         string              `json:"public_key" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData KeysSetUpRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+		if !checkPasswordPolicy(c, passwordPolicy, logger, "password", requestData.Password) {
+			return
+		}
+
+		err := authservice.SetUpUserKeys(db, user, requestData.Password, requestData.KeyDerivationSalt,
+			requestData.PublicKey, requestData.PrivateKey.toPrivateKeyEnvelope())
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.KeysAlreadySetUpErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Keys are already set up."})
+			case errors.As(err, &authservice.UnsupportedPrivateKeyEnvelopeErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Private key envelope version is not supported."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Setting up keys failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Keys set up.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
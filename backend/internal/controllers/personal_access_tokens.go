@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleUsersMeTokensList
+//
+//	@Summary	List personal access tokens of the user
+//	@Tags		users
+//	@Id			usersMeTokensList
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleUsersMeTokensList.TokenResponse
+//	@Failure	401
+//	@Failure	500
+//	@Router		/users/me/tokens [get]
+func HandleUsersMeTokensList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type TokenResponse struct {
+		Id          uint       `json:"id" binding:"required"`
+		Name        string     `json:"name" binding:"required"`
+		TokenPrefix string     `json:"token_prefix" binding:"required"`
+		VaultIds    []uint     `json:"vault_ids" binding:"required"`
+		CreatedAt   time.Time  `json:"created_at" binding:"required"`
+		ExpiresAt   time.Time  `json:"expires_at" binding:"required"`
+		LastUsedAt  *time.Time `json:"last_used_at"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		tokens, err := authservice.ListPersonalAccessTokens(db, user.ID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Listing personal access tokens failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, common.Map(tokens, func(token models.PersonalAccessToken) TokenResponse {
+			return TokenResponse{
+				Id:          token.ID,
+				Name:        token.Name,
+				TokenPrefix: token.TokenPrefix,
+				VaultIds:    token.VaultIDs,
+				CreatedAt:   token.CreatedAt,
+				ExpiresAt:   token.ExpiresAt,
+				LastUsedAt:  token.LastUsedAt,
+			}
+		}))
+	}
+}
+
+// HandleUsersMeTokensCreate
+//
+//	@Summary		Create a personal access token
+//	@Description	The token is sent in Authorization: Bearer header. It can only read the given vaults and can't be
+//	@Description	used to manage the account. The token is only returned in this response.
+//	@Tags			users
+//	@Id				usersMeTokensCreate
+//	@Param			request	body	controllers.HandleUsersMeTokensCreate.CreateTokenRequest	true	"Token data"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleUsersMeTokensCreate.CreateTokenResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/tokens [post]
+func HandleUsersMeTokensCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type CreateTokenRequest struct {
+		Name          string `json:"name" binding:"required,max=100"`
+		VaultIds      []uint `json:"vault_ids" binding:"required,min=1"`
+		ExpiresInDays int    `json:"expires_in_days" binding:"required,min=1,max=365"`
+	}
+
+	type CreateTokenResponse struct {
+		Id        uint      `json:"id" binding:"required"`
+		Token     string    `json:"token" binding:"required"`
+		ExpiresAt time.Time `json:"expires_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData CreateTokenRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		for _, vaultId := range requestData.VaultIds {
+			canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), int(vaultId), models.VaultPermissionRead)
+			if err != nil {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			if !canRead {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("You don't have read permission on vault %d.", vaultId)})
+				return
+			}
+		}
+
+		token, rawToken, err := authservice.CreatePersonalAccessToken(db, user, requestData.Name, requestData.VaultIds,
+			time.Now().AddDate(0, 0, requestData.ExpiresInDays))
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating personal access token failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Uint("token_id", token.ID).
+			Msg("Personal access token created.")
+
+		c.JSON(http.StatusCreated, CreateTokenResponse{Id: token.ID, Token: rawToken, ExpiresAt: token.ExpiresAt})
+	}
+}
+
+// HandleUsersMeTokensRevoke
+//
+//	@Summary	Revoke a personal access token
+//	@Tags		users
+//	@Id			usersMeTokensRevoke
+//	@Param		id	path	int	true	"Token id"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/users/me/tokens/{id} [delete]
+func HandleUsersMeTokensRevoke(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		tokenId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = authservice.RevokePersonalAccessToken(db, user.ID, uint(tokenId))
+		if err != nil {
+			if errors.Is(err, authservice.PersonalAccessTokenNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Token not found."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Revoking personal access token failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Int("token_id", tokenId).
+			Msg("Personal access token revoked.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
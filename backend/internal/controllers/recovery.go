@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/common/ratelimit"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleUsersMeRecoveryKeyGet
+//
+//	@Summary	Get recovery key status of the user
+//	@Tags		users
+//	@Id			usersMeRecoveryKeyGet
+//	@Produce	json
+//	@Success	200	{object}	controllers.HandleUsersMeRecoveryKeyGet.RecoveryKeyResponse
+//	@Failure	401
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/users/me/recovery-key [get]
+func HandleUsersMeRecoveryKeyGet(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RecoveryKeyResponse struct {
+		CreatedAt time.Time `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		recoveryKey, err := authservice.GetRecoveryKey(db, user.ID)
+		if err != nil {
+			if errors.Is(err, authservice.RecoveryKeyNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Recovery key not found."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting recovery key failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, RecoveryKeyResponse{CreatedAt: recoveryKey.CreatedAt})
+	}
+}
+
+// HandleUsersMeRecoveryKeyUpdate
+//
+//	@Summary		Set up a new recovery key
+//	@Description	The recovery key is generated on the client and never sent to the server. private_key is the
+//	@Description	private key of the user encrypted with the recovery key and recovery_key_verifier is a value derived
+//	@Description	from the recovery key which is required to recover the account. Previous recovery key of the user
+//	@Description	stops working.
+//	@Tags			users
+//	@Id				usersMeRecoveryKeyUpdate
+//	@Param			request	body	controllers.HandleUsersMeRecoveryKeyUpdate.RecoveryKeyUpdateRequest	true	"Password, verifier and the encrypted private key"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/recovery-key [put]
+func HandleUsersMeRecoveryKeyUpdate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RecoveryKeyUpdateRequest struct {
+		Password            string             `json:"password" binding:"required"`
+		RecoveryKeyVerifier string             `json:"recovery_key_verifier" binding:"required,max=72"`
+		PrivateKey          privateKeyEnvelope `json:"private_key" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData RecoveryKeyUpdateRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err := authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		_, err = authservice.SetRecoveryKey(db, user, requestData.RecoveryKeyVerifier,
+			*requestData.PrivateKey.toPrivateKeyEnvelope())
+		if err != nil {
+			if errors.As(err, &authservice.UnsupportedPrivateKeyEnvelopeErr{}) {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Private key envelope version is not supported."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Setting recovery key failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Recovery key set.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeRecoveryKeyDelete
+//
+//	@Summary	Delete recovery key of the user
+//	@Tags		users
+//	@Id			usersMeRecoveryKeyDelete
+//	@Param		request	body	controllers.HandleUsersMeRecoveryKeyDelete.DeleteRequest	true	"Current password of the user"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/users/me/recovery-key [delete]
+func HandleUsersMeRecoveryKeyDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type DeleteRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData DeleteRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err := authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		err = authservice.DeleteRecoveryKey(db, user.ID)
+		if err != nil {
+			if errors.Is(err, authservice.RecoveryKeyNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Recovery key not found."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting recovery key failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Recovery key deleted.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleAuthPasswordReset
+//
+//	@Summary		Request a password reset mail
+//	@Description	The password can't be reset without the recovery key since the vault keys are encrypted with the
+//	@Description	key pair protected by the password. The mail links to account recovery if the user has a recovery
+//	@Description	key. 204 is returned whether a mail is sent or not, so the endpoint can't be used to find out
+//	@Description	registered emails. Requests are delayed per IP and email.
+//	@Tags			auth
+//	@Id				authPasswordReset
+//	@Param			request	body	controllers.HandleAuthPasswordReset.PasswordResetRequest	true	"Email of the user"
+//	@Success		204
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/auth/password-reset [post]
+func HandleAuthPasswordReset(apiConfig *config.RestapiConfig, loginLimiter *ratelimit.Limiter, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type PasswordResetRequest struct {
+		Email string `json:"email" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData PasswordResetRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		timeNow := time.Now()
+		ipKey := "password-reset:ip:" + c.ClientIP()
+		emailKey := "password-reset:email:" + strings.ToLower(requestData.Email)
+		// every request counts, not only the failed ones
+		if !reserveRateLimit(c, loginLimiter, logger, timeNow, "Too many password reset mails requested, try again later.",
+			ipKey, emailKey) {
+			return
+		}
+
+		var user models.User
+		err := db.First(&user, "email = ?", requestData.Email).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.Status(http.StatusNoContent)
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting user by email failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		hasRecoveryKey := true
+		if _, err := authservice.GetRecoveryKey(db, user.ID); err != nil {
+			if !errors.Is(err, authservice.RecoveryKeyNotFoundErr{}) {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting recovery key failed.")
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			hasRecoveryKey = false
+		}
+
+		sendMail(c, mail, logger, user.Email, "password_reset", map[string]any{
+			"Name":           user.Name,
+			"HasRecoveryKey": hasRecoveryKey,
+			"RecoveryURL":    apiConfig.FrontendURL + "/recover-account?email=" + url.QueryEscape(user.Email),
+		})
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleAuthRecoveryBegin
+//
+//	@Summary		Start account recovery with a recovery key
+//	@Description	Returns the private key of the user encrypted with the recovery key and the vault keys which must
+//	@Description	be encrypted again with the new key pair to complete the recovery. Failed attempts are delayed per
+//	@Description	IP and email like logins.
+//	@Tags			auth
+//	@Id				authRecoveryBegin
+//	@Param			request	body	controllers.HandleAuthRecoveryBegin.RecoveryBeginRequest	true	"Email and recovery key verifier"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleAuthRecoveryBegin.RecoveryBeginResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/auth/recovery/begin [post]
+func HandleAuthRecoveryBegin(loginLimiter *ratelimit.Limiter, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RecoveryBeginRequest struct {
+		Email               string `json:"email" binding:"required"`
+		RecoveryKeyVerifier string `json:"recovery_key_verifier" binding:"required,max=72"`
+	}
+
+	type RecoveryBeginResponse struct {
+		PrivateKey privateKeyEnvelope `json:"private_key" binding:"required"`
+		Owned      []ownedVaultKey    `json:"owned" binding:"required"`
+		Issued     []issuedVaultKey   `json:"issued" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData RecoveryBeginRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		timeNow := time.Now()
+		ipKey := "recovery:ip:" + c.ClientIP()
+		emailKey := "recovery:email:" + strings.ToLower(requestData.Email)
+		if !reserveRateLimit(c, loginLimiter, logger, timeNow, "Too many failed recovery attempts, try again later.",
+			ipKey, emailKey) {
+			return
+		}
+
+		user, recoveryKey, err := authservice.VerifyRecoveryKey(db, requestData.Email, requestData.RecoveryKeyVerifier)
+		if err != nil {
+			if errors.Is(err, authservice.InvalidRecoveryKeyErr{}) {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong email or recovery key."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Verifying recovery key failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if err := loginLimiter.Release(timeNow, ipKey, emailKey); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Releasing recovery rate limit failed.")
+		}
+
+		owned, issued, err := authservice.GetUserVaultKeysToRewrap(db, user.ID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault keys of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, RecoveryBeginResponse{
+			PrivateKey: privateKeyEnvelope{
+				Version:             recoveryKey.EnvelopeVersion,
+				EncryptionIV:        recoveryKey.EncryptionIV,
+				EncryptedPrivateKey: recoveryKey.EncryptedPrivateKey,
+			},
+			Owned:  common.Map(owned, newOwnedVaultKey),
+			Issued: common.Map(issued, newIssuedVaultKey),
+		})
+	}
+}
+
+// HandleAuthRecoveryComplete
+//
+//	@Summary		Complete account recovery by setting a new password
+//	@Description	Works like password change, every vault key returned from /auth/recovery/begin must be encrypted
+//	@Description	again with the new key pair. The recovery key, emergency accesses and every session of the user
+//	@Description	are removed.
+//	@Tags			auth
+//	@Id				authRecoveryComplete
+//	@Param			request	body	controllers.HandleAuthRecoveryComplete.RecoveryCompleteRequest	true	"Recovery key verifier and new password with the re-encrypted vault keys"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/auth/recovery/complete [post]
+func HandleAuthRecoveryComplete(loginLimiter *ratelimit.Limiter, passwordPolicy *authservice.PasswordPolicy, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RecoveryCompleteRequest struct {
+		Email               string              `json:"email" binding:"required"`
+		RecoveryKeyVerifier string              `json:"recovery_key_verifier" binding:"required,max=72"`
+		NewPassword         string              `json:"new_password" binding:"required,max=72"`
+		KeyDerivationSalt   string              `json:"key_derivation_salt" binding:"required"`
+		PublicKey           string              `json:"public_key" binding:"required"`
+		PrivateKey          *privateKeyEnvelope `json:"private_key"`
+		VaultKeys           []rewrappedVaultKey `json:"vault_keys" binding:"required,dive"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData RecoveryCompleteRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+		if !checkPasswordPolicy(c, passwordPolicy, logger, "new_password", requestData.NewPassword) {
+			return
+		}
+
+		timeNow := time.Now()
+		ipKey := "recovery:ip:" + c.ClientIP()
+		emailKey := "recovery:email:" + strings.ToLower(requestData.Email)
+		if !reserveRateLimit(c, loginLimiter, logger, timeNow, "Too many failed recovery attempts, try again later.",
+			ipKey, emailKey) {
+			return
+		}
+
+		user, err := authservice.RecoverAccount(db, requestData.Email, requestData.RecoveryKeyVerifier,
+			requestData.NewPassword, requestData.KeyDerivationSalt, requestData.PublicKey,
+			requestData.PrivateKey.toPrivateKeyEnvelope(), toRewrappedVaultKeys(requestData.VaultKeys))
+		if err != nil {
+			if errors.Is(err, authservice.InvalidRecoveryKeyErr{}) {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong email or recovery key."})
+				return
+			}
+			respondChangePasswordError(c, logger, err)
+			return
+		}
+
+		if err := loginLimiter.Release(timeNow, ipKey); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Releasing recovery rate limit failed.")
+		}
+		if err := loginLimiter.Reset(emailKey); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Resetting recovery rate limit failed.")
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("Account recovered with recovery key.")
+		sendPasswordChangedMail(c, mail, logger, user, "with the recovery key")
+
+		c.Status(http.StatusNoContent)
+	}
+}
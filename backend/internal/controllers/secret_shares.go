@@ -0,0 +1,358 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleVaultItemSharesCreate
+//
+//	@Summary		Create a share link for a vault item
+//	@Description	encrypted_data should be encrypted with a key which is not sent to the server, e.g. a key kept in
+//	@Description	the fragment of the share link. Returned token can't be retrieved again. max_views is at most 10
+//	@Description	and expires_at must be within the maximum share lifetime configured on the server.
+//	@Tags			vault items
+//	@Id				createVaultItemShare
+//	@Param			request	body	controllers.HandleVaultItemSharesCreate.ShareCreateRequest	true	"Share data"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleVaultItemSharesCreate.ShareCreateResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/items/{itemId}/shares [post]
+//	@Param			id		path	int	true	"Vault id"
+//	@Param			itemId	path	int	true	"Vault Item id"
+func HandleVaultItemSharesCreate(apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type ShareCreateRequest struct {
+		EncryptedData string    `json:"encrypted_data" binding:"required"`
+		MaxViews      int       `json:"max_views" binding:"required,min=1,max=10"`
+		ExpiresAt     time.Time `json:"expires_at" binding:"required"`
+	}
+
+	type ShareCreateResponse struct {
+		Id        uint      `json:"id" binding:"required"`
+		Token     string    `json:"token" binding:"required"`
+		ExpiresAt time.Time `json:"expires_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionRead)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRead {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData ShareCreateRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		var vaultItem models.VaultItem
+		err = db.First(&vaultItem, "id = ? AND vault_id = ?", vaultItemId, vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		share, token, err := vaultservice.CreateSecretShare(db, vaultItem, user, requestData.EncryptedData,
+			requestData.MaxViews, requestData.ExpiresAt, time.Second*time.Duration(apiConfig.SecretShareMaxLifetimeSeconds))
+		if err != nil {
+			if errors.Is(err, vaultservice.InvalidExpiryErr{}) {
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: fmt.Sprintf(
+					"Expiry time must be in the future and at most %d seconds later.", apiConfig.SecretShareMaxLifetimeSeconds)})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating secret share failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, ShareCreateResponse{Id: share.ID, Token: token, ExpiresAt: share.ExpiresAt})
+	}
+}
+
+// HandleVaultItemSharesList
+//
+//	@Summary	List active share links of a vault item
+//	@Tags		vault items
+//	@Id			listVaultItemShares
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleVaultItemSharesList.ShareResponseItem
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/vaults/{id}/items/{itemId}/shares [get]
+//	@Param		id		path	int	true	"Vault id"
+//	@Param		itemId	path	int	true	"Vault Item id"
+func HandleVaultItemSharesList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type ShareCreatorData struct {
+		Id    uint   `json:"id" binding:"required"`
+		Email string `json:"email" binding:"required"`
+	}
+
+	type ShareResponseItem struct {
+		Id        uint             `json:"id" binding:"required"`
+		MaxViews  int              `json:"max_views" binding:"required"`
+		ViewCount int              `json:"view_count" binding:"required"`
+		ExpiresAt time.Time        `json:"expires_at" binding:"required"`
+		CreatedAt time.Time        `json:"created_at" binding:"required"`
+		Creator   ShareCreatorData `json:"creator" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionRead)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRead {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var vaultItem models.VaultItem
+		err = db.First(&vaultItem, "id = ? AND vault_id = ?", vaultItemId, vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var shares []models.SecretShare
+		err = db.Preload("CreatorUser").
+			Where("vault_id = ? AND vault_item_id = ? AND expires_at > ?", vaultId, vaultItemId, time.Now()).
+			Order("created_at DESC").Find(&shares).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying secret shares failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []ShareResponseItem{}
+		for _, share := range shares {
+			results = append(results, ShareResponseItem{
+				Id:        share.ID,
+				MaxViews:  share.MaxViews,
+				ViewCount: share.ViewCount,
+				ExpiresAt: share.ExpiresAt,
+				CreatedAt: share.CreatedAt,
+				Creator: ShareCreatorData{
+					Id:    share.CreatorUser.ID,
+					Email: share.CreatorUser.Email,
+				},
+			})
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// HandleVaultItemSharesRevoke
+//
+//	@Summary		Revoke a share link of a vault item
+//	@Description	Share creator or users with manage_items permission can revoke a share.
+//	@Tags			vault items
+//	@Id				revokeVaultItemShare
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/items/{itemId}/shares/{shareId} [delete]
+//	@Param			id		path	int	true	"Vault id"
+//	@Param			itemId	path	int	true	"Vault Item id"
+//	@Param			shareId	path	int	true	"Share id"
+func HandleVaultItemSharesRevoke(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		shareId, err := strconv.Atoi(c.Param("shareId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionRead)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRead {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var share models.SecretShare
+		err = db.Preload("VaultItem").
+			First(&share, "id = ? AND vault_id = ? AND vault_item_id = ?", shareId, vaultId, vaultItemId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Share doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting secret share from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		if share.CreatorUserID != user.ID {
+			canManageItems, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId,
+				models.VaultPermissionManageItems)
+			if err != nil {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			if !canManageItems {
+				c.Status(http.StatusForbidden)
+				return
+			}
+		}
+
+		err = vaultservice.RevokeSecretShare(db, share.VaultItem, user, share.ID)
+		if err != nil {
+			if errors.Is(err, vaultservice.SecretShareNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Share doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Revoking secret share failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleSecretSharesRetrieve
+//
+//	@Summary		Retrieve a shared secret
+//	@Description	Doesn't require authentication. Every request counts as a view and the share is deleted once it's
+//	@Description	viewed as many times as it's allowed.
+//	@Tags			shares
+//	@Id				retrieveSecretShare
+//	@Param			token	path	string	true	"Share token"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleSecretSharesRetrieve.ShareRetrieveResponse
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/share/{token} [get]
+func HandleSecretSharesRetrieve(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type ShareRetrieveResponse struct {
+		EncryptedData  string    `json:"encrypted_data" binding:"required"`
+		RemainingViews int       `json:"remaining_views" binding:"required"`
+		ExpiresAt      time.Time `json:"expires_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		share, err := vaultservice.ConsumeSecretShare(db, c.Param("token"))
+		if err != nil {
+			if errors.Is(err, vaultservice.SecretShareNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Share doesn't exist or has expired."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Consuming secret share failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		// shared secrets must not be kept by any cache
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, ShareRetrieveResponse{
+			EncryptedData:  share.EncryptedData,
+			RemainingViews: share.MaxViews - share.ViewCount,
+			ExpiresAt:      share.ExpiresAt,
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/pagination"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleUsersMeSessionsList
+//
+//	@Summary	List active sessions of the user
+//	@Tags		users
+//	@Id			usersMeSessionsList
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleUsersMeSessionsList.SessionResponse
+//	@Failure	401
+//	@Failure	500
+//	@Router		/users/me/sessions [get]
+func HandleUsersMeSessionsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type SessionResponse struct {
+		Id         uint      `json:"id" binding:"required"`
+		UserAgent  string    `json:"user_agent" binding:"required"`
+		CreatedAt  time.Time `json:"created_at" binding:"required"`
+		LastSeenAt time.Time `json:"last_seen_at" binding:"required"`
+		LastSeenIP string    `json:"last_seen_ip" binding:"required"`
+		ExpiresAt  time.Time `json:"expires_at" binding:"required"`
+		IsCurrent  bool      `json:"is_current" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		currentSession, ok := middlewares.ExtractUserSessionFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user session from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var sessions []models.UserSession
+		err := db.Where("user_id = ? AND expires_at > ?", user.ID, time.Now()).Order("last_seen_at DESC").
+			Find(&sessions).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Listing user sessions failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, common.Map(sessions, func(session models.UserSession) SessionResponse {
+			return SessionResponse{
+				Id:         session.ID,
+				UserAgent:  session.UserAgent,
+				CreatedAt:  session.CreatedAt,
+				LastSeenAt: session.LastSeenAt,
+				LastSeenIP: session.LastSeenIP,
+				ExpiresAt:  session.ExpiresAt,
+				IsCurrent:  session.ID == currentSession.ID,
+			}
+		}))
+	}
+}
+
+// HandleUsersMeSessionsDelete
+//
+//	@Summary		Revoke a session of the user
+//	@Description	The current session can't be revoked with this endpoint, logout should be used instead.
+//	@Tags			users
+//	@Id				usersMeSessionsDelete
+//	@Param			id	path	int	true	"Session id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/users/me/sessions/{id} [delete]
+func HandleUsersMeSessionsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		sessionId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		currentSession, ok := middlewares.ExtractUserSessionFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user session from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		if uint(sessionId) == currentSession.ID {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Use logout to end the current session."})
+			return
+		}
+
+		err = authservice.RevokeUserSession(db, user.ID, uint(sessionId))
+		if err != nil {
+			if errors.Is(err, authservice.UserSessionNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Session not found."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Revoking user session failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Uint("session_id", uint(sessionId)).
+			Msg("User session revoked.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeSessionsRevokeOthers
+//
+//	@Summary	Log out everywhere else
+//	@Tags		users
+//	@Id			usersMeSessionsRevokeOthers
+//	@Produce	json
+//	@Success	200	{object}	controllers.HandleUsersMeSessionsRevokeOthers.RevokeOthersResponse
+//	@Failure	401
+//	@Failure	500
+//	@Router		/users/me/sessions/revoke-others [post]
+func HandleUsersMeSessionsRevokeOthers(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RevokeOthersResponse struct {
+		RevokedCount int64 `json:"revoked_count" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		currentSession, ok := middlewares.ExtractUserSessionFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user session from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		revokedCount, err := authservice.RevokeOtherUserSessions(db, user.ID, currentSession.ID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Revoking other user sessions failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Int64("revoked_count", revokedCount).
+			Msg("Other user sessions revoked.")
+
+		c.JSON(http.StatusOK, RevokeOthersResponse{RevokedCount: revokedCount})
+	}
+}
+
+// HandleUsersMeFailedLoginsList
+//
+//	@Summary	List failed login attempts to the account of the user
+//	@Tags		users
+//	@Id			usersMeFailedLoginsList
+//	@Produce	json
+//	@Param		page		query		int	false	"Page number"			default(1)	minimum(1)
+//	@Param		page_size	query		int	false	"Item count per page"	default(10)
+//	@Success	200			{object}	pagination.StandardPaginationResponse[controllers.HandleUsersMeFailedLoginsList.FailedLoginResponseItem]
+//	@Failure	401
+//	@Failure	500
+//	@Router		/users/me/failed-logins [get]
+func HandleUsersMeFailedLoginsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type FailedLoginResponseItem struct {
+		IPAddress string    `json:"ip_address" binding:"required"`
+		UserAgent string    `json:"user_agent" binding:"required"`
+		CreatedAt time.Time `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var count int64
+		err := db.Model(&models.FailedLogin{}).Where("user_id = ?", user.ID).Count(&count).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying failed login count failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var failedLogins []models.FailedLogin
+		err = db.Scopes(pagination.Paginate(c)).Where("user_id = ?", user.ID).Order("created_at DESC").
+			Find(&failedLogins).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying failed logins failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, pagination.StandardPaginationResponse[FailedLoginResponseItem]{
+			Results: common.Map(failedLogins, func(failedLogin models.FailedLogin) FailedLoginResponseItem {
+				return FailedLoginResponseItem{
+					IPAddress: failedLogin.IPAddress,
+					UserAgent: failedLogin.UserAgent,
+					CreatedAt: failedLogin.CreatedAt,
+				}
+			}),
+			Count: int(count),
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleUsersMeTOTPEnroll
+//
+//	@Summary		Start TOTP enrollment
+//	@Description	Generates a new TOTP secret. TOTP is not required on login until the enrollment is confirmed.
+//	@Tags			users
+//	@Id				usersMeTOTPEnroll
+//	@Param			request	body	controllers.HandleUsersMeTOTPEnroll.EnrollRequest	true	"Current password of the user"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeTOTPEnroll.EnrollResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/totp/enroll [post]
+func HandleUsersMeTOTPEnroll(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type EnrollRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	type EnrollResponse struct {
+		Secret          string `json:"secret" binding:"required"`
+		ProvisioningURI string `json:"provisioning_uri" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData EnrollRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err := authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		secret, provisioningURI, err := authservice.StartTOTPEnrollment(db, user)
+		if err != nil {
+			if errors.Is(err, authservice.TOTPAlreadyEnabledErr{}) {
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{Error: "TOTP is already enabled."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Starting TOTP enrollment failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, EnrollResponse{Secret: secret, ProvisioningURI: provisioningURI})
+	}
+}
+
+// HandleUsersMeTOTPConfirm
+//
+//	@Summary		Confirm TOTP enrollment
+//	@Description	Enables TOTP if the code is valid and returns the recovery codes. Recovery codes can't be retrieved
+//	@Description	again later.
+//	@Tags			users
+//	@Id				usersMeTOTPConfirm
+//	@Param			request	body	controllers.HandleUsersMeTOTPConfirm.ConfirmRequest	true	"Code generated by the authenticator app"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeTOTPConfirm.RecoveryCodesResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/totp/confirm [post]
+func HandleUsersMeTOTPConfirm(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type ConfirmRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	type RecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData ConfirmRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		recoveryCodes, err := authservice.ConfirmTOTPEnrollment(db, user, requestData.Code)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.TOTPAlreadyEnabledErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{Error: "TOTP is already enabled."})
+			case errors.Is(err, authservice.TOTPNotEnrolledErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "TOTP enrollment is not started."})
+			case errors.Is(err, authservice.InvalidTOTPCodeErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong code."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Confirming TOTP enrollment failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("TOTP enabled.")
+
+		c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+	}
+}
+
+// HandleUsersMeTOTPDisable
+//
+//	@Summary	Disable TOTP
+//	@Tags		users
+//	@Id			usersMeTOTPDisable
+//	@Param		request	body	controllers.HandleUsersMeTOTPDisable.DisableRequest	true	"Current password and a TOTP or recovery code"
+//	@Produce	json
+//	@Success	200
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/users/me/totp/disable [post]
+func HandleUsersMeTOTPDisable(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type DisableRequest struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData DisableRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err := authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		err = authservice.DisableTOTP(db, user, requestData.Code)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.TOTPNotEnrolledErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "TOTP is not enabled."})
+			case errors.Is(err, authservice.InvalidTOTPCodeErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong code."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Disabling TOTP failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Msg("TOTP disabled.")
+
+		c.Status(http.StatusOK)
+	}
+}
+
+// HandleUsersMeTOTPRecoveryCodes
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replaces the recovery codes of the user with new ones. Old recovery codes stop working.
+//	@Tags			users
+//	@Id				usersMeTOTPRecoveryCodes
+//	@Param			request	body	controllers.HandleUsersMeTOTPRecoveryCodes.RegenerateRequest	true	"A TOTP or recovery code"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeTOTPRecoveryCodes.RecoveryCodesResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/totp/recovery-codes [post]
+func HandleUsersMeTOTPRecoveryCodes(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RegenerateRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	type RecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData RegenerateRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		recoveryCodes, err := authservice.RegenerateRecoveryCodes(db, user, requestData.Code)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.TOTPNotEnrolledErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "TOTP is not enabled."})
+			case errors.Is(err, authservice.InvalidTOTPCodeErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong code."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Regenerating recovery codes failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
 	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
 	"github.com/berk-karaal/letuspass/backend/internal/models"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
@@ -24,8 +25,12 @@ import (
 //	@Router		/users/me [get]
 func HandleUsersMe(logger *logging.Logger) func(c *gin.Context) {
 	type MeResponse struct {
-		Email string `json:"email" binding:"required"`
-		Name  string `json:"name" binding:"required"`
+		Email       string `json:"email" binding:"required"`
+		Name        string `json:"name" binding:"required"`
+		Role        string `json:"role" binding:"required"`
+		TOTPEnabled bool   `json:"totp_enabled" binding:"required"`
+		// HasKeys is false if the user was created with OpenID Connect and hasn't set up their keys yet.
+		HasKeys bool `json:"has_keys" binding:"required"`
 	}
 
 	return func(c *gin.Context) {
@@ -37,8 +42,11 @@ func HandleUsersMe(logger *logging.Logger) func(c *gin.Context) {
 		}
 
 		c.JSON(http.StatusOK, MeResponse{
-			Email: user.Email,
-			Name:  user.Name,
+			Email:       user.Email,
+			Name:        user.Name,
+			Role:        user.Role,
+			TOTPEnabled: user.TOTPEnabled,
+			HasKeys:     authservice.HasKeys(user),
 		})
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/berk-karaal/letuspass/backend/internal/common"
 	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
 	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
 	"github.com/berk-karaal/letuspass/backend/internal/common/orderbyparam"
 	"github.com/berk-karaal/letuspass/backend/internal/common/pagination"
 	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
@@ -60,49 +61,14 @@ func HandleVaultsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context
 			return
 		}
 
-		vault := models.Vault{Name: requestData.Name}
-		if err := db.Create(&vault).Error; err != nil {
+		vault, err := vaultservice.CreateVault(db, user, requestData.Name, requestData.EncryptionIV,
+			requestData.EncryptedVaultKey)
+		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating vault failed.")
 			c.Status(http.StatusInternalServerError)
 			return
 		}
 
-		vaultKey := models.VaultKey{
-			VaultID:           vault.ID,
-			KeyOwnerUserID:    user.ID,
-			InviterUserID:     user.ID,
-			EncryptionIV:      requestData.EncryptionIV,
-			EncryptedVaultKey: requestData.EncryptedVaultKey,
-		}
-		if err := db.Create(&vaultKey).Error; err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating vault key failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		vaultPermissions := []models.VaultPermission{
-			{VaultID: vault.ID, UserID: user.ID, Permission: models.VaultPermissionManageVault},
-			{VaultID: vault.ID, UserID: user.ID, Permission: models.VaultPermissionDeleteVault},
-			{VaultID: vault.ID, UserID: user.ID, Permission: models.VaultPermissionManageItems},
-			{VaultID: vault.ID, UserID: user.ID, Permission: models.VaultPermissionRead},
-		}
-		if err := db.Create(&vaultPermissions).Error; err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating vault permissions failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		auditLog := models.VaultAuditLog{
-			VaultID:     vault.ID,
-			VaultItemID: 0,
-			UserID:      user.ID,
-			ActionCode:  models.AuditLogActionVaultCreate,
-			ActionData:  models.AuditLogDataVaultCreate(vault.Name),
-		}
-		if err := db.Create(&auditLog).Error; err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Saving audit log failed.")
-		}
-
 		c.JSON(http.StatusCreated, VaultCreateResponse{Id: vault.ID, Name: vault.Name})
 	}
 }
@@ -146,12 +112,11 @@ func HandleVaultsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context)
 			return
 		}
 
+		readableVaultIds := vaultservice.EffectivePermissions(db).Select("vault_id").
+			Where("user_id = ? AND permission = ?", user.ID, models.VaultPermissionRead)
+
 		var count int64
-		err = db.Select("count(*)").
-			Model(models.VaultPermission{}).
-			Where("vault_permissions.user_id = ? AND vault_permissions.permission = ?",
-				user.ID, models.VaultPermissionRead).
-			Count(&count).Error
+		err = db.Model(models.Vault{}).Where("vaults.id IN (?)", readableVaultIds).Count(&count).Error
 
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying user's vaults count failed.")
@@ -161,9 +126,8 @@ func HandleVaultsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context)
 
 		results := []VaultResponseItem{}
 		err = db.Scopes(pagination.Paginate(c)).Select("vaults.id, vaults.name, vaults.created_at, vaults.updated_at").
-			Model(models.VaultPermission{}).
-			Joins("LEFT OUTER JOIN vaults ON vault_permissions.vault_id = vaults.id").
-			Where("vault_permissions.user_id = ? AND vault_permissions.permission = ?", user.ID, models.VaultPermissionRead).
+			Model(models.Vault{}).
+			Where("vaults.id IN (?)", readableVaultIds).
 			Order(ordering).Scan(&results).Error
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying user's vaults failed.")
@@ -280,45 +244,17 @@ func HandleVaultDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context)
 			return
 		}
 
-		err = db.Delete(&models.Vault{}, vaultId).Error
-		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting Vault failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		err = db.Where("vault_id = ?", vaultId).Delete(&models.VaultPermission{}).Error
-		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting Vault Permissions failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		err = db.Where("vault_id = ?", vaultId).Delete(&models.VaultKey{}).Error
-		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting vault keys failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		err = db.Where("vault_id = ?", vaultId).Delete(&models.VaultItem{}).Error
+		err = vaultservice.DeleteVault(db, uint(vaultId), user.ID)
 		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting vault items failed.")
+			if errors.Is(err, vaultservice.VaultNotFoundErr{}) {
+				c.Status(http.StatusNotFound)
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting vault failed.")
 			c.Status(http.StatusInternalServerError)
 			return
 		}
 
-		auditLog := models.VaultAuditLog{
-			VaultID:     uint(vaultId),
-			VaultItemID: 0,
-			UserID:      user.ID,
-			ActionCode:  models.AuditLogActionVaultDelete,
-			ActionData:  models.AuditLogDataVaultDelete(),
-		}
-		if err := db.Create(&auditLog).Error; err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Saving audit log failed.")
-		}
-
 		c.Status(http.StatusNoContent)
 	}
 }
@@ -350,9 +286,7 @@ func HandleVaultsMyPermissions(logger *logging.Logger, db *gorm.DB) func(c *gin.
 			return
 		}
 
-		permissions := []string{}
-		err = db.Model(&models.VaultPermission{}).Select("permission").
-			Where("vault_id = ? AND user_id = ?", vaultId, user.ID).Scan(&permissions).Error
+		permissions, err := vaultservice.GetUserVaultPermissions(db, user.ID, uint(vaultId))
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault permissions failed.")
 			c.Status(http.StatusInternalServerError)
@@ -391,31 +325,24 @@ func HandleVaultsLeave(logger *logging.Logger, db *gorm.DB) func(c *gin.Context)
 			return
 		}
 
-		err = db.Where("vault_id = ? AND user_id = ?", vaultId, user.ID).Delete(&models.VaultPermission{}).Error
+		err = vaultservice.LeaveVault(db, uint(vaultId), user)
 		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Removing user vault permissions failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		err = db.Unscoped().Where("vault_id = ? AND key_owner_user_id = ?", vaultId, user.ID).Delete(&models.VaultKey{}).Error
-		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Removing user vault key failed.")
-			c.Status(http.StatusInternalServerError)
+			switch {
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}), errors.Is(err, vaultservice.UserNotVaultMemberErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.VaultOwnerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "Vault owner can't leave the vault. Transfer the ownership first."})
+			case errors.Is(err, vaultservice.LastVaultManagerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "Vault must have at least one user with manage_vault permission."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Leaving vault failed.")
+				c.Status(http.StatusInternalServerError)
+			}
 			return
 		}
 
-		auditLog := models.VaultAuditLog{
-			VaultID:     uint(vaultId),
-			VaultItemID: 0,
-			UserID:      user.ID,
-			ActionCode:  models.AuditLogActionVaultUserLeft,
-			ActionData:  models.AuditLogDataVaultUserLeft(),
-		}
-		if err := db.Create(&auditLog).Error; err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Saving audit log failed.")
-		}
-
 		c.Status(http.StatusNoContent)
 	}
 }
@@ -457,7 +384,10 @@ func HandleVaultsMyKey(logger *logging.Logger, db *gorm.DB) func(c *gin.Context)
 		}
 
 		vaultKey := models.VaultKey{}
-		err = db.Preload("InviterUser").First(&vaultKey, "vault_id = ? AND key_owner_user_id = ?", vaultId, user.ID).Error
+		// inviter may be a deleted user, their public key is still needed to decrypt the vault key
+		err = db.Preload("InviterUser", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+			First(&vaultKey, "vault_id = ? AND key_owner_user_id = ?", vaultId, user.ID).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				c.Status(http.StatusNotFound)
@@ -493,12 +423,13 @@ func HandleVaultsMyKey(logger *logging.Logger, db *gorm.DB) func(c *gin.Context)
 //	@Failure	422	{object}	bodybinder.validationErrorResponse
 //	@Failure	500
 //	@Router		/vaults/{id}/manage/add-user [post]
-func HandleVaultsManageAddUser(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+func HandleVaultsManageAddUser(mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type AddUserRequest struct {
-		Email                string   `json:"email" binding:"required"`
-		Permissions          []string `json:"permissions" binding:"required"`
-		VaultKeyEncryptionIV string   `json:"vault_key_encryption_iv" binding:"required"`
-		EncryptedVaultKey    string   `json:"encrypted_vault_key" binding:"required"`
+		Email                string     `json:"email" binding:"required"`
+		Permissions          []string   `json:"permissions" binding:"required"`
+		ExpiresAt            *time.Time `json:"expires_at"`
+		VaultKeyEncryptionIV string     `json:"vault_key_encryption_iv" binding:"required"`
+		EncryptedVaultKey    string     `json:"encrypted_vault_key" binding:"required"`
 	}
 
 	return func(c *gin.Context) {
@@ -531,86 +462,29 @@ func HandleVaultsManageAddUser(logger *logging.Logger, db *gorm.DB) func(c *gin.
 			return
 		}
 
-		var newUser models.User
-		err = db.First(&newUser, "email = ?", requestData.Email).Error
+		newUser, err := vaultservice.AddUserToVault(db, uint(vaultId), user, requestData.Email, requestData.Permissions,
+			requestData.ExpiresAt, requestData.VaultKeyEncryptionIV, requestData.EncryptedVaultKey)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User with given email not found."})
-				return
+			var invalidPermissionErr vaultservice.InvalidPermissionErr
+			switch {
+			case errors.As(err, &invalidPermissionErr):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("Given permission '%s' is invalid.", invalidPermissionErr.Permission)})
+			case errors.Is(err, vaultservice.InvalidExpiryErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Expiry time must be in the future."})
+			case errors.Is(err, vaultservice.UserNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "User with given email not found. Invite them to the vault instead."})
+			case errors.Is(err, vaultservice.UserAlreadyVaultMemberErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User is already added to vault."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Adding user to vault failed.")
+				c.Status(http.StatusInternalServerError)
 			}
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying users by email failed.")
-			c.Status(http.StatusInternalServerError)
 			return
 		}
 
-		var isAlreadyAdded bool
-		err = db.Model(&models.VaultPermission{}).Select("count(*) > 0").
-			Where("vault_id = ? AND user_id = ?", vaultId, newUser.ID).Scan(&isAlreadyAdded).Error
-		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying if user already added to vault failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-		if isAlreadyAdded {
-			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User is already added to vault."})
-			return
-		}
-
-		// check and prepare vault permission record
-		newUserVaultPermissions := []models.VaultPermission{}
-		for _, p := range requestData.Permissions {
-			if !slices.Contains([]string{
-				models.VaultPermissionManageVault,
-				models.VaultPermissionDeleteVault,
-				models.VaultPermissionManageItems}, p) {
-				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: fmt.Sprintf("Given permission '%s' is invalid.", p)})
-				return
-			}
-			newUserVaultPermissions = append(newUserVaultPermissions, models.VaultPermission{
-				VaultID:    uint(vaultId),
-				UserID:     newUser.ID,
-				Permission: p,
-			})
-		}
-		// append the read permission since it's mandatory
-		newUserVaultPermissions = append(newUserVaultPermissions, models.VaultPermission{
-			VaultID:    uint(vaultId),
-			UserID:     newUser.ID,
-			Permission: models.VaultPermissionRead,
-		})
-
-		err = db.Create(&newUserVaultPermissions).Error
-		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating vault permissions for newly added user failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		vaultKeyRecord := models.VaultKey{
-			VaultID:           uint(vaultId),
-			KeyOwnerUserID:    newUser.ID,
-			InviterUserID:     user.ID,
-			EncryptionIV:      requestData.VaultKeyEncryptionIV,
-			EncryptedVaultKey: requestData.EncryptedVaultKey,
-		}
-		err = db.Create(&vaultKeyRecord).Error
-		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating vault key record for newly added user failed.")
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
-		auditLog := models.VaultAuditLog{
-			VaultID:     uint(vaultId),
-			VaultItemID: 0,
-			UserID:      user.ID,
-			ActionCode:  models.AuditLogActionVaultAddUser,
-			ActionData: models.AuditLogDataVaultAddUser(newUser.Email, common.Map(newUserVaultPermissions,
-				func(i models.VaultPermission) string { return i.Permission })),
-		}
-		if err := db.Create(&auditLog).Error; err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Saving audit log failed.")
-		}
+		sendAddedToVaultMail(c, mail, logger, db, uint(vaultId), user, newUser)
 
 		c.Status(http.StatusOK)
 	}
@@ -630,9 +504,11 @@ func HandleVaultsManageAddUser(logger *logging.Logger, db *gorm.DB) func(c *gin.
 //	@Router		/vaults/{id}/manage/users [get]
 func HandleVaultsManageListUsers(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type UsersResponseItem struct {
-		Id          int      `json:"id" binding:"required"`
-		Email       string   `json:"email" binding:"required"`
-		Permissions []string `json:"permissions" binding:"required"`
+		Id          int        `json:"id" binding:"required"`
+		Email       string     `json:"email" binding:"required"`
+		Permissions []string   `json:"permissions" binding:"required"`
+		IsOwner     bool       `json:"is_owner" binding:"required"`
+		ExpiresAt   *time.Time `json:"expires_at"`
 	}
 
 	return func(c *gin.Context) {
@@ -660,12 +536,22 @@ func HandleVaultsManageListUsers(logger *logging.Logger, db *gorm.DB) func(c *gi
 			return
 		}
 
+		var vault models.Vault
+		err = db.First(&vault, vaultId).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
 		var usersAndPermissions []struct {
-			Id         int    `gorm:"column:id"`
-			Email      string `gorm:"column:email"`
-			Permission string `gorm:"column:permission"`
+			Id         int        `gorm:"column:id"`
+			Email      string     `gorm:"column:email"`
+			Permission string     `gorm:"column:permission"`
+			ExpiresAt  *time.Time `gorm:"column:expires_at"`
 		}
-		err = db.Select("users.id as id, users.email as email, vault_permissions.permission as permission").
+		err = db.Select("users.id as id, users.email as email, vault_permissions.permission as permission, "+
+			"vault_permissions.expires_at as expires_at").
 			Model(&models.VaultPermission{}).
 			Joins("LEFT OUTER JOIN users ON vault_permissions.user_id = users.id").
 			Where("vault_permissions.vault_id = ?", vaultId).
@@ -682,6 +568,7 @@ func HandleVaultsManageListUsers(logger *logging.Logger, db *gorm.DB) func(c *gi
 			email string
 		}
 		userAndPermissionsMap := make(map[UserKey][]string)
+		userExpiryMap := make(map[UserKey]*time.Time)
 		for _, v := range usersAndPermissions {
 			userKey := UserKey{v.Id, v.Email}
 			_, ok := userAndPermissionsMap[userKey]
@@ -689,11 +576,18 @@ func HandleVaultsManageListUsers(logger *logging.Logger, db *gorm.DB) func(c *gi
 				userAndPermissionsMap[userKey] = []string{}
 			}
 			userAndPermissionsMap[userKey] = append(userAndPermissionsMap[userKey], v.Permission)
+			userExpiryMap[userKey] = v.ExpiresAt
 		}
 
 		result := []UsersResponseItem{}
 		for k, v := range userAndPermissionsMap {
-			result = append(result, UsersResponseItem{Id: k.id, Email: k.email, Permissions: v})
+			result = append(result, UsersResponseItem{
+				Id:          k.id,
+				Email:       k.email,
+				Permissions: v,
+				IsOwner:     uint(k.id) == vault.OwnerUserID,
+				ExpiresAt:   userExpiryMap[k],
+			})
 		}
 		slices.SortFunc(result, func(i, j UsersResponseItem) int {
 			return strings.Compare(i.Email, j.Email)
@@ -713,6 +607,7 @@ func HandleVaultsManageListUsers(logger *logging.Logger, db *gorm.DB) func(c *gi
 //	@Failure	400	{object}	schemas.BadRequestResponse
 //	@Failure	401
 //	@Failure	403
+//	@Failure	404
 //	@Failure	422	{object}	bodybinder.validationErrorResponse
 //	@Failure	500
 //	@Router		/vaults/{id}/manage/users [delete]
@@ -751,41 +646,313 @@ func HandleVaultsManageRemoveUser(logger *logging.Logger, db *gorm.DB) func(c *g
 			return
 		}
 
-		var removedUser models.User
-		err = db.First(&removedUser, requestData.UserId).Error
+		_, err = vaultservice.RemoveUserFromVault(db, uint(vaultId), user, uint(requestData.UserId))
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
+			switch {
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}), errors.Is(err, vaultservice.UserNotFoundErr{}),
+				errors.Is(err, vaultservice.UserNotVaultMemberErr{}):
 				c.Status(http.StatusNotFound)
-				return
+			case errors.Is(err, vaultservice.VaultOwnerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Vault owner can't be removed from the vault."})
+			case errors.Is(err, vaultservice.LastVaultManagerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "Vault must have at least one user with manage_vault permission."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Removing user from vault failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleVaultsManageUpdateUserPermissions
+//
+//	@Summary	Replace permissions of a vault user
+//	@Tags		vault manage
+//	@Id			updateVaultUserPermissions
+//	@Param		id		path		int																				true	"Vault id"
+//	@Param		userId	path		int																				true	"User id"
+//	@Param		request	body		controllers.HandleVaultsManageUpdateUserPermissions.UpdatePermissionsRequest	true	"New permissions of the user"
+//	@Success	200		{object}	controllers.HandleVaultsManageUpdateUserPermissions.UpdatePermissionsResponse
+//	@Failure	400		{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/vaults/{id}/manage/users/{userId} [patch]
+func HandleVaultsManageUpdateUserPermissions(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type UpdatePermissionsRequest struct {
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+
+	type UpdatePermissionsResponse struct {
+		Id          uint     `json:"id" binding:"required"`
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		memberId, err := strconv.Atoi(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData UpdatePermissionsRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		_, newPermissions, err := vaultservice.UpdateMemberPermissions(db, uint(vaultId), user, uint(memberId),
+			requestData.Permissions)
+		if err != nil {
+			var invalidPermissionErr vaultservice.InvalidPermissionErr
+			switch {
+			case errors.As(err, &invalidPermissionErr):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("Given permission '%s' is invalid.", invalidPermissionErr.Permission)})
+			case errors.Is(err, vaultservice.LastVaultManagerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "Vault must have at least one user with manage_vault permission."})
+			case errors.Is(err, vaultservice.VaultOwnerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "Vault owner must keep manage_vault permission."})
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault doesn't exist."})
+			case errors.Is(err, vaultservice.UserNotFoundErr{}), errors.Is(err, vaultservice.UserNotVaultMemberErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "User is not a member of the vault."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Updating vault permissions of user failed.")
+				c.Status(http.StatusInternalServerError)
 			}
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying user failed.")
+			return
+		}
+
+		c.JSON(http.StatusOK, UpdatePermissionsResponse{Id: uint(memberId), Permissions: newPermissions})
+	}
+}
+
+// HandleVaultsManageTransferOwnership
+//
+//	@Summary	Transfer ownership of the vault to another vault user
+//	@Tags		vault manage
+//	@Id			transferVaultOwnership
+//	@Param		id		path	int																			true	"Vault id"
+//	@Param		request	body	controllers.HandleVaultsManageTransferOwnership.TransferOwnershipRequest	true	"ID of the new owner"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/vaults/{id}/manage/transfer-ownership [post]
+func HandleVaultsManageTransferOwnership(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type TransferOwnershipRequest struct {
+		UserId int `json:"user_id" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
 			c.Status(http.StatusInternalServerError)
 			return
 		}
 
-		err = db.Where("vault_id = ? AND user_id = ?", vaultId, removedUser.ID).Delete(&models.VaultPermission{}).Error
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
 		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Removing user from vault failed.")
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
 			c.Status(http.StatusInternalServerError)
 			return
 		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData TransferOwnershipRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
 
-		err = db.Unscoped().Where("vault_id = ? AND key_owner_user_id = ?", vaultId, removedUser.ID).Delete(&models.VaultKey{}).Error
+		err = vaultservice.TransferVaultOwnership(db, uint(vaultId), user, uint(requestData.UserId))
 		if err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Removing user from vault failed.")
+			switch {
+			case errors.Is(err, vaultservice.NotVaultOwnerErr{}):
+				c.Status(http.StatusForbidden)
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault doesn't exist."})
+			case errors.Is(err, vaultservice.UserNotFoundErr{}), errors.Is(err, vaultservice.UserNotVaultMemberErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "User is not a member of the vault."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Transferring vault ownership failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleVaultsManageRotateKey
+//
+//	@Summary		Rotate the vault key
+//	@Description	Replaces the vault key of every vault user and re-encrypts every vault item with the new vault key.
+//	@Description	Request must contain exactly the current users and items of the vault. Revisions and deleted items
+//	@Description	of the vault are permanently deleted since they are encrypted with the old vault key. data of the
+//	@Description	items which have one and the metadata of every attachment of the vault must be re-encrypted too.
+//	@Tags			vault manage
+//	@Id				rotateVaultKey
+//	@Param			id		path	int															true	"Vault id"
+//	@Param			request	body	controllers.HandleVaultsManageRotateKey.RotateKeyRequest	true	"Re-encrypted vault items and vault keys"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/manage/rotate-key [post]
+func HandleVaultsManageRotateKey(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RotatedItem struct {
+		Id                uint           `json:"id" binding:"required"`
+		EncryptionIV      string         `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string         `json:"encrypted_username"`
+		EncryptedPassword string         `json:"encrypted_password"`
+		EncryptedNote     string         `json:"encrypted_note"`
+		Data              *vaultItemData `json:"data"`
+	}
+
+	type RotatedKey struct {
+		UserId            uint   `json:"user_id" binding:"required"`
+		EncryptionIV      string `json:"encryption_iv" binding:"required"`
+		EncryptedVaultKey string `json:"encrypted_vault_key" binding:"required"`
+	}
+
+	type RotatedAttachment struct {
+		Id                uint   `json:"id" binding:"required"`
+		EncryptionIV      string `json:"encryption_iv" binding:"required"`
+		EncryptedMetadata string `json:"encrypted_metadata" binding:"required"`
+	}
+
+	type RotateKeyRequest struct {
+		Items       []RotatedItem       `json:"items" binding:"required,dive"`
+		Attachments []RotatedAttachment `json:"attachments" binding:"dive"`
+		Keys        []RotatedKey        `json:"keys" binding:"required,min=1,dive"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
 			c.Status(http.StatusInternalServerError)
 			return
 		}
 
-		auditLog := models.VaultAuditLog{
-			VaultID:     uint(vaultId),
-			VaultItemID: 0,
-			UserID:      user.ID,
-			ActionCode:  models.AuditLogActionVaultRemoveUser,
-			ActionData:  models.AuditLogDataVaultRemoveUser(removedUser.Email),
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
 		}
-		if err := db.Create(&auditLog).Error; err != nil {
-			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Saving audit log failed.")
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData RotateKeyRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		items := common.Map(requestData.Items, func(i RotatedItem) vaultservice.RotatedVaultItem {
+			return vaultservice.RotatedVaultItem{
+				Id:                i.Id,
+				EncryptionIV:      i.EncryptionIV,
+				EncryptedUsername: i.EncryptedUsername,
+				EncryptedPassword: i.EncryptedPassword,
+				EncryptedNote:     i.EncryptedNote,
+				Data:              i.Data.toVaultItemData(),
+			}
+		})
+		attachments := common.Map(requestData.Attachments, func(a RotatedAttachment) vaultservice.RotatedAttachment {
+			return vaultservice.RotatedAttachment{
+				Id:                a.Id,
+				EncryptionIV:      a.EncryptionIV,
+				EncryptedMetadata: a.EncryptedMetadata,
+			}
+		})
+		keys := common.Map(requestData.Keys, func(k RotatedKey) vaultservice.WrappedVaultKey {
+			return vaultservice.WrappedVaultKey{
+				UserId:            k.UserId,
+				EncryptionIV:      k.EncryptionIV,
+				EncryptedVaultKey: k.EncryptedVaultKey,
+			}
+		})
+
+		err = vaultservice.RotateVaultKey(db, uint(vaultId), user, items, attachments, keys)
+		if err != nil {
+			var dataMissingErr vaultservice.VaultItemDataMissingErr
+			switch {
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.DuplicateEntryErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Each user, item and attachment must be given once."})
+			case errors.As(err, &vaultservice.UnsupportedVaultItemDataErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Vault item data version is not supported."})
+			case errors.As(err, &dataMissingErr):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("Data of vault item %d must be re-encrypted too.", dataMissingErr.VaultItemId)})
+			case errors.Is(err, vaultservice.VaultChangedErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{
+					Error: "Vault users, items or attachments have changed. Fetch them again and retry."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Rotating vault key failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
 		}
 
 		c.Status(http.StatusNoContent)
@@ -0,0 +1,383 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// sharedVaultKey is the vault key encrypted for a user who doesn't have the vault key yet.
+type sharedVaultKey struct {
+	UserId            uint   `json:"user_id" binding:"required"`
+	EncryptionIV      string `json:"encryption_iv" binding:"required"`
+	EncryptedVaultKey string `json:"encrypted_vault_key" binding:"required"`
+}
+
+func (k sharedVaultKey) toWrappedVaultKey() vaultservice.WrappedVaultKey {
+	return vaultservice.WrappedVaultKey{
+		UserId:            k.UserId,
+		EncryptionIV:      k.EncryptionIV,
+		EncryptedVaultKey: k.EncryptedVaultKey,
+	}
+}
+
+// HandleVaultsManageListGroups
+//
+//	@Summary	List groups that have access to vault
+//	@Tags		vault manage
+//	@Id			listVaultGroups
+//	@Param		id	path	int	true	"Vault id"
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleVaultsManageListGroups.GroupsResponseItem
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	500
+//	@Router		/vaults/{id}/manage/groups [get]
+func HandleVaultsManageListGroups(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type GroupsResponseItem struct {
+		Id          uint     `json:"id" binding:"required"`
+		Name        string   `json:"name" binding:"required"`
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var groupPermissions []models.VaultGroupPermission
+		err = db.Preload("Group").Where("vault_id = ?", vaultId).Order("group_id ASC, permission ASC").
+			Find(&groupPermissions).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault groups failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []GroupsResponseItem{}
+		for _, p := range groupPermissions {
+			if len(results) == 0 || results[len(results)-1].Id != p.GroupID {
+				results = append(results, GroupsResponseItem{Id: p.GroupID, Name: p.Group.Name, Permissions: []string{}})
+			}
+			results[len(results)-1].Permissions = append(results[len(results)-1].Permissions, p.Permission)
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// HandleVaultsManageAddGroup
+//
+//	@Summary		Give a group access to vault
+//	@Description	Read permission is given to the group in addition to the given permissions. keys should contain the
+//	@Description	vault key encrypted for the group members who don't have the vault key yet, members left out can
+//	@Description	be given the vault key later through the pending keys endpoint. Only the managers of the group can
+//	@Description	add it to a vault.
+//	@Tags			vault manage
+//	@Id				addGroupToVault
+//	@Param			id		path	int														true	"Vault id"
+//	@Param			request	body	controllers.HandleVaultsManageAddGroup.AddGroupRequest	true	"Group and its permissions"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403	{object}	schemas.ForbiddenResponse
+//	@Failure		404
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/manage/groups [post]
+func HandleVaultsManageAddGroup(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type AddGroupRequest struct {
+		GroupId     uint             `json:"group_id" binding:"required"`
+		Permissions []string         `json:"permissions" binding:"required"`
+		Keys        []sharedVaultKey `json:"keys" binding:"dive"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData AddGroupRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		err = vaultservice.GrantGroupVaultAccess(db, uint(vaultId), user, requestData.GroupId, requestData.Permissions,
+			common.Map(requestData.Keys, sharedVaultKey.toWrappedVaultKey))
+		if err != nil {
+			var invalidPermissionErr vaultservice.InvalidPermissionErr
+			var keyNotPendingErr vaultservice.VaultKeyNotPendingErr
+			switch {
+			case errors.As(err, &invalidPermissionErr):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("Given permission '%s' is invalid.", invalidPermissionErr.Permission)})
+			case errors.As(err, &keyNotPendingErr):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("User %d doesn't need a vault key.", keyNotPendingErr.UserId)})
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.GroupNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Group with given id not found."})
+			case errors.Is(err, vaultservice.NotGroupManagerErr{}):
+				c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{Error: "Only the managers of the group can add it to a vault."})
+			case errors.Is(err, vaultservice.GroupAlreadyVaultMemberErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Group is already added to vault."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Adding group to vault failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleVaultsManageRemoveGroup
+//
+//	@Summary	Remove a group's access to vault
+//	@Tags		vault manage
+//	@Id			removeGroupFromVault
+//	@Param		id		path	int	true	"Vault id"
+//	@Param		groupId	path	int	true	"Group id"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404
+//	@Failure	500
+//	@Router		/vaults/{id}/manage/groups/{groupId} [delete]
+func HandleVaultsManageRemoveGroup(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		groupId, err := strconv.Atoi(c.Param("groupId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Group id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		err = vaultservice.RevokeGroupVaultAccess(db, uint(vaultId), user, uint(groupId))
+		if err != nil {
+			switch {
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}), errors.Is(err, vaultservice.GroupNotFoundErr{}),
+				errors.Is(err, vaultservice.GroupNotVaultMemberErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.LastVaultManagerErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: "Vault must have at least one user with manage_vault permission."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Removing group from vault failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleVaultsManageListPendingKeys
+//
+//	@Summary		List users waiting for the vault key
+//	@Description	Lists the users who have access to the vault through a group but don't have the vault key yet.
+//	@Tags			vault manage
+//	@Id				listVaultPendingKeys
+//	@Param			id	path	int	true	"Vault id"
+//	@Produce		json
+//	@Success		200	{object}	[]controllers.HandleVaultsManageListPendingKeys.PendingKeyResponseItem
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500
+//	@Router			/vaults/{id}/manage/pending-keys [get]
+func HandleVaultsManageListPendingKeys(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type PendingKeyResponseItem struct {
+		UserId    uint   `json:"user_id" binding:"required"`
+		Email     string `json:"email" binding:"required"`
+		PublicKey string `json:"public_key" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		users, err := vaultservice.ListPendingVaultKeyUsers(db, uint(vaultId))
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying pending vault keys failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, common.Map(users, func(u models.User) PendingKeyResponseItem {
+			return PendingKeyResponseItem{UserId: u.ID, Email: u.Email, PublicKey: u.PublicKey}
+		}))
+	}
+}
+
+// HandleVaultsManageSharePendingKeys
+//
+//	@Summary	Share the vault key with users waiting for it
+//	@Tags		vault manage
+//	@Id			shareVaultPendingKeys
+//	@Param		id		path	int																		true	"Vault id"
+//	@Param		request	body	controllers.HandleVaultsManageSharePendingKeys.SharePendingKeysRequest	true	"Vault key encrypted for each user"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/vaults/{id}/manage/pending-keys [post]
+func HandleVaultsManageSharePendingKeys(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type SharePendingKeysRequest struct {
+		Keys []sharedVaultKey `json:"keys" binding:"required,min=1,dive"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData SharePendingKeysRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		err = vaultservice.SharePendingVaultKeys(db, uint(vaultId), user,
+			common.Map(requestData.Keys, sharedVaultKey.toWrappedVaultKey))
+		if err != nil {
+			var keyNotPendingErr vaultservice.VaultKeyNotPendingErr
+			switch {
+			case errors.As(err, &keyNotPendingErr):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("User %d doesn't need a vault key.", keyNotPendingErr.UserId)})
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Sharing pending vault keys failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,499 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleVaultsManageInviteUser
+//
+//	@Summary		Invite an email address to vault
+//	@Description	Unlike add-user, the invited email doesn't need to belong to a registered user. Once the invitee
+//	@Description	accepts the invitation, a vault manager completes it by sharing the vault key with the invitee.
+//	@Tags			vault manage
+//	@Id				inviteUserToVault
+//	@Param			id		path	int															true	"Vault id"
+//	@Param			request	body	controllers.HandleVaultsManageInviteUser.InviteUserRequest	true	"Invitation data"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleVaultsManageInviteUser.InviteUserResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/manage/invitations [post]
+func HandleVaultsManageInviteUser(apiConfig *config.RestapiConfig, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type InviteUserRequest struct {
+		Email       string   `json:"email" binding:"required,email"`
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+
+	type InviteUserResponse struct {
+		Id uint `json:"id" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData InviteUserRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		invitation, err := vaultservice.InviteToVault(db, uint(vaultId), user, requestData.Email, requestData.Permissions)
+		if err != nil {
+			var invalidPermissionErr vaultservice.InvalidPermissionErr
+			switch {
+			case errors.As(err, &invalidPermissionErr):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+					Error: fmt.Sprintf("Given permission '%s' is invalid.", invalidPermissionErr.Permission)})
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.UserAlreadyVaultMemberErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User is already added to vault."})
+			case errors.Is(err, vaultservice.InvitationAlreadyExistsErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Email is already invited to vault."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Inviting user to vault failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		var vault models.Vault
+		if err := db.Select("name").First(&vault, vaultId).Error; err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault failed.")
+		} else {
+			sendMail(c, mail, logger, invitation.Email, "vault_invitation", map[string]any{
+				"InviterEmail":   user.Email,
+				"VaultName":      vault.Name,
+				"InvitationsURL": apiConfig.FrontendURL + "/invitations",
+			})
+		}
+
+		c.JSON(http.StatusCreated, InviteUserResponse{Id: invitation.ID})
+	}
+}
+
+// HandleVaultsManageListInvitations
+//
+//	@Summary		List open invitations of vault
+//	@Description	Accepted invitations contain the public key of the invitee which the vault key should be encrypted
+//	@Description	with to complete the invitation.
+//	@Tags			vault manage
+//	@Id				listVaultInvitations
+//	@Param			id	path	int	true	"Vault id"
+//	@Produce		json
+//	@Success		200	{object}	[]controllers.HandleVaultsManageListInvitations.InvitationResponseItem
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		500
+//	@Router			/vaults/{id}/manage/invitations [get]
+func HandleVaultsManageListInvitations(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type InvitationResponseItem struct {
+		Id                   uint      `json:"id" binding:"required"`
+		Email                string    `json:"email" binding:"required"`
+		Permissions          []string  `json:"permissions" binding:"required"`
+		InviterEmail         string    `json:"inviter_email" binding:"required"`
+		IsAccepted           bool      `json:"is_accepted" binding:"required"`
+		InviteeUserId        *uint     `json:"invitee_user_id"`
+		InviteeUserPublicKey *string   `json:"invitee_user_public_key"`
+		CreatedAt            time.Time `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var invitations []models.VaultInvitation
+		err = db.Preload("InviterUser").Preload("InviteeUser").Where("vault_id = ?", vaultId).
+			Order("created_at ASC").Find(&invitations).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault invitations failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []InvitationResponseItem{}
+		for _, invitation := range invitations {
+			item := InvitationResponseItem{
+				Id:           invitation.ID,
+				Email:        invitation.Email,
+				Permissions:  invitation.Permissions,
+				InviterEmail: invitation.InviterUser.Email,
+				IsAccepted:   invitation.InviteeUserID != 0,
+				CreatedAt:    invitation.CreatedAt,
+			}
+			if item.IsAccepted {
+				item.InviteeUserId = &invitation.InviteeUser.ID
+				item.InviteeUserPublicKey = &invitation.InviteeUser.PublicKey
+			}
+			results = append(results, item)
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// HandleVaultsManageRevokeInvitation
+//
+//	@Summary	Revoke an invitation of vault
+//	@Tags		vault manage
+//	@Id			revokeVaultInvitation
+//	@Param		id				path	int	true	"Vault id"
+//	@Param		invitationId	path	int	true	"Invitation id"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404
+//	@Failure	500
+//	@Router		/vaults/{id}/manage/invitations/{invitationId} [delete]
+func HandleVaultsManageRevokeInvitation(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		invitationId, err := strconv.Atoi(c.Param("invitationId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Invitation id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		err = vaultservice.RevokeVaultInvitation(db, uint(vaultId), user, uint(invitationId))
+		if err != nil {
+			if errors.Is(err, vaultservice.InvitationNotFoundErr{}) {
+				c.Status(http.StatusNotFound)
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Revoking vault invitation failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleVaultsManageCompleteInvitation
+//
+//	@Summary		Complete an accepted invitation of vault
+//	@Description	Gives the invitee access to the vault with the permissions in the invitation. Vault key must be
+//	@Description	encrypted with the public key of the invitee.
+//	@Tags			vault manage
+//	@Id				completeVaultInvitation
+//	@Param			id				path	int																			true	"Vault id"
+//	@Param			invitationId	path	int																			true	"Invitation id"
+//	@Param			request			body	controllers.HandleVaultsManageCompleteInvitation.CompleteInvitationRequest	true	"Vault key encrypted for the invitee"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/manage/invitations/{invitationId}/complete [post]
+func HandleVaultsManageCompleteInvitation(mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type CompleteInvitationRequest struct {
+		VaultKeyEncryptionIV string `json:"vault_key_encryption_iv" binding:"required"`
+		EncryptedVaultKey    string `json:"encrypted_vault_key" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		invitationId, err := strconv.Atoi(c.Param("invitationId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Invitation id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageVault, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageVault)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageVault {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var requestData CompleteInvitationRequest
+		if ok = bodybinder.Bind(&requestData, c); !ok {
+			return
+		}
+
+		invitee, err := vaultservice.CompleteVaultInvitation(db, uint(vaultId), user, uint(invitationId),
+			requestData.VaultKeyEncryptionIV, requestData.EncryptedVaultKey)
+		if err != nil {
+			switch {
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}), errors.Is(err, vaultservice.InvitationNotFoundErr{}),
+				errors.Is(err, vaultservice.UserNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.InvitationNotAcceptedErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Invitation is not accepted yet."})
+			case errors.Is(err, vaultservice.UserAlreadyVaultMemberErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "User is already added to vault."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Completing vault invitation failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		sendAddedToVaultMail(c, mail, logger, db, uint(vaultId), user, invitee)
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeInvitationsList
+//
+//	@Summary		List vault invitations sent to the user
+//	@Description	The list is empty until the user verifies their email address.
+//	@Tags			users
+//	@Id				listMyVaultInvitations
+//	@Produce		json
+//	@Success		200	{object}	[]controllers.HandleUsersMeInvitationsList.InvitationResponseItem
+//	@Failure		401
+//	@Failure		500
+//	@Router			/users/me/invitations [get]
+func HandleUsersMeInvitationsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type InvitationResponseItem struct {
+		Id           uint      `json:"id" binding:"required"`
+		VaultName    string    `json:"vault_name" binding:"required"`
+		InviterEmail string    `json:"inviter_email" binding:"required"`
+		Permissions  []string  `json:"permissions" binding:"required"`
+		IsAccepted   bool      `json:"is_accepted" binding:"required"`
+		CreatedAt    time.Time `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []InvitationResponseItem{}
+		if user.EmailVerifiedAt == nil {
+			c.JSON(http.StatusOK, results)
+			return
+		}
+
+		var invitations []models.VaultInvitation
+		err := db.Preload("Vault").Preload("InviterUser").Where("email = ?", user.Email).
+			Order("created_at DESC").Find(&invitations).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying user's vault invitations failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		for _, invitation := range invitations {
+			results = append(results, InvitationResponseItem{
+				Id:           invitation.ID,
+				VaultName:    invitation.Vault.Name,
+				InviterEmail: invitation.InviterUser.Email,
+				Permissions:  invitation.Permissions,
+				IsAccepted:   invitation.InviteeUserID != 0,
+				CreatedAt:    invitation.CreatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// HandleUsersMeInvitationsAccept
+//
+//	@Summary		Accept a vault invitation
+//	@Description	User gets access to the vault once a vault manager completes the invitation.
+//	@Tags			users
+//	@Id				acceptVaultInvitation
+//	@Param			id	path	int	true	"Invitation id"
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403	{object}	schemas.ForbiddenResponse
+//	@Failure		404
+//	@Failure		500
+//	@Router			/users/me/invitations/{id}/accept [post]
+func HandleUsersMeInvitationsAccept(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		invitationId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = vaultservice.AcceptVaultInvitation(db, uint(invitationId), user)
+		if err != nil {
+			switch {
+			case errors.Is(err, vaultservice.InvitationNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.InvitationAlreadyAcceptedErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Invitation is already accepted."})
+			case errors.Is(err, vaultservice.EmailNotVerifiedErr{}):
+				c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{
+					Error: "Email address must be verified to respond to vault invitations."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Accepting vault invitation failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleUsersMeInvitationsDecline
+//
+//	@Summary	Decline a vault invitation
+//	@Tags		users
+//	@Id			declineVaultInvitation
+//	@Param		id	path	int	true	"Invitation id"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403	{object}	schemas.ForbiddenResponse
+//	@Failure	404
+//	@Failure	500
+//	@Router		/users/me/invitations/{id}/decline [post]
+func HandleUsersMeInvitationsDecline(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		invitationId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = vaultservice.DeclineVaultInvitation(db, uint(invitationId), user)
+		if err != nil {
+			switch {
+			case errors.Is(err, vaultservice.InvitationNotFoundErr{}):
+				c.Status(http.StatusNotFound)
+			case errors.Is(err, vaultservice.InvitationAlreadyAcceptedErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Invitation is already accepted."})
+			case errors.Is(err, vaultservice.EmailNotVerifiedErr{}):
+				c.JSON(http.StatusForbidden, schemas.ForbiddenResponse{
+					Error: "Email address must be verified to respond to vault invitations."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Declining vault invitation failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/blobstore"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleVaultItemAttachmentsUpload
+//
+//	@Summary		Upload an attachment to a vault item
+//	@Description	Request body is the file content encrypted on the client, it is streamed to the storage as it is.
+//	@Description	X-Encrypted-Metadata should hold the file name, the content type and the key the content is
+//	@Description	encrypted with, encrypted with the vault key. Attachments can't be larger than the maximum
+//	@Description	attachment size and can't exceed the attachment quota of the vault.
+//	@Tags			vault items
+//	@Id				uploadVaultItemAttachment
+//	@Accept			octet-stream
+//	@Param			X-Encryption-IV			header	string	true	"Encryption IV of the metadata"
+//	@Param			X-Encrypted-Metadata	header	string	true	"Encrypted metadata of the attachment"
+//	@Param			content					body	string	true	"Encrypted file content"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleVaultItemAttachmentsUpload.AttachmentUploadResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		413	{object}	schemas.PayloadTooLargeResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/items/{itemId}/attachments [post]
+//	@Param			id		path	int	true	"Vault id"
+//	@Param			itemId	path	int	true	"Vault Item id"
+func HandleVaultItemAttachmentsUpload(apiConfig *config.RestapiConfig, blobStore blobstore.BlobStore, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type AttachmentUploadResponse struct {
+		Id   uint  `json:"id" binding:"required"`
+		Size int64 `json:"size" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageItems, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageItems)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageItems {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		encryptionIV := c.GetHeader("X-Encryption-IV")
+		encryptedMetadata := c.GetHeader("X-Encrypted-Metadata")
+		if encryptionIV == "" || encryptedMetadata == "" {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+				Error: "X-Encryption-IV and X-Encrypted-Metadata headers are required."})
+			return
+		}
+
+		// reject too large uploads before reading the body if the client tells the size
+		if c.Request.ContentLength > apiConfig.AttachmentMaxSizeBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, schemas.PayloadTooLargeResponse{
+				Error: "Attachment is larger than the maximum attachment size."})
+			return
+		}
+
+		var vaultItem models.VaultItem
+		err = db.First(&vaultItem, "id = ? AND vault_id = ?", vaultItemId, vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		attachment, err := vaultservice.UploadAttachment(db, blobStore, vaultItem, user, encryptionIV,
+			encryptedMetadata, c.Request.Body, apiConfig.AttachmentMaxSizeBytes, apiConfig.VaultAttachmentQuotaBytes)
+		if err != nil {
+			switch {
+			case errors.As(err, &vaultservice.AttachmentTooLargeErr{}):
+				c.JSON(http.StatusRequestEntityTooLarge, schemas.PayloadTooLargeResponse{
+					Error: "Attachment is larger than the maximum attachment size."})
+			case errors.As(err, &vaultservice.AttachmentQuotaExceededErr{}):
+				c.JSON(http.StatusRequestEntityTooLarge, schemas.PayloadTooLargeResponse{
+					Error: "Attachment exceeds the attachment quota of the vault."})
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault doesn't exist."})
+			case errors.Is(err, vaultservice.VaultItemNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item doesn't exist."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Uploading vault item attachment failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		c.JSON(http.StatusCreated, AttachmentUploadResponse{Id: attachment.ID, Size: attachment.Size})
+	}
+}
+
+// HandleVaultItemAttachmentsList
+//
+//	@Summary	List attachments of a vault item
+//	@Tags		vault items
+//	@Id			listVaultItemAttachments
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleVaultItemAttachmentsList.AttachmentResponseItem
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	500
+//	@Router		/vaults/{id}/items/{itemId}/attachments [get]
+//	@Param		id		path	int	true	"Vault id"
+//	@Param		itemId	path	int	true	"Vault Item id"
+func HandleVaultItemAttachmentsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type AttachmentUploaderData struct {
+		Id    uint   `json:"id" binding:"required"`
+		Email string `json:"email" binding:"required"`
+	}
+
+	type AttachmentResponseItem struct {
+		Id                uint                   `json:"id" binding:"required"`
+		Size              int64                  `json:"size" binding:"required"`
+		EncryptionIV      string                 `json:"encryption_iv" binding:"required"`
+		EncryptedMetadata string                 `json:"encrypted_metadata" binding:"required"`
+		CreatedAt         time.Time              `json:"created_at" binding:"required"`
+		Uploader          AttachmentUploaderData `json:"uploader" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionRead)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRead {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var attachments []models.VaultItemAttachment
+		err = db.Preload("UploaderUser").Where("vault_id = ? AND vault_item_id = ?", vaultId, vaultItemId).
+			Order("created_at ASC").Find(&attachments).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault item attachments failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []AttachmentResponseItem{}
+		for _, attachment := range attachments {
+			results = append(results, AttachmentResponseItem{
+				Id:                attachment.ID,
+				Size:              attachment.Size,
+				EncryptionIV:      attachment.EncryptionIV,
+				EncryptedMetadata: attachment.EncryptedMetadata,
+				CreatedAt:         attachment.CreatedAt,
+				Uploader: AttachmentUploaderData{
+					Id:    attachment.UploaderUser.ID,
+					Email: attachment.UploaderUser.Email,
+				},
+			})
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// HandleVaultItemAttachmentsDownload
+//
+//	@Summary		Download an attachment of a vault item
+//	@Description	Response body is the encrypted file content as it was uploaded.
+//	@Tags			vault items
+//	@Id				downloadVaultItemAttachment
+//	@Produce		octet-stream
+//	@Success		200	{file}		binary
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/items/{itemId}/attachments/{attachmentId} [get]
+//	@Param			id				path	int	true	"Vault id"
+//	@Param			itemId			path	int	true	"Vault Item id"
+//	@Param			attachmentId	path	int	true	"Attachment id"
+func HandleVaultItemAttachmentsDownload(blobStore blobstore.BlobStore, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		attachmentId, err := strconv.Atoi(c.Param("attachmentId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionRead)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRead {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var attachment models.VaultItemAttachment
+		err = db.First(&attachment, "id = ? AND vault_id = ? AND vault_item_id = ?", attachmentId, vaultId,
+			vaultItemId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Attachment doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item attachment from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		content, err := blobStore.Open(attachment.BlobKey)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Opening vault item attachment content failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		defer content.Close()
+
+		c.DataFromReader(http.StatusOK, attachment.Size, "application/octet-stream", content,
+			map[string]string{"Cache-Control": "no-store"})
+	}
+}
+
+// HandleVaultItemAttachmentsDelete
+//
+//	@Summary		Delete an attachment of a vault item
+//	@Description	The encrypted file content is removed from the storage shortly after.
+//	@Tags			vault items
+//	@Id				deleteVaultItemAttachment
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/items/{itemId}/attachments/{attachmentId} [delete]
+//	@Param			id				path	int	true	"Vault id"
+//	@Param			itemId			path	int	true	"Vault Item id"
+//	@Param			attachmentId	path	int	true	"Attachment id"
+func HandleVaultItemAttachmentsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		attachmentId, err := strconv.Atoi(c.Param("attachmentId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageItems, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageItems)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageItems {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var vaultItem models.VaultItem
+		err = db.First(&vaultItem, "id = ? AND vault_id = ?", vaultItemId, vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = vaultservice.DeleteAttachment(db, vaultItem, user, uint(attachmentId))
+		if err != nil {
+			if errors.Is(err, vaultservice.AttachmentNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Attachment doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting vault item attachment failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -19,37 +19,86 @@ import (
 	"gorm.io/gorm"
 )
 
+// vaultItemData is the encrypted payload which holds the fields of a vault item, see vaultservice.VaultItemDataV1 for
+// its format.
+type vaultItemData struct {
+	Version       int    `json:"version" binding:"required"`
+	EncryptionIV  string `json:"encryption_iv" binding:"required"`
+	EncryptedData string `json:"encrypted_data" binding:"required"`
+}
+
+// newVaultItemData returns the data of a vault item or a revision from its columns, or nil if it only has the legacy
+// payload.
+func newVaultItemData(version int, encryptionIV, encryptedData string) *vaultItemData {
+	if version == 0 {
+		return nil
+	}
+	return &vaultItemData{Version: version, EncryptionIV: encryptionIV, EncryptedData: encryptedData}
+}
+
+func (d *vaultItemData) toVaultItemData() *vaultservice.VaultItemData {
+	if d == nil {
+		return nil
+	}
+	return &vaultservice.VaultItemData{
+		Version:       d.Version,
+		EncryptionIV:  d.EncryptionIV,
+		EncryptedData: d.EncryptedData,
+	}
+}
+
+// validateVaultItem responds with HTTP 400 and returns false if the type or the data of a vault item is invalid.
+func validateVaultItem(c *gin.Context, itemType string, data *vaultItemData) bool {
+	err := vaultservice.ValidateVaultItem(itemType, data.toVaultItemData())
+	if err == nil {
+		return true
+	}
+	if errors.As(err, &vaultservice.InvalidVaultItemTypeErr{}) {
+		c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Vault item type is invalid."})
+	} else {
+		c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Vault item data version is not supported."})
+	}
+	return false
+}
+
 // HandleVaultItemsCreate
 //
-//	@Summary	Create a new vault item
-//	@Tags		vault items
-//	@Id			createVaultItem
-//	@Param		request	body	controllers.HandleVaultItemsCreate.VaultItemCreateRequest	true	"New vault item data"
-//	@Produce	json
-//	@Success	201	{object}	controllers.HandleVaultItemsCreate.VaultItemCreateResponse
-//	@Failure	400	{object}	schemas.BadRequestResponse
-//	@Failure	401
-//	@Failure	403
-//	@Failure	422	{object}	bodybinder.validationErrorResponse
-//	@Failure	500
-//	@Router		/vaults/{id}/items [post]
-//	@Param		id	path	int	true	"Vault id"
+//	@Summary		Create a new vault item
+//	@Description	type is one of login, secure_note, credit_card, api_key and ssh_key, it is login if it isn't given.
+//	@Description	data holds the fields of the item, encrypted_username, encrypted_password and encrypted_note are
+//	@Description	kept for older clients.
+//	@Tags			vault items
+//	@Id				createVaultItem
+//	@Param			request	body	controllers.HandleVaultItemsCreate.VaultItemCreateRequest	true	"New vault item data"
+//	@Produce		json
+//	@Success		201	{object}	controllers.HandleVaultItemsCreate.VaultItemCreateResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/items [post]
+//	@Param			id	path	int	true	"Vault id"
 func HandleVaultItemsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type VaultItemCreateRequest struct {
-		Title             string `json:"title" binding:"required"`
-		EncryptionIV      string `json:"encryption_iv" binding:"required"`
-		EncryptedUsername string `json:"encrypted_username"`
-		EncryptedPassword string `json:"encrypted_password"`
-		EncryptedNote     string `json:"encrypted_note"`
+		Type              string         `json:"type"`
+		Title             string         `json:"title" binding:"required"`
+		EncryptionIV      string         `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string         `json:"encrypted_username"`
+		EncryptedPassword string         `json:"encrypted_password"`
+		EncryptedNote     string         `json:"encrypted_note"`
+		Data              *vaultItemData `json:"data"`
 	}
 
 	type VaultItemCreateResponse struct {
-		Id                uint   `json:"id" binding:"required"`
-		Title             string `json:"title" binding:"required"`
-		EncryptionIV      string `json:"encryption_iv" binding:"required"`
-		EncryptedUsername string `json:"encrypted_username" binding:"required"`
-		EncryptedPassword string `json:"encrypted_password" binding:"required"`
-		EncryptedNote     string `json:"encrypted_note" binding:"required"`
+		Id                uint           `json:"id" binding:"required"`
+		Type              string         `json:"type" binding:"required"`
+		Title             string         `json:"title" binding:"required"`
+		EncryptionIV      string         `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string         `json:"encrypted_username" binding:"required"`
+		EncryptedPassword string         `json:"encrypted_password" binding:"required"`
+		EncryptedNote     string         `json:"encrypted_note" binding:"required"`
+		Data              *vaultItemData `json:"data"`
 	}
 
 	return func(c *gin.Context) {
@@ -82,14 +131,23 @@ func HandleVaultItemsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 			return
 		}
 
+		if requestData.Type == "" {
+			requestData.Type = models.VaultItemTypeLogin
+		}
+		if !validateVaultItem(c, requestData.Type, requestData.Data) {
+			return
+		}
+
 		vaultItem := models.VaultItem{
 			VaultID:           uint(vaultId),
+			Type:              requestData.Type,
 			Title:             requestData.Title,
 			EncryptionIV:      requestData.EncryptionIV,
 			EncryptedUsername: requestData.EncryptedUsername,
 			EncryptedPassword: requestData.EncryptedPassword,
 			EncryptedNote:     requestData.EncryptedNote,
 		}
+		vaultservice.SetVaultItemData(&vaultItem, requestData.Data.toVaultItemData())
 		err = db.Create(&vaultItem).Error
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating vault item failed.")
@@ -110,11 +168,13 @@ func HandleVaultItemsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 
 		c.JSON(http.StatusCreated, VaultItemCreateResponse{
 			Id:                vaultItem.ID,
+			Type:              vaultItem.Type,
 			Title:             vaultItem.Title,
 			EncryptionIV:      vaultItem.EncryptionIV,
 			EncryptedUsername: vaultItem.EncryptedUsername,
 			EncryptedPassword: vaultItem.EncryptedPassword,
 			EncryptedNote:     vaultItem.EncryptedNote,
+			Data:              newVaultItemData(vaultItem.DataVersion, vaultItem.DataEncryptionIV, vaultItem.EncryptedData),
 		})
 	}
 }
@@ -128,6 +188,7 @@ func HandleVaultItemsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 //	@Param		page_size	query	int		false	"Item count per page"	default(10)
 //	@Param		ordering	query	string	false	"Ordering"				Enums(title, -title, created_at, -created_at)
 //	@Param		title		query	string	false	"Search by title"
+//	@Param		type		query	string	false	"Filter by type"
 //	@Produce	json
 //	@Success	200	{object}	pagination.StandardPaginationResponse[controllers.HandleVaultItemsList.VaultItemResponseItem]
 //	@Failure	400	{object}	schemas.BadRequestResponse
@@ -139,6 +200,7 @@ func HandleVaultItemsCreate(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 func HandleVaultItemsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type VaultItemResponseItem struct {
 		Id    uint   `json:"id" binding:"required"`
+		Type  string `json:"type" binding:"required"`
 		Title string `json:"title" binding:"required"`
 	}
 
@@ -178,6 +240,7 @@ func HandleVaultItemsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Conte
 		}
 
 		titleSearchParam := c.Query("title")
+		typeParam := c.Query("type")
 
 		var count int64
 		countStmt := db.Select("count(*)").Table("vault_items").
@@ -185,6 +248,9 @@ func HandleVaultItemsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Conte
 		if titleSearchParam != "" {
 			countStmt = countStmt.Where("title ILIKE ?", "%"+titleSearchParam+"%")
 		}
+		if typeParam != "" {
+			countStmt = countStmt.Where("type = ?", typeParam)
+		}
 		err = countStmt.Scan(&count).Error
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault items count failed.")
@@ -193,11 +259,14 @@ func HandleVaultItemsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Conte
 		}
 
 		results := []VaultItemResponseItem{}
-		queryStmt := db.Scopes(pagination.Paginate(c)).Select("id, title").Table("vault_items").
+		queryStmt := db.Scopes(pagination.Paginate(c)).Select("id, type, title").Table("vault_items").
 			Where("deleted_at IS NULL AND vault_id = ?", vaultId).Order(ordering)
 		if titleSearchParam != "" {
 			queryStmt = queryStmt.Where("title ILIKE ?", "%"+titleSearchParam+"%")
 		}
+		if typeParam != "" {
+			queryStmt = queryStmt.Where("type = ?", typeParam)
+		}
 		err = queryStmt.
 			Scan(&results).Error
 		if err != nil {
@@ -230,13 +299,15 @@ func HandleVaultItemsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Conte
 //	@Param		itemId	path	int	true	"Vault Item id"
 func HandleVaultItemsRetrieve(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type VaultItemRetrieveResponse struct {
-		Id                uint      `json:"id" binding:"required"`
-		Title             string    `json:"title" binding:"required"`
-		EncryptionIV      string    `json:"encryption_iv" binding:"required"`
-		EncryptedUsername string    `json:"encrypted_username" binding:"required"`
-		EncryptedPassword string    `json:"encrypted_password" binding:"required"`
-		EncryptedNote     string    `json:"encrypted_note" binding:"required"`
-		UpdatedAt         time.Time `json:"updated_at" binding:"required"`
+		Id                uint           `json:"id" binding:"required"`
+		Type              string         `json:"type" binding:"required"`
+		Title             string         `json:"title" binding:"required"`
+		EncryptionIV      string         `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string         `json:"encrypted_username" binding:"required"`
+		EncryptedPassword string         `json:"encrypted_password" binding:"required"`
+		EncryptedNote     string         `json:"encrypted_note" binding:"required"`
+		Data              *vaultItemData `json:"data"`
+		UpdatedAt         time.Time      `json:"updated_at" binding:"required"`
 	}
 
 	return func(c *gin.Context) {
@@ -284,11 +355,13 @@ func HandleVaultItemsRetrieve(logger *logging.Logger, db *gorm.DB) func(c *gin.C
 
 		c.JSON(http.StatusOK, VaultItemRetrieveResponse{
 			Id:                vaultItem.ID,
+			Type:              vaultItem.Type,
 			Title:             vaultItem.Title,
 			EncryptionIV:      vaultItem.EncryptionIV,
 			EncryptedUsername: vaultItem.EncryptedUsername,
 			EncryptedPassword: vaultItem.EncryptedPassword,
 			EncryptedNote:     vaultItem.EncryptedNote,
+			Data:              newVaultItemData(vaultItem.DataVersion, vaultItem.DataEncryptionIV, vaultItem.EncryptedData),
 			UpdatedAt:         vaultItem.UpdatedAt,
 		})
 	}
@@ -296,35 +369,46 @@ func HandleVaultItemsRetrieve(logger *logging.Logger, db *gorm.DB) func(c *gin.C
 
 // HandleVaultItemsUpdate
 //
-//	@Summary	Update a new vault item
-//	@Tags		vault items
-//	@Id			updateVaultItem
-//	@Param		request	body	controllers.HandleVaultItemsUpdate.VaultItemUpdateRequest	true	"New vault item data"
-//	@Produce	json
-//	@Success	200	{object}	controllers.HandleVaultItemsUpdate.VaultItemUpdateResponse
-//	@Failure	400	{object}	schemas.BadRequestResponse
-//	@Failure	401
-//	@Failure	403
-//	@Failure	404	{object}	schemas.NotFoundResponse
-//	@Failure	422	{object}	bodybinder.validationErrorResponse
-//	@Failure	500
-//	@Router		/vaults/{id}/items/{itemId} [put]
-//	@Param		id		path	int	true	"Vault id"
-//	@Param		itemId	path	int	true	"Vault Item id"
+//	@Summary		Update a new vault item
+//	@Description	type of the item is kept if it isn't given. data must be given if the item already has data, so
+//	@Description	older clients which only update the legacy fields can't leave it stale. encryption_iv and
+//	@Description	data.encryption_iv must be new IVs, since the previous payload is kept as a revision which is
+//	@Description	encrypted with the same vault key.
+//	@Tags			vault items
+//	@Id				updateVaultItem
+//	@Param			request	body	controllers.HandleVaultItemsUpdate.VaultItemUpdateRequest	true	"New vault item data"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleVaultItemsUpdate.VaultItemUpdateResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/items/{itemId} [put]
+//	@Param			id		path	int	true	"Vault id"
+//	@Param			itemId	path	int	true	"Vault Item id"
 func HandleVaultItemsUpdate(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	type VaultItemUpdateRequest struct {
-		Title             string `json:"title" binding:"required"`
-		EncryptedUsername string `json:"encrypted_username"`
-		EncryptedPassword string `json:"encrypted_password"`
-		EncryptedNote     string `json:"encrypted_note"`
+		Type              string         `json:"type"`
+		Title             string         `json:"title" binding:"required"`
+		EncryptionIV      string         `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string         `json:"encrypted_username"`
+		EncryptedPassword string         `json:"encrypted_password"`
+		EncryptedNote     string         `json:"encrypted_note"`
+		Data              *vaultItemData `json:"data"`
 	}
 
 	type VaultItemUpdateResponse struct {
-		Id                uint   `json:"id" binding:"required"`
-		Title             string `json:"title" binding:"required"`
-		EncryptedUsername string `json:"encrypted_username" binding:"required"`
-		EncryptedPassword string `json:"encrypted_password" binding:"required"`
-		EncryptedNote     string `json:"encrypted_note" binding:"required"`
+		Id                uint           `json:"id" binding:"required"`
+		Type              string         `json:"type" binding:"required"`
+		Title             string         `json:"title" binding:"required"`
+		EncryptionIV      string         `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string         `json:"encrypted_username" binding:"required"`
+		EncryptedPassword string         `json:"encrypted_password" binding:"required"`
+		EncryptedNote     string         `json:"encrypted_note" binding:"required"`
+		Data              *vaultItemData `json:"data"`
 	}
 
 	return func(c *gin.Context) {
@@ -379,11 +463,42 @@ func HandleVaultItemsUpdate(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 			return
 		}
 
-		vaultItem.Title = requestData.Title
-		vaultItem.EncryptedUsername = requestData.EncryptedUsername
-		vaultItem.EncryptedPassword = requestData.EncryptedPassword
-		vaultItem.EncryptedNote = requestData.EncryptedNote
-		err = db.Save(&vaultItem).Error
+		if requestData.Data == nil && vaultItem.DataVersion != 0 {
+			c.JSON(http.StatusConflict, schemas.ConflictResponse{
+				Error: "Vault item has data, it must be updated with data."})
+			return
+		}
+		// AES-GCM leaks the plaintexts and the authentication key if an IV is used for two payloads with the same key
+		if requestData.EncryptionIV == vaultItem.EncryptionIV ||
+			requestData.Data != nil && requestData.Data.EncryptionIV == vaultItem.DataEncryptionIV {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{
+				Error: "Encryption IVs must be different from the current ones."})
+			return
+		}
+		if requestData.Type == "" {
+			requestData.Type = vaultItem.Type
+		}
+		if !validateVaultItem(c, requestData.Type, requestData.Data) {
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// keep the previous payload so that the update can be reverted later
+			if _, err := vaultservice.CreateVaultItemRevision(tx, vaultItem, user.ID); err != nil {
+				return err
+			}
+
+			vaultItem.Type = requestData.Type
+			vaultItem.Title = requestData.Title
+			vaultItem.EncryptionIV = requestData.EncryptionIV
+			vaultItem.EncryptedUsername = requestData.EncryptedUsername
+			vaultItem.EncryptedPassword = requestData.EncryptedPassword
+			vaultItem.EncryptedNote = requestData.EncryptedNote
+			if requestData.Data != nil {
+				vaultservice.SetVaultItemData(&vaultItem, requestData.Data.toVaultItemData())
+			}
+			return tx.Save(&vaultItem).Error
+		})
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Updating vault item failed.")
 			c.Status(http.StatusInternalServerError)
@@ -403,10 +518,13 @@ func HandleVaultItemsUpdate(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 
 		c.JSON(http.StatusOK, VaultItemUpdateResponse{
 			Id:                vaultItem.ID,
+			Type:              vaultItem.Type,
 			Title:             vaultItem.Title,
+			EncryptionIV:      vaultItem.EncryptionIV,
 			EncryptedUsername: vaultItem.EncryptedUsername,
 			EncryptedPassword: vaultItem.EncryptedPassword,
 			EncryptedNote:     vaultItem.EncryptedNote,
+			Data:              newVaultItemData(vaultItem.DataVersion, vaultItem.DataEncryptionIV, vaultItem.EncryptedData),
 		})
 	}
 }
@@ -470,7 +588,17 @@ func HandleVaultItemsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 			return
 		}
 
-		err = db.Delete(&vaultItem).Error
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&vaultItem).Error; err != nil {
+				return err
+			}
+			// attachments go to the trash with the item, their blobs are deleted once the item is purged
+			if err := tx.Where("vault_item_id = ?", vaultItem.ID).Delete(&models.VaultItemAttachment{}).Error; err != nil {
+				return err
+			}
+			// share links of a deleted item shouldn't keep working
+			return tx.Unscoped().Where("vault_item_id = ?", vaultItem.ID).Delete(&models.SecretShare{}).Error
+		})
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting vault item failed.")
 			c.Status(http.StatusInternalServerError)
@@ -491,3 +619,264 @@ func HandleVaultItemsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Con
 		c.Status(http.StatusNoContent)
 	}
 }
+
+// HandleVaultItemRevisionsList
+//
+//	@Summary	List previous revisions of a vault item
+//	@Tags		vault items
+//	@Id			listVaultItemRevisions
+//	@Param		page		query	int	false	"Page number"			default(1)	minimum(1)
+//	@Param		page_size	query	int	false	"Item count per page"	default(10)
+//	@Produce	json
+//	@Success	200	{object}	pagination.StandardPaginationResponse[controllers.HandleVaultItemRevisionsList.RevisionResponseItem]
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/vaults/{id}/items/{itemId}/revisions [get]
+//	@Param		id		path	int	true	"Vault id"
+//	@Param		itemId	path	int	true	"Vault Item id"
+func HandleVaultItemRevisionsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RevisionUserData struct {
+		Id    uint   `json:"id" binding:"required"`
+		Email string `json:"email" binding:"required"`
+	}
+
+	type RevisionResponseItem struct {
+		Id                uint             `json:"id" binding:"required"`
+		Type              string           `json:"type" binding:"required"`
+		Title             string           `json:"title" binding:"required"`
+		EncryptionIV      string           `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string           `json:"encrypted_username" binding:"required"`
+		EncryptedPassword string           `json:"encrypted_password" binding:"required"`
+		EncryptedNote     string           `json:"encrypted_note" binding:"required"`
+		Data              *vaultItemData   `json:"data"`
+		CreatedAt         time.Time        `json:"created_at" binding:"required"`
+		ReplacedBy        RevisionUserData `json:"replaced_by" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionRead)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRead {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var vaultItem models.VaultItem
+		err = db.First(&vaultItem, "id = ? AND vault_id = ?", vaultItemId, vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var count int64
+		err = db.Model(&models.VaultItemRevision{}).Where("vault_item_id = ?", vaultItem.ID).Count(&count).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault item revision count failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		revisions := []models.VaultItemRevision{}
+		err = db.Unscoped().Scopes(pagination.Paginate(c)).Joins("User").
+			Order("vault_item_revisions.created_at DESC").
+			Find(&revisions, "vault_item_revisions.vault_item_id = ? AND vault_item_revisions.deleted_at IS NULL", vaultItem.ID).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying vault item revisions failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := make([]RevisionResponseItem, len(revisions))
+		for i, v := range revisions {
+			results[i] = RevisionResponseItem{
+				Id:                v.ID,
+				Type:              v.Type,
+				Title:             v.Title,
+				EncryptionIV:      v.EncryptionIV,
+				EncryptedUsername: v.EncryptedUsername,
+				EncryptedPassword: v.EncryptedPassword,
+				EncryptedNote:     v.EncryptedNote,
+				Data:              newVaultItemData(v.DataVersion, v.DataEncryptionIV, v.EncryptedData),
+				CreatedAt:         v.CreatedAt,
+				ReplacedBy: RevisionUserData{
+					Id:    v.User.ID,
+					Email: v.User.Email,
+				},
+			}
+		}
+
+		c.JSON(http.StatusOK, pagination.StandardPaginationResponse[RevisionResponseItem]{
+			Results: results,
+			Count:   int(count),
+		})
+	}
+}
+
+// HandleVaultItemRevisionsRestore
+//
+//	@Summary	Restore a vault item to one of its previous revisions
+//	@Tags		vault items
+//	@Id			restoreVaultItemRevision
+//	@Produce	json
+//	@Success	200	{object}	controllers.HandleVaultItemRevisionsRestore.VaultItemRestoreResponse
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/vaults/{id}/items/{itemId}/revisions/{revId}/restore [post]
+//	@Param		id		path	int	true	"Vault id"
+//	@Param		itemId	path	int	true	"Vault Item id"
+//	@Param		revId	path	int	true	"Vault Item Revision id"
+func HandleVaultItemRevisionsRestore(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type VaultItemRestoreResponse struct {
+		Id                uint           `json:"id" binding:"required"`
+		Type              string         `json:"type" binding:"required"`
+		Title             string         `json:"title" binding:"required"`
+		EncryptionIV      string         `json:"encryption_iv" binding:"required"`
+		EncryptedUsername string         `json:"encrypted_username" binding:"required"`
+		EncryptedPassword string         `json:"encrypted_password" binding:"required"`
+		EncryptedNote     string         `json:"encrypted_note" binding:"required"`
+		Data              *vaultItemData `json:"data"`
+		UpdatedAt         time.Time      `json:"updated_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		revisionId, err := strconv.Atoi(c.Param("revId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageItems, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageItems)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageItems {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var vaultItem models.VaultItem
+		err = db.First(&vaultItem, "id = ? AND vault_id = ?", vaultItemId, vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var revision models.VaultItemRevision
+		err = db.First(&revision, "id = ? AND vault_item_id = ?", revisionId, vaultItem.ID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault item revision doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting vault item revision from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// the current payload becomes a revision too, so restoring can be undone as well
+			if _, err := vaultservice.CreateVaultItemRevision(tx, vaultItem, user.ID); err != nil {
+				return err
+			}
+
+			vaultItem.Type = revision.Type
+			vaultItem.Title = revision.Title
+			vaultItem.EncryptionIV = revision.EncryptionIV
+			vaultItem.EncryptedUsername = revision.EncryptedUsername
+			vaultItem.EncryptedPassword = revision.EncryptedPassword
+			vaultItem.EncryptedNote = revision.EncryptedNote
+			vaultItem.DataVersion = revision.DataVersion
+			vaultItem.DataEncryptionIV = revision.DataEncryptionIV
+			vaultItem.EncryptedData = revision.EncryptedData
+			if err := tx.Save(&vaultItem).Error; err != nil {
+				return err
+			}
+
+			auditLog := models.VaultAuditLog{
+				VaultID:     uint(vaultId),
+				VaultItemID: vaultItem.ID,
+				UserID:      user.ID,
+				ActionCode:  models.AuditLogActionVaultItemRestoreRevision,
+				ActionData:  models.AuditLogDataVaultItemRestoreRevision(vaultItem.Title, revision.ID),
+			}
+			return tx.Create(&auditLog).Error
+		})
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Restoring vault item revision failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, VaultItemRestoreResponse{
+			Id:                vaultItem.ID,
+			Type:              vaultItem.Type,
+			Title:             vaultItem.Title,
+			EncryptionIV:      vaultItem.EncryptionIV,
+			EncryptedUsername: vaultItem.EncryptedUsername,
+			EncryptedPassword: vaultItem.EncryptedPassword,
+			EncryptedNote:     vaultItem.EncryptedNote,
+			Data:              newVaultItemData(vaultItem.DataVersion, vaultItem.DataEncryptionIV, vaultItem.EncryptedData),
+			UpdatedAt:         vaultItem.UpdatedAt,
+		})
+	}
+}
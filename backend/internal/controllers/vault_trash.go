@@ -0,0 +1,311 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/pagination"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleVaultsListTrash
+//
+//	@Summary	List deleted vaults that user can restore
+//	@Tags		vault trash
+//	@Id			listDeletedVaults
+//	@Produce	json
+//	@Param		page		query		int	false	"Page number"			default(1)	minimum(1)
+//	@Param		page_size	query		int	false	"Item count per page"	default(10)
+//	@Success	200			{object}	pagination.StandardPaginationResponse[controllers.HandleVaultsListTrash.DeletedVaultResponseItem]
+//	@Failure	401
+//	@Failure	500
+//	@Router		/vaults/trash [get]
+func HandleVaultsListTrash(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type DeletedVaultResponseItem struct {
+		Id        uint      `json:"id" binding:"required"`
+		Name      string    `json:"name" binding:"required"`
+		DeletedAt time.Time `json:"deleted_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		// Permissions of a deleted vault are deleted together with the vault. Only the users who had the
+		// delete permission at the time the vault was deleted can see and restore it.
+		deletedVaultsOfUser := func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Model(&models.Vault{}).
+				Joins("INNER JOIN vault_permissions ON vault_permissions.vault_id = vaults.id").
+				Where("vaults.deleted_at IS NOT NULL AND vault_permissions.deleted_at >= vaults.deleted_at").
+				Where("vault_permissions.user_id = ? AND vault_permissions.permission = ?",
+					user.ID, models.VaultPermissionDeleteVault)
+		}
+
+		var count int64
+		err := db.Scopes(deletedVaultsOfUser).Count(&count).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying deleted vaults count failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []DeletedVaultResponseItem{}
+		err = db.Scopes(deletedVaultsOfUser, pagination.Paginate(c)).
+			Select("vaults.id, vaults.name, vaults.deleted_at").
+			Order("vaults.deleted_at DESC").Scan(&results).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying deleted vaults failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, pagination.StandardPaginationResponse[DeletedVaultResponseItem]{
+			Results: results,
+			Count:   int(count),
+		})
+	}
+}
+
+// HandleVaultsRestore
+//
+//	@Summary	Restore a deleted vault
+//	@Tags		vault trash
+//	@Id			restoreVault
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	500
+//	@Router		/vaults/{id}/restore [post]
+//	@Param		id	path	int	true	"Vault id"
+func HandleVaultsRestore(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var vault models.Vault
+		err = db.Unscoped().First(&vault, "id = ? AND deleted_at IS NOT NULL", vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Deleted vault doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying deleted vault failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var canRestore bool
+		err = db.Unscoped().Model(&models.VaultPermission{}).Select("count(*) > 0").
+			Where("vault_id = ? AND user_id = ? AND permission = ? AND deleted_at >= ?",
+				vault.ID, user.ID, models.VaultPermissionDeleteVault, vault.DeletedAt.Time).
+			Scan(&canRestore).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking deleted vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRestore {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		err = vaultservice.RestoreVault(db, vault, user.ID)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Restoring vault failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// HandleVaultTrashList
+//
+//	@Summary	List deleted items of a vault
+//	@Tags		vault trash
+//	@Id			listDeletedVaultItems
+//	@Produce	json
+//	@Param		page		query		int	false	"Page number"			default(1)	minimum(1)
+//	@Param		page_size	query		int	false	"Item count per page"	default(10)
+//	@Success	200			{object}	pagination.StandardPaginationResponse[controllers.HandleVaultTrashList.DeletedVaultItemResponseItem]
+//	@Failure	400			{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	403
+//	@Failure	500
+//	@Router		/vaults/{id}/trash [get]
+//	@Param		id	path	int	true	"Vault id"
+func HandleVaultTrashList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type DeletedVaultItemResponseItem struct {
+		Id        uint      `json:"id" binding:"required"`
+		Title     string    `json:"title" binding:"required"`
+		DeletedAt time.Time `json:"deleted_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canRead, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionRead)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canRead {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var count int64
+		err = db.Unscoped().Model(&models.VaultItem{}).
+			Where("deleted_at IS NOT NULL AND vault_id = ?", vaultId).Count(&count).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying deleted vault items count failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		results := []DeletedVaultItemResponseItem{}
+		err = db.Unscoped().Scopes(pagination.Paginate(c)).Model(&models.VaultItem{}).
+			Select("id, title, deleted_at").
+			Where("deleted_at IS NOT NULL AND vault_id = ?", vaultId).
+			Order("deleted_at DESC").Scan(&results).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Querying deleted vault items failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, pagination.StandardPaginationResponse[DeletedVaultItemResponseItem]{
+			Results: results,
+			Count:   int(count),
+		})
+	}
+}
+
+// HandleVaultItemsRestore
+//
+//	@Summary		Restore a deleted vault item
+//	@Description	409 is returned if the attachments of the item don't fit in the attachment quota of the vault.
+//	@Tags			vault trash
+//	@Id				restoreVaultItem
+//	@Success		204
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404	{object}	schemas.NotFoundResponse
+//	@Failure		409	{object}	schemas.ConflictResponse
+//	@Failure		500
+//	@Router			/vaults/{id}/trash/{itemId}/restore [post]
+//	@Param			id		path	int	true	"Vault id"
+//	@Param			itemId	path	int	true	"Vault Item id"
+func HandleVaultItemsRestore(apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		vaultId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		vaultItemId, err := strconv.Atoi(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		canManageItems, err := vaultservice.CheckUserHasVaultPermission(db, int(user.ID), vaultId, models.VaultPermissionManageItems)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Checking vault permissions of user failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !canManageItems {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		var vaultItem models.VaultItem
+		err = db.Unscoped().First(&vaultItem, "id = ? AND vault_id = ? AND deleted_at IS NOT NULL", vaultItemId, vaultId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Deleted vault item doesn't exist."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Getting deleted vault item from database failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		err = vaultservice.RestoreVaultItem(db, vaultItem, apiConfig.VaultAttachmentQuotaBytes)
+		if err != nil {
+			switch {
+			case errors.As(err, &vaultservice.AttachmentQuotaExceededErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{
+					Error: "Attachments of the item don't fit in the attachment quota of the vault."})
+			case errors.Is(err, vaultservice.VaultNotFoundErr{}):
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Vault doesn't exist."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Restoring vault item failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     uint(vaultId),
+			VaultItemID: vaultItem.ID,
+			UserID:      user.ID,
+			ActionCode:  models.AuditLogActionVaultItemRestore,
+			ActionData:  models.AuditLogDataVaultItemRestore(vaultItem.Title),
+		}
+		if err := db.Create(&auditLog).Error; err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Saving audit log failed.")
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
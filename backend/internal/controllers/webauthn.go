@@ -0,0 +1,443 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/common/bodybinder"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/common/ratelimit"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/berk-karaal/letuspass/backend/internal/schemas"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// HandleAuthLoginWebAuthn
+//
+//	@Summary		Complete login with a passkey
+//	@Description	credential is the result of navigator.credentials.get() called with the webauthn_options returned
+//	@Description	from the login endpoint.
+//	@Tags			auth
+//	@Id				authLoginWebAuthn
+//	@Param			request	body	controllers.HandleAuthLoginWebAuthn.LoginWebAuthnRequest	true	"Login challenge token and the passkey assertion"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleAuthLoginWebAuthn.LoginResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		403	{object}	schemas.ForbiddenResponse
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/auth/login/webauthn [post]
+func HandleAuthLoginWebAuthn(apiConfig *config.RestapiConfig, webAuthn *webauthn.WebAuthn, loginLimiter *ratelimit.Limiter, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type LoginWebAuthnRequest struct {
+		TwoFactorToken string          `json:"two_factor_token" binding:"required"`
+		Credential     json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+	}
+
+	type LoginResponse struct {
+		Email             string              `json:"email" binding:"required"`
+		Name              string              `json:"name" binding:"required"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData LoginWebAuthnRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		timeNow := time.Now()
+		user, err := authservice.CompleteLoginChallengeWithWebAuthn(db, webAuthn, requestData.TwoFactorToken,
+			requestData.Credential)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.LoginChallengeNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Login session expired, login again."})
+			case errors.Is(err, authservice.InvalidWebAuthnResponseErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Passkey verification failed."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Completing login challenge failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if !checkUserIsActive(c, user) {
+			return
+		}
+
+		clearFailedLogins(c, loginLimiter, logger, db, user, timeNow)
+		if err := startUserSession(c, apiConfig, mail, logger, db, user); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating user session failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, LoginResponse{
+			Email:             user.Email,
+			Name:              user.Name,
+			KeyDerivationSalt: user.KeyDerivationSalt,
+			PrivateKey:        newPrivateKeyEnvelope(user),
+		})
+	}
+}
+
+// HandleAuthPasskeyBegin
+//
+//	@Summary		Start passwordless login with a passkey
+//	@Description	options are passed to navigator.credentials.get(), the result is sent to the passkey finish endpoint
+//	@Description	together with the token. Passkey logins which aren't finished are delayed per IP.
+//	@Tags			auth
+//	@Id				authPasskeyBegin
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleAuthPasskeyBegin.PasskeyBeginResponse
+//	@Failure		429	{object}	schemas.TooManyRequestsResponse
+//	@Failure		500
+//	@Router			/auth/passkey/begin [post]
+func HandleAuthPasskeyBegin(webAuthn *webauthn.WebAuthn, loginLimiter *ratelimit.Limiter, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type PasskeyBeginResponse struct {
+		Token   string                        `json:"token" binding:"required"`
+		Options *protocol.CredentialAssertion `json:"options" binding:"required" swaggertype:"object"`
+	}
+
+	return func(c *gin.Context) {
+		// released when the login is finished, so only the abandoned ones count
+		if !reserveRateLimit(c, loginLimiter, logger, time.Now(), "Too many passkey logins started, try again later.",
+			passkeyLoginRateLimitKey(c)) {
+			return
+		}
+
+		token, options, err := authservice.BeginPasskeyLogin(db, webAuthn)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Beginning passkey login failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, PasskeyBeginResponse{Token: token, Options: options})
+	}
+}
+
+// HandleAuthPasskeyFinish
+//
+//	@Summary	Finish passwordless login with a passkey
+//	@Tags		auth
+//	@Id			authPasskeyFinish
+//	@Param		request	body	controllers.HandleAuthPasskeyFinish.PasskeyFinishRequest	true	"Passkey login token and the passkey assertion"
+//	@Produce	json
+//	@Success	200	{object}	controllers.HandleAuthPasskeyFinish.LoginResponse
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	403	{object}	schemas.ForbiddenResponse
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/auth/passkey/finish [post]
+func HandleAuthPasskeyFinish(apiConfig *config.RestapiConfig, webAuthn *webauthn.WebAuthn, loginLimiter *ratelimit.Limiter, mail mailer.Mailer, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type PasskeyFinishRequest struct {
+		Token      string          `json:"token" binding:"required"`
+		Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+	}
+
+	type LoginResponse struct {
+		Email             string              `json:"email" binding:"required"`
+		Name              string              `json:"name" binding:"required"`
+		KeyDerivationSalt string              `json:"key_derivation_salt" binding:"required"`
+		PrivateKey        *privateKeyEnvelope `json:"private_key"`
+	}
+
+	return func(c *gin.Context) {
+		var requestData PasskeyFinishRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		user, err := authservice.CompleteLoginChallengeWithWebAuthn(db, webAuthn, requestData.Token,
+			requestData.Credential)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.LoginChallengeNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Login session expired, try again."})
+			case errors.Is(err, authservice.InvalidWebAuthnResponseErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Passkey verification failed."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Completing passkey login failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := loginLimiter.Release(time.Now(), passkeyLoginRateLimitKey(c)); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Releasing passkey login rate limit failed.")
+		}
+
+		if !checkUserIsActive(c, user) {
+			return
+		}
+
+		if err := startUserSession(c, apiConfig, mail, logger, db, user); err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Creating user session failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, LoginResponse{
+			Email:             user.Email,
+			Name:              user.Name,
+			KeyDerivationSalt: user.KeyDerivationSalt,
+			PrivateKey:        newPrivateKeyEnvelope(user),
+		})
+	}
+}
+
+// passkeyLoginRateLimitKey returns the rate limit key of the passkey logins started from the IP of the request.
+func passkeyLoginRateLimitKey(c *gin.Context) string {
+	return "passkey:ip:" + c.ClientIP()
+}
+
+// HandleUsersMeWebAuthnRegisterBegin
+//
+//	@Summary		Start registering a passkey
+//	@Description	options are passed to navigator.credentials.create(), the result is sent to the register finish
+//	@Description	endpoint.
+//	@Tags			users
+//	@Id				usersMeWebAuthnRegisterBegin
+//	@Param			request	body	controllers.HandleUsersMeWebAuthnRegisterBegin.RegisterBeginRequest	true	"Current password of the user"
+//	@Produce		json
+//	@Success		200	{object}	controllers.HandleUsersMeWebAuthnRegisterBegin.RegisterBeginResponse
+//	@Failure		400	{object}	schemas.BadRequestResponse
+//	@Failure		401
+//	@Failure		422	{object}	bodybinder.validationErrorResponse
+//	@Failure		500
+//	@Router			/users/me/webauthn/register/begin [post]
+func HandleUsersMeWebAuthnRegisterBegin(webAuthn *webauthn.WebAuthn, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RegisterBeginRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	type RegisterBeginResponse struct {
+		Options *protocol.CredentialCreation `json:"options" binding:"required" swaggertype:"object"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData RegisterBeginRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err := authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		options, err := authservice.BeginWebAuthnRegistration(db, webAuthn, user)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Beginning passkey registration failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, RegisterBeginResponse{Options: options})
+	}
+}
+
+// HandleUsersMeWebAuthnRegisterFinish
+//
+//	@Summary	Finish registering a passkey
+//	@Tags		users
+//	@Id			usersMeWebAuthnRegisterFinish
+//	@Param		request	body	controllers.HandleUsersMeWebAuthnRegisterFinish.RegisterFinishRequest	true	"Name of the passkey and the result of the authenticator"
+//	@Produce	json
+//	@Success	201	{object}	controllers.HandleUsersMeWebAuthnRegisterFinish.CredentialResponse
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	409	{object}	schemas.ConflictResponse
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/users/me/webauthn/register/finish [post]
+func HandleUsersMeWebAuthnRegisterFinish(webAuthn *webauthn.WebAuthn, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type RegisterFinishRequest struct {
+		Name       string          `json:"name" binding:"required,max=100"`
+		Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+	}
+
+	type CredentialResponse struct {
+		Id        uint      `json:"id" binding:"required"`
+		Name      string    `json:"name" binding:"required"`
+		CreatedAt time.Time `json:"created_at" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData RegisterFinishRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		credential, err := authservice.FinishWebAuthnRegistration(db, webAuthn, user, requestData.Name,
+			requestData.Credential)
+		if err != nil {
+			switch {
+			case errors.Is(err, authservice.WebAuthnRegistrationNotFoundErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Passkey registration expired, try again."})
+			case errors.Is(err, authservice.InvalidWebAuthnResponseErr{}):
+				c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Passkey verification failed."})
+			case errors.Is(err, authservice.WebAuthnCredentialAlreadyExistsErr{}):
+				c.JSON(http.StatusConflict, schemas.ConflictResponse{Error: "Passkey is already registered."})
+			default:
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Finishing passkey registration failed.")
+				c.Status(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Uint("credential_id", credential.ID).
+			Msg("Passkey registered.")
+
+		c.JSON(http.StatusCreated, CredentialResponse{
+			Id:        credential.ID,
+			Name:      credential.Name,
+			CreatedAt: credential.CreatedAt,
+		})
+	}
+}
+
+// HandleUsersMeWebAuthnCredentialsList
+//
+//	@Summary	List passkeys of the user
+//	@Tags		users
+//	@Id			usersMeWebAuthnCredentialsList
+//	@Produce	json
+//	@Success	200	{object}	[]controllers.HandleUsersMeWebAuthnCredentialsList.CredentialResponse
+//	@Failure	401
+//	@Failure	500
+//	@Router		/users/me/webauthn/credentials [get]
+func HandleUsersMeWebAuthnCredentialsList(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type CredentialResponse struct {
+		Id         uint       `json:"id" binding:"required"`
+		Name       string     `json:"name" binding:"required"`
+		CreatedAt  time.Time  `json:"created_at" binding:"required"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+	}
+
+	return func(c *gin.Context) {
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var credentials []models.WebAuthnCredential
+		err := db.Where("user_id = ?", user.ID).Order("id ASC").Find(&credentials).Error
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Listing passkeys failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, common.Map(credentials, func(credential models.WebAuthnCredential) CredentialResponse {
+			return CredentialResponse{
+				Id:         credential.ID,
+				Name:       credential.Name,
+				CreatedAt:  credential.CreatedAt,
+				LastUsedAt: credential.LastUsedAt,
+			}
+		}))
+	}
+}
+
+// HandleUsersMeWebAuthnCredentialsDelete
+//
+//	@Summary	Delete a passkey of the user
+//	@Tags		users
+//	@Id			usersMeWebAuthnCredentialsDelete
+//	@Param		id		path	int																	true	"Passkey id"
+//	@Param		request	body	controllers.HandleUsersMeWebAuthnCredentialsDelete.DeleteRequest	true	"Current password of the user"
+//	@Success	204
+//	@Failure	400	{object}	schemas.BadRequestResponse
+//	@Failure	401
+//	@Failure	404	{object}	schemas.NotFoundResponse
+//	@Failure	422	{object}	bodybinder.validationErrorResponse
+//	@Failure	500
+//	@Router		/users/me/webauthn/credentials/{id} [delete]
+func HandleUsersMeWebAuthnCredentialsDelete(logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
+	type DeleteRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		credentialId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Id must be an integer."})
+			return
+		}
+
+		user, ok := middlewares.ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		var requestData DeleteRequest
+		if !bodybinder.Bind(&requestData, c) {
+			return
+		}
+
+		ok, err = authservice.ComparePassword(user.Password, requestData.Password)
+		if err != nil {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Comparing password failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, schemas.BadRequestResponse{Error: "Wrong password."})
+			return
+		}
+
+		err = authservice.DeleteWebAuthnCredential(db, user, uint(credentialId))
+		if err != nil {
+			if errors.Is(err, authservice.WebAuthnCredentialNotFoundErr{}) {
+				c.JSON(http.StatusNotFound, schemas.NotFoundResponse{Error: "Passkey not found."})
+				return
+			}
+			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Deleting passkey failed.")
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		logger.RequestEvent(zerolog.InfoLevel, c).Uint("user_id", user.ID).Uint("credential_id", uint(credentialId)).
+			Msg("Passkey deleted.")
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const accessExpirySweepInterval = time.Minute
+
+// StartAccessExpirySweep starts a goroutine which periodically revokes the vault accesses whose expiry time has
+// passed.
+func StartAccessExpirySweep(logger *logging.Logger, db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(accessExpirySweepInterval)
+		defer ticker.Stop()
+
+		for {
+			revokedCount, err := vaultservice.RevokeExpiredVaultAccess(db, time.Now())
+			if err != nil {
+				logger.NewEvent(zerolog.ErrorLevel).Err(err).Msg("Revoking expired vault accesses failed.")
+			} else if revokedCount > 0 {
+				logger.NewEvent(zerolog.InfoLevel).Int64("revoked_count", revokedCount).
+					Msg("Revoked expired vault accesses.")
+			}
+
+			<-ticker.C
+		}
+	}()
+}
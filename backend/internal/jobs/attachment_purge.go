@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/blobstore"
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const attachmentPurgeInterval = 10 * time.Minute
+
+// StartAttachmentPurge starts a goroutine which periodically deletes the blobs of the deleted vault item attachments.
+func StartAttachmentPurge(blobStore blobstore.BlobStore, logger *logging.Logger, db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(attachmentPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purgedCount, err := vaultservice.PurgeDeletedAttachments(db, blobStore)
+			if err != nil {
+				logger.NewEvent(zerolog.ErrorLevel).Err(err).Msg("Purging deleted attachments failed.")
+			} else if purgedCount > 0 {
+				logger.NewEvent(zerolog.InfoLevel).Int64("purged_count", purgedCount).
+					Msg("Purged deleted attachments.")
+			}
+
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const loginChallengePurgeInterval = 10 * time.Minute
+
+// StartLoginChallengePurge starts a goroutine which periodically deletes the expired login challenges. Challenges of
+// the passwordless logins are created before the user is known, so they aren't cleaned up on the next login of a user.
+func StartLoginChallengePurge(logger *logging.Logger, db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(loginChallengePurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purgedCount, err := authservice.PurgeExpiredLoginChallenges(db, time.Now())
+			if err != nil {
+				logger.NewEvent(zerolog.ErrorLevel).Err(err).Msg("Purging expired login challenges failed.")
+			} else if purgedCount > 0 {
+				logger.NewEvent(zerolog.InfoLevel).Int64("purged_count", purgedCount).
+					Msg("Purged expired login challenges.")
+			}
+
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const secretSharePurgeInterval = time.Hour
+
+// StartSecretSharePurge starts a goroutine which periodically deletes the secret shares that have expired before
+// being viewed.
+func StartSecretSharePurge(logger *logging.Logger, db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(secretSharePurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purgedCount, err := vaultservice.PurgeExpiredSecretShares(db, time.Now())
+			if err != nil {
+				logger.NewEvent(zerolog.ErrorLevel).Err(err).Msg("Purging expired secret shares failed.")
+			} else if purgedCount > 0 {
+				logger.NewEvent(zerolog.InfoLevel).Int64("purged_count", purgedCount).
+					Msg("Purged expired secret shares.")
+			}
+
+			<-ticker.C
+		}
+	}()
+}
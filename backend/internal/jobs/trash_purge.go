@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/config"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const trashPurgeInterval = time.Hour
+
+// StartTrashPurge starts a goroutine which periodically hard-deletes vaults and vault items that have been in the
+// trash for longer than the configured retention period.
+func StartTrashPurge(apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB) {
+	retention := time.Second * time.Duration(apiConfig.TrashRetentionSeconds)
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purgedVaults, purgedItems, err := vaultservice.PurgeTrash(db, time.Now().Add(-retention))
+			if err != nil {
+				logger.NewEvent(zerolog.ErrorLevel).Err(err).Msg("Purging trash failed.")
+			} else if purgedVaults > 0 || purgedItems > 0 {
+				logger.NewEvent(zerolog.InfoLevel).Int64("purged_vaults", purgedVaults).
+					Int64("purged_items", purgedItems).Msg("Purged trash.")
+			}
+
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// AdminHandler middleware aborts with HTTP 403 Forbidden if the current user isn't an admin. It must be used after
+// CurrentUserHandler middleware.
+func AdminHandler(logger *logging.Logger) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		user, ok := ExtractUserFromGinContext(c)
+		if !ok {
+			logger.RequestEvent(zerolog.ErrorLevel, c).Msg("Extracting user from Gin context failed.")
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if user.Role != models.UserRoleAdmin {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+	}
+}
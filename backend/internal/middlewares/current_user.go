@@ -15,13 +15,15 @@ import (
 )
 
 const UserContextKey = "user"
+const UserSessionContextKey = "userSession"
+const PersonalAccessTokenContextKey = "personalAccessToken"
 
 // CurrentUserHandler middleware checks request for the authenticated user and puts user data to Gin context.
 // If request sent without authentication, this middleware aborts with HTTP 401 Unauthorized with no response
 // body. This middleware should only be used on routes that required authentication.
 func CurrentUserHandler(apiConfig *config.RestapiConfig, logger *logging.Logger, db *gorm.DB) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		user, userSession, err := GetCurrentUser(c, apiConfig, db)
+		user, userSession, token, err := GetCurrentUser(c, apiConfig, db)
 		if err != nil {
 			if errors.Is(err, UserNotAuthenticatedErr{}) {
 				c.AbortWithStatus(http.StatusUnauthorized)
@@ -33,19 +35,52 @@ func CurrentUserHandler(apiConfig *config.RestapiConfig, logger *logging.Logger,
 		}
 
 		timeNow := time.Now()
+		if token != nil {
+			if !personalAccessTokenAllowsRequest(c, *token) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			err = db.Model(token).Update("last_used_at", timeNow).Error
+			if err != nil {
+				logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Updating personal access token last use failed.")
+			}
+			c.Set(UserContextKey, user)
+			c.Set(PersonalAccessTokenContextKey, *token)
+			return
+		}
+
 		if userSession.ExpiresAt.Before(timeNow) {
 			db.Delete(&userSession)
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
 
-		err = db.Model(&userSession).Update("expires_at", timeNow.Add(time.Minute*60*24)).Error
+		err = db.Model(&userSession).Updates(map[string]any{
+			"expires_at":   timeNow.Add(time.Minute * 60 * 24),
+			"last_seen_at": timeNow,
+			"last_seen_ip": c.ClientIP(),
+		}).Error
 		if err != nil {
 			logger.RequestEvent(zerolog.ErrorLevel, c).Err(err).Msg("Extending user session expire time failed.")
 		}
 
 		c.Set(UserContextKey, user)
+		c.Set(UserSessionContextKey, userSession)
+	}
+}
+
+// ExtractUserSessionFromGinContext returns UserSession model of the current request inserted to Gin context by
+// CurrentUserHandler middleware.
+func ExtractUserSessionFromGinContext(c *gin.Context) (models.UserSession, bool) {
+	val, ok := c.Get(UserSessionContextKey)
+	if !ok {
+		return models.UserSession{}, false
+	}
+	userSession, ok := val.(models.UserSession)
+	if !ok {
+		return models.UserSession{}, false
 	}
+	return userSession, true
 }
 
 // ExtractUserFromGinContext returns User model inserted to Gin context by CurrentUserHandler middleware.
@@ -66,31 +101,46 @@ type UserNotAuthenticatedErr struct{}
 
 func (e UserNotAuthenticatedErr) Error() string { return "user is not authenticated" }
 
-// GetCurrentUser returns the User and UserSession if the user is logged-in, if not returns
-// UserNotAuthenticatedErr.
-func GetCurrentUser(c *gin.Context, apiConfig *config.RestapiConfig, db *gorm.DB) (models.User, models.UserSession, error) {
+// GetCurrentUser returns the User and UserSession if the user is logged-in, or the User and PersonalAccessToken if
+// the request has an Authorization: Bearer header. If the request isn't authenticated or the user isn't active,
+// returns UserNotAuthenticatedErr.
+func GetCurrentUser(c *gin.Context, apiConfig *config.RestapiConfig, db *gorm.DB) (models.User, models.UserSession, *models.PersonalAccessToken, error) {
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		user, token, err := getPersonalAccessTokenUser(db, authorization)
+		if err != nil {
+			return models.User{}, models.UserSession{}, nil, err
+		}
+		if !user.IsActive {
+			return models.User{}, models.UserSession{}, nil, UserNotAuthenticatedErr{}
+		}
+		return user, models.UserSession{}, &token, nil
+	}
+
 	sessionToken, err := c.Cookie(apiConfig.SessionTokenCookieName)
 	if err != nil {
 		if errors.Is(err, http.ErrNoCookie) {
-			return models.User{}, models.UserSession{}, UserNotAuthenticatedErr{}
+			return models.User{}, models.UserSession{}, nil, UserNotAuthenticatedErr{}
 		}
-		return models.User{}, models.UserSession{}, fmt.Errorf("getting session_token cookie failed: %w", err)
+		return models.User{}, models.UserSession{}, nil, fmt.Errorf("getting session_token cookie failed: %w", err)
 	}
 
 	var userSession models.UserSession
 	err = db.First(&userSession, "token = ?", sessionToken).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.User{}, models.UserSession{}, UserNotAuthenticatedErr{}
+			return models.User{}, models.UserSession{}, nil, UserNotAuthenticatedErr{}
 		}
-		return models.User{}, models.UserSession{}, fmt.Errorf("querying UserSession by token failed: %w", err)
+		return models.User{}, models.UserSession{}, nil, fmt.Errorf("querying UserSession by token failed: %w", err)
 	}
 
 	var user models.User
 	err = db.First(&user, "id = ?", userSession.UserID).Error
 	if err != nil {
-		return models.User{}, models.UserSession{}, fmt.Errorf("querying User by id failed: %w", err)
+		return models.User{}, models.UserSession{}, nil, fmt.Errorf("querying User by id failed: %w", err)
+	}
+	if !user.IsActive {
+		return models.User{}, models.UserSession{}, nil, UserNotAuthenticatedErr{}
 	}
 
-	return user, userSession, nil
+	return user, userSession, nil, nil
 }
@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// personalAccessTokenRoutes are the read-only routes which can be called with a personal access token. Vault routes
+// can only be called for the vaults the token can access.
+var personalAccessTokenRoutes = []string{
+	"GET /api/v1/users/me",
+	"GET /api/v1/vaults/:id",
+	"GET /api/v1/vaults/:id/my-permissions",
+	"GET /api/v1/vaults/:id/key",
+	"GET /api/v1/vaults/:id/items",
+	"GET /api/v1/vaults/:id/items/:itemId",
+	"GET /api/v1/vaults/:id/items/:itemId/revisions",
+}
+
+// ExtractPersonalAccessTokenFromGinContext returns the PersonalAccessToken of the current request inserted to Gin
+// context by CurrentUserHandler middleware. Returns false if the request is authenticated with a session.
+func ExtractPersonalAccessTokenFromGinContext(c *gin.Context) (models.PersonalAccessToken, bool) {
+	val, ok := c.Get(PersonalAccessTokenContextKey)
+	if !ok {
+		return models.PersonalAccessToken{}, false
+	}
+	token, ok := val.(models.PersonalAccessToken)
+	if !ok {
+		return models.PersonalAccessToken{}, false
+	}
+	return token, true
+}
+
+// getPersonalAccessTokenUser returns the user of the unexpired personal access token in given Authorization header
+// value.
+func getPersonalAccessTokenUser(db *gorm.DB, authorization string) (models.User, models.PersonalAccessToken, error) {
+	scheme, rawToken, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || rawToken == "" {
+		return models.User{}, models.PersonalAccessToken{}, UserNotAuthenticatedErr{}
+	}
+
+	var token models.PersonalAccessToken
+	err := db.First(&token, "token_hash = ? AND expires_at > ?", authservice.HashToken(rawToken), time.Now()).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, models.PersonalAccessToken{}, UserNotAuthenticatedErr{}
+		}
+		return models.User{}, models.PersonalAccessToken{}, fmt.Errorf("querying PersonalAccessToken by hash failed: %w", err)
+	}
+
+	var user models.User
+	err = db.First(&user, "id = ?", token.UserID).Error
+	if err != nil {
+		return models.User{}, models.PersonalAccessToken{}, fmt.Errorf("querying User by id failed: %w", err)
+	}
+
+	return user, token, nil
+}
+
+// personalAccessTokenAllowsRequest returns true if the route of the request can be called with given token.
+func personalAccessTokenAllowsRequest(c *gin.Context, token models.PersonalAccessToken) bool {
+	if !slices.Contains(personalAccessTokenRoutes, c.Request.Method+" "+c.FullPath()) {
+		return false
+	}
+	if !strings.HasPrefix(c.FullPath(), "/api/v1/vaults/:id") {
+		return true
+	}
+	vaultId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return false
+	}
+	return slices.Contains(token.VaultIDs, uint(vaultId))
+}
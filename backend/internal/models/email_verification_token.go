@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmailVerificationToken is sent to the email address of a user who registered while email verification is
+// required. The user is activated when the token is used. Only the hash of the token is stored.
+type EmailVerificationToken struct {
+	gorm.Model
+	UserID    uint      `gorm:"index"`
+	TokenHash string    `gorm:"unique;index"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
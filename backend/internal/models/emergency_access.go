@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	EmergencyAccessStatusIdle      string = "idle"
+	EmergencyAccessStatusRequested string = "requested"
+	EmergencyAccessStatusApproved  string = "approved"
+)
+
+// EmergencyAccess lets the grantee take over the account of the grantor. Access is granted when the grantor
+// approves the grantee's request, or automatically once WaitingPeriodDays passes after RequestedAt unless the
+// grantor denies the request. EncryptedPrivateKey is the private key of the grantor encrypted with the key pairs
+// of the grantor and the grantee, like vault keys.
+type EmergencyAccess struct {
+	gorm.Model
+	GrantorUserID       uint `gorm:"uniqueIndex:idx_emergency_access_grantor_grantee"`
+	GranteeUserID       uint `gorm:"uniqueIndex:idx_emergency_access_grantor_grantee;index"`
+	WaitingPeriodDays   int
+	Status              string
+	RequestedAt         *time.Time
+	EncryptionIV        string
+	EncryptedPrivateKey string
+
+	GrantorUser User `gorm:"foreignKey:GrantorUserID"`
+	GranteeUser User `gorm:"foreignKey:GranteeUserID"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// FailedLogin is a login attempt made to an account with a wrong password. Users can see them to notice attempts to
+// guess their password.
+type FailedLogin struct {
+	gorm.Model
+	UserID    uint `gorm:"index"`
+	IPAddress string
+	UserAgent string
+}
@@ -0,0 +1,9 @@
+package models
+
+import "gorm.io/gorm"
+
+type Group struct {
+	gorm.Model
+	Name    string
+	Members []GroupMember
+}
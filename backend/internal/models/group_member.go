@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+type GroupMember struct {
+	gorm.Model
+	GroupID   uint `gorm:"index"`
+	UserID    uint `gorm:"index"`
+	IsManager bool
+
+	User User `gorm:"foreignKey:UserID"`
+}
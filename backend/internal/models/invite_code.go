@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InviteCode lets a person register while registration is invite-only. Invite codes are issued by admins and can be
+// used once before ExpiresAt. Only the hash of the code is stored.
+type InviteCode struct {
+	gorm.Model
+	CodeHash        string `gorm:"unique;index"`
+	CreatedByUserID uint
+	ExpiresAt       time.Time `gorm:"not null"`
+	UsedByUserID    *uint
+	UsedAt          *time.Time
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// LoginChallenge is created when a user with a second factor passes the password check or logs in with the OpenID
+// Connect identity provider. Login is completed by sending the challenge token with the second factor before
+// ExpiresAt. Only the hash of the token is stored.
+// Challenges of passwordless passkey logins don't have a UserID, the user is known after the passkey assertion.
+// WebAuthnSession holds the WebAuthn ceremony data if the challenge can be completed with a passkey.
+type LoginChallenge struct {
+	gorm.Model
+	TokenHash       string `gorm:"unique;index"`
+	UserID          uint
+	ExpiresAt       time.Time `gorm:"not null"`
+	FailedAttempts  int
+	WebAuthnSession datatypes.JSON
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OIDCLoginState is created when a user starts logging in with the OpenID Connect identity provider and deleted on
+// the callback. Only the hash of the state is stored. Nonce and CodeVerifier bind the callback to this login.
+type OIDCLoginState struct {
+	gorm.Model
+	StateHash    string `gorm:"unique;index"`
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name GORM derives from the struct name, which would be o_id_c_login_states.
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
+
+// OIDCLinkRequest is created when an identity matches the email of a user it can't be linked to automatically, like
+// a user who hasn't verified their email or has a second factor. The identity is linked once the user confirms the
+// request from a logged-in session before ExpiresAt. Only the hash of the token is stored.
+type OIDCLinkRequest struct {
+	gorm.Model
+	TokenHash string `gorm:"unique;index"`
+	UserID    uint
+	Subject   string
+	ExpiresAt time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name GORM derives from the struct name, which would be o_id_c_link_requests.
+func (OIDCLinkRequest) TableName() string {
+	return "oidc_link_requests"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken lets scripts call the API as the user with an Authorization: Bearer header. Tokens are
+// read-only and can only access the vaults in VaultIDs. Only the hash of the token is stored, TokenPrefix is the
+// beginning of the token which helps the user to recognize it.
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID      uint `gorm:"index"`
+	Name        string
+	TokenHash   string `gorm:"unique;index"`
+	TokenPrefix string
+	VaultIDs    datatypes.JSONSlice[uint]
+	ExpiresAt   time.Time `gorm:"not null"`
+	LastUsedAt  *time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// RecoveryKey is the private key of the user encrypted with a recovery key which is generated on the client and
+// never sent to the server. VerifierHash is the hash of a value derived from the recovery key which proves that the
+// client knows the recovery key when the user recovers their account.
+type RecoveryKey struct {
+	gorm.Model
+	UserID              uint `gorm:"unique"`
+	VerifierHash        string
+	EnvelopeVersion     int
+	EncryptionIV        string
+	EncryptedPrivateKey string
+}
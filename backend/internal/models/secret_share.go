@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SecretShare is a link which lets someone without an account view a single vault item. EncryptedData is encrypted
+// on the client with a key that is never sent to the server and TokenHash is the hash of the token in the link.
+// The share is deleted once it's viewed MaxViews times.
+type SecretShare struct {
+	gorm.Model
+	VaultID       uint `gorm:"index"`
+	VaultItemID   uint `gorm:"index"`
+	CreatorUserID uint
+	TokenHash     string `gorm:"unique;index"`
+	EncryptedData string
+	MaxViews      int
+	ViewCount     int
+	ExpiresAt     time.Time `gorm:"not null"`
+
+	VaultItem   VaultItem `gorm:"foreignKey:VaultItemID"`
+	CreatorUser User      `gorm:"foreignKey:CreatorUserID"`
+}
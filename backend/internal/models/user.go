@@ -1,6 +1,15 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
 
 type User struct {
 	gorm.Model
@@ -9,7 +18,32 @@ type User struct {
 	Name              string
 	KeyDerivationSalt string
 	PublicKey         string
-	IsActive          bool `gorm:"default:true"`
+	// EncryptedPrivateKey is the private key of the user encrypted on the client, PrivateKeyEnvelopeVersion tells
+	// how it is encrypted. PrivateKeyEnvelopeVersion is 0 if the user hasn't stored their private key.
+	EncryptedPrivateKey       string
+	PrivateKeyEncryptionIV    string
+	PrivateKeyEnvelopeVersion int
+	Role                      string `gorm:"default:user"`
+	IsActive                  bool   `gorm:"default:true"`
+	// DeactivatedAt is set when an admin deactivates the user. Verifying the email address doesn't activate a
+	// deactivated user.
+	DeactivatedAt *time.Time
+	// EmailVerifiedAt is set when the user verifies their email address. Users who registered while email
+	// verification is required aren't active until then.
+	EmailVerifiedAt *time.Time
+	// OIDCSubject is the subject of the user at the OpenID Connect identity provider, it is set on the first login
+	// with the identity provider. Column name is given since GORM derives o_id_c_subject from the field name.
+	OIDCSubject *string `gorm:"column:oidc_subject;unique"`
+	// TOTPSecret is set when the user starts TOTP enrollment, TOTP is required on login only after TOTPEnabled is
+	// set by confirming the enrollment. TOTPLastCounter is the time step of the last accepted code which prevents
+	// reusing a code.
+	TOTPSecret      string
+	TOTPEnabled     bool
+	TOTPLastCounter int64
+	// FailedLoginCount is the number of consecutive failed password checks, login is refused until LockedUntil
+	// after too many failures.
+	FailedLoginCount int
+	LockedUntil      *time.Time
 
 	UserSessions     []UserSession
 	VaultPermissions []VaultPermission
@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// UserRecoveryCode is a single use code which can be used instead of a TOTP code on login. Only the hash of the code
+// is stored.
+type UserRecoveryCode struct {
+	gorm.Model
+	UserID   uint   `gorm:"index"`
+	CodeHash string `gorm:"index"`
+}
@@ -12,4 +12,7 @@ type UserSession struct {
 	UserID    uint
 	ExpiresAt time.Time `gorm:"not null"`
 	UserAgent string
+	// LastSeenAt and LastSeenIP are updated on every authenticated request made with the session.
+	LastSeenAt time.Time
+	LastSeenIP string
 }
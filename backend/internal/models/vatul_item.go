@@ -2,12 +2,28 @@ package models
 
 import "gorm.io/gorm"
 
+const (
+	VaultItemTypeLogin      string = "login"
+	VaultItemTypeSecureNote string = "secure_note"
+	VaultItemTypeCreditCard string = "credit_card"
+	VaultItemTypeAPIKey     string = "api_key"
+	VaultItemTypeSSHKey     string = "ssh_key"
+)
+
+// VaultItem is a secret stored in a vault. Type tells the clients how to display the item. EncryptedUsername,
+// EncryptedPassword and EncryptedNote are the legacy payload encrypted with EncryptionIV. Newer clients store the
+// fields of the item in EncryptedData which is encrypted with DataEncryptionIV, DataVersion is the format version of
+// EncryptedData and it is 0 if the item doesn't have one.
 type VaultItem struct {
 	gorm.Model
 	VaultID           uint
+	Type              string `gorm:"default:login"`
 	Title             string
 	EncryptionIV      string
 	EncryptedUsername string
 	EncryptedPassword string
 	EncryptedNote     string
+	DataVersion       int
+	DataEncryptionIV  string
+	EncryptedData     string
 }
@@ -5,6 +5,7 @@ import "gorm.io/gorm"
 type Vault struct {
 	gorm.Model
 	Name             string
+	OwnerUserID      uint `gorm:"default:null"`
 	VaultPermissions []VaultPermission
 	VaultItems       []VaultItem
 }
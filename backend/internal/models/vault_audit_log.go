@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -30,6 +32,26 @@ const (
 	AuditLogActionVaultItemCreate AuditLogAction = "vault_item_create"
 	AuditLogActionVaultItemUpdate AuditLogAction = "vault_item_update"
 	AuditLogActionVaultItemDelete AuditLogAction = "vault_item_delete"
+
+	AuditLogActionVaultItemRestoreRevision  AuditLogAction = "vault_item_restore_revision"
+	AuditLogActionVaultItemRestore          AuditLogAction = "vault_item_restore"
+	AuditLogActionVaultRestore              AuditLogAction = "vault_restore"
+	AuditLogActionVaultUpdatePermissions    AuditLogAction = "vault_update_permissions"
+	AuditLogActionVaultTransferOwnership    AuditLogAction = "vault_transfer_ownership"
+	AuditLogActionVaultKeyRotated           AuditLogAction = "vault_key_rotated"
+	AuditLogActionVaultAddGroup             AuditLogAction = "vault_add_group"
+	AuditLogActionVaultRemoveGroup          AuditLogAction = "vault_remove_group"
+	AuditLogActionVaultShareKeys            AuditLogAction = "vault_share_keys"
+	AuditLogActionVaultInviteUser           AuditLogAction = "vault_invite_user"
+	AuditLogActionVaultRevokeInvitation     AuditLogAction = "vault_revoke_invitation"
+	AuditLogActionVaultAcceptInvitation     AuditLogAction = "vault_accept_invitation"
+	AuditLogActionVaultDeclineInvitation    AuditLogAction = "vault_decline_invitation"
+	AuditLogActionVaultAccessExpired        AuditLogAction = "vault_access_expired"
+	AuditLogActionVaultItemShareCreate      AuditLogAction = "vault_item_share_create"
+	AuditLogActionVaultItemShareView        AuditLogAction = "vault_item_share_view"
+	AuditLogActionVaultItemShareRevoke      AuditLogAction = "vault_item_share_revoke"
+	AuditLogActionVaultItemAttachmentAdd    AuditLogAction = "vault_item_attachment_add"
+	AuditLogActionVaultItemAttachmentDelete AuditLogAction = "vault_item_attachment_delete"
 )
 
 func AuditLogDataVaultCreate(name string) map[string]any {
@@ -83,3 +105,132 @@ func AuditLogDataVaultItemDelete(title string) map[string]any {
 		"title": title,
 	}
 }
+
+func AuditLogDataVaultItemRestoreRevision(title string, revisionId uint) map[string]any {
+	return map[string]any{
+		"title":       title,
+		"revision_id": revisionId,
+	}
+}
+
+func AuditLogDataVaultItemRestore(title string) map[string]any {
+	return map[string]any{
+		"title": title,
+	}
+}
+
+func AuditLogDataVaultRestore(name string) map[string]any {
+	return map[string]any{
+		"name": name,
+	}
+}
+
+func AuditLogDataVaultUpdatePermissions(userEmail string, oldPermissions, newPermissions []string) map[string]any {
+	return map[string]any{
+		"user_email":      userEmail,
+		"old_permissions": oldPermissions,
+		"new_permissions": newPermissions,
+	}
+}
+
+func AuditLogDataVaultTransferOwnership(oldOwnerEmail, newOwnerEmail string) map[string]any {
+	return map[string]any{
+		"old_owner_email": oldOwnerEmail,
+		"new_owner_email": newOwnerEmail,
+	}
+}
+
+func AuditLogDataVaultKeyRotated(memberCount, itemCount int) map[string]any {
+	return map[string]any{
+		"member_count": memberCount,
+		"item_count":   itemCount,
+	}
+}
+
+func AuditLogDataVaultAddGroup(groupName string, permissions []string) map[string]any {
+	return map[string]any{
+		"group_name":  groupName,
+		"permissions": permissions,
+	}
+}
+
+func AuditLogDataVaultRemoveGroup(groupName string) map[string]any {
+	return map[string]any{
+		"group_name": groupName,
+	}
+}
+
+func AuditLogDataVaultShareKeys(userEmails []string) map[string]any {
+	return map[string]any{
+		"user_emails": userEmails,
+	}
+}
+
+func AuditLogDataVaultInviteUser(email string, permissions []string) map[string]any {
+	return map[string]any{
+		"invited_email": email,
+		"permissions":   permissions,
+	}
+}
+
+func AuditLogDataVaultRevokeInvitation(email string) map[string]any {
+	return map[string]any{
+		"invited_email": email,
+	}
+}
+
+func AuditLogDataVaultAcceptInvitation(email string) map[string]any {
+	return map[string]any{
+		"invited_email": email,
+	}
+}
+
+func AuditLogDataVaultDeclineInvitation(email string) map[string]any {
+	return map[string]any{
+		"invited_email": email,
+	}
+}
+
+func AuditLogDataVaultAccessExpired(userEmail string, permissions []string) map[string]any {
+	return map[string]any{
+		"user_email":  userEmail,
+		"permissions": permissions,
+	}
+}
+
+func AuditLogDataVaultItemShareCreate(title string, maxViews int, expiresAt time.Time) map[string]any {
+	return map[string]any{
+		"title":      title,
+		"max_views":  maxViews,
+		"expires_at": expiresAt,
+	}
+}
+
+func AuditLogDataVaultItemShareView(title string, viewCount, maxViews int) map[string]any {
+	return map[string]any{
+		"title":      title,
+		"view_count": viewCount,
+		"max_views":  maxViews,
+	}
+}
+
+func AuditLogDataVaultItemShareRevoke(title string) map[string]any {
+	return map[string]any{
+		"title": title,
+	}
+}
+
+func AuditLogDataVaultItemAttachmentAdd(title string, attachmentId uint, size int64) map[string]any {
+	return map[string]any{
+		"title":         title,
+		"attachment_id": attachmentId,
+		"size":          size,
+	}
+}
+
+func AuditLogDataVaultItemAttachmentDelete(title string, attachmentId uint) map[string]any {
+	return map[string]any{
+		"title":         title,
+		"attachment_id": attachmentId,
+	}
+}
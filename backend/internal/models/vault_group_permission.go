@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// VaultGroupPermission gives a permission on a vault to every member of a group. Members of the group still need
+// their own VaultKey record to decrypt the vault.
+type VaultGroupPermission struct {
+	gorm.Model
+	VaultID    uint `gorm:"index"`
+	GroupID    uint `gorm:"index"`
+	Permission string
+
+	Group Group `gorm:"foreignKey:GroupID"`
+}
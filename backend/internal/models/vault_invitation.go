@@ -0,0 +1,22 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// VaultInvitation is an invitation to a vault sent to an email address which doesn't need to belong to a registered
+// user yet. InviteeUserID is set when the invitee accepts the invitation, after that a vault manager completes the
+// invitation by encrypting the vault key with the invitee's public key.
+type VaultInvitation struct {
+	gorm.Model
+	VaultID       uint   `gorm:"index"`
+	Email         string `gorm:"index"`
+	InviterUserID uint
+	InviteeUserID uint `gorm:"default:null"`
+	Permissions   datatypes.JSONSlice[string]
+
+	Vault       Vault `gorm:"foreignKey:VaultID"`
+	InviterUser User  `gorm:"foreignKey:InviterUserID"`
+	InviteeUser User  `gorm:"foreignKey:InviteeUserID"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "gorm.io/gorm"
+
+// VaultItemAttachment is a file attached to a vault item. The file is encrypted on the client and its content is
+// kept in the blob store under BlobKey. EncryptedMetadata holds the file name, the content type and the key the
+// content is encrypted with, encrypted with the vault key, so rotating the vault key only re-encrypts the metadata.
+// Soft-deleted attachments are kept until their blobs are deleted, see vault.PurgeDeletedAttachments.
+type VaultItemAttachment struct {
+	gorm.Model
+	VaultID           uint `gorm:"index"`
+	VaultItemID       uint `gorm:"index"`
+	UploaderUserID    uint
+	BlobKey           string `gorm:"unique"`
+	Size              int64
+	EncryptionIV      string
+	EncryptedMetadata string
+
+	UploaderUser User `gorm:"foreignKey:UploaderUserID"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+// VaultItemRevision is a snapshot of a VaultItem's encrypted payload taken right before the item is
+// overwritten. UserID is the user whose change replaced this revision.
+type VaultItemRevision struct {
+	gorm.Model
+	VaultItemID       uint `gorm:"index"`
+	UserID            uint
+	Type              string `gorm:"default:login"`
+	Title             string
+	EncryptionIV      string
+	EncryptedUsername string
+	EncryptedPassword string
+	EncryptedNote     string
+	DataVersion       int
+	DataEncryptionIV  string
+	EncryptedData     string
+
+	User User `gorm:"foreignKey:UserID"`
+}
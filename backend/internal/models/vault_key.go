@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type VaultKey struct {
 	gorm.Model
@@ -9,6 +13,7 @@ type VaultKey struct {
 	InviterUserID     uint
 	EncryptionIV      string
 	EncryptedVaultKey string
+	ExpiresAt         *time.Time
 
 	KeyOwnerUser User `gorm:"foreignKey:KeyOwnerUserID"`
 	InviterUser  User `gorm:"foreignKey:InviterUserID"`
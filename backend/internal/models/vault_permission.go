@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 const (
 	VaultPermissionManageVault string = "manage_vault"
@@ -14,4 +18,6 @@ type VaultPermission struct {
 	VaultID    uint
 	UserID     uint
 	Permission string
+	// ExpiresAt is the time the permission is revoked at. Permissions without expiry are kept until they are removed.
+	ExpiresAt *time.Time `gorm:"index"`
 }
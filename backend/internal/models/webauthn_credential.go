@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// WebAuthnCredential is a passkey or security key registered by a user. SignCount and CloneWarning are updated on
+// every login made with the credential.
+type WebAuthnCredential struct {
+	gorm.Model
+	UserID          uint `gorm:"index"`
+	Name            string
+	CredentialID    []byte `gorm:"unique;index"`
+	PublicKey       []byte
+	AttestationType string
+	Transports      datatypes.JSONSlice[string]
+	AAGUID          []byte
+	SignCount       uint32
+	CloneWarning    bool
+	Attachment      string
+	BackupEligible  bool
+	BackupState     bool
+	LastUsedAt      *time.Time
+
+	User User
+}
+
+// WebAuthnRegistration holds the ceremony data of a passkey registration started by the user until the
+// registration is finished or ExpiresAt passes. A user has at most one registration in progress.
+type WebAuthnRegistration struct {
+	gorm.Model
+	UserID      uint `gorm:"index"`
+	SessionData datatypes.JSON
+	ExpiresAt   time.Time `gorm:"not null"`
+}
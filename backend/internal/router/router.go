@@ -1,27 +1,42 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	golog "log"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/berk-karaal/letuspass/backend/internal/common/blobstore"
 	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/common/ratelimit"
 	"github.com/berk-karaal/letuspass/backend/internal/config"
 	"github.com/berk-karaal/letuspass/backend/internal/databases/postgres"
+	"github.com/berk-karaal/letuspass/backend/internal/jobs"
 	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
 	"github.com/berk-karaal/letuspass/backend/internal/models"
+	adminservice "github.com/berk-karaal/letuspass/backend/internal/services/admin"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
 	_ "github.com/berk-karaal/letuspass/backend/swagger"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+const (
+	// mailQueueSize is the number of mails which can wait to be sent, new mails are dropped when the queue is full.
+	mailQueueSize = 100
+	// mailQueueWorkers is the number of mails sent concurrently.
+	mailQueueWorkers = 2
+)
+
 func SetupRouter(apiConfig config.RestapiConfig) *gin.Engine {
 	registerJsonTagNames()
 
@@ -35,26 +50,97 @@ func SetupRouter(apiConfig config.RestapiConfig) *gin.Engine {
 		golog.Fatal(err)
 	}
 	err = postgresDb.AutoMigrate(&models.User{}, &models.UserSession{}, &models.Vault{}, &models.VaultPermission{},
-		&models.VaultItem{}, &models.VaultKey{}, &models.VaultAuditLog{})
+		&models.VaultItem{}, &models.VaultKey{}, &models.VaultAuditLog{}, &models.VaultItemRevision{},
+		&models.Group{}, &models.GroupMember{}, &models.VaultGroupPermission{}, &models.VaultInvitation{},
+		&models.SecretShare{}, &models.UserRecoveryCode{}, &models.LoginChallenge{},
+		&models.WebAuthnCredential{}, &models.WebAuthnRegistration{}, &models.FailedLogin{}, &models.RecoveryKey{},
+		&models.EmergencyAccess{}, &models.PersonalAccessToken{}, &models.OIDCLoginState{},
+		&models.EmailVerificationToken{}, &models.InviteCode{}, &models.VaultItemAttachment{},
+		&models.OIDCLinkRequest{})
 	if err != nil {
 		golog.Fatal(err)
 	}
 
+	if err := adminservice.PromoteAdmins(postgresDb, apiConfig.AdminEmails); err != nil {
+		golog.Fatal(err)
+	}
+
+	blobStore, err := blobstore.NewLocalStore(apiConfig.AttachmentsDir)
+	if err != nil {
+		golog.Fatal(err)
+	}
+
+	jobs.StartTrashPurge(&apiConfig, logger, postgresDb)
+	jobs.StartAccessExpirySweep(logger, postgresDb)
+	jobs.StartSecretSharePurge(logger, postgresDb)
+	jobs.StartAttachmentPurge(blobStore, logger, postgresDb)
+	jobs.StartLoginChallengePurge(logger, postgresDb)
+
+	webAuthn, err := authservice.NewWebAuthn(apiConfig.WebAuthnRPID, apiConfig.WebAuthnRPDisplayName,
+		apiConfig.WebAuthnRPOrigins)
+	if err != nil {
+		golog.Fatal(err)
+	}
+
+	var oidcProvider *authservice.OIDCProvider
+	if apiConfig.OIDCIssuerURL != "" {
+		oidcProvider, err = authservice.NewOIDCProvider(context.Background(), apiConfig.OIDCIssuerURL,
+			apiConfig.OIDCClientID, apiConfig.OIDCClientSecret, apiConfig.OIDCRedirectURL)
+		if err != nil {
+			golog.Fatal(err)
+		}
+	}
+
+	loginLimiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{
+		FreeFailures: apiConfig.LoginRateLimitFreeFailures,
+		BaseDelay:    time.Second * time.Duration(apiConfig.LoginRateLimitBaseDelaySeconds),
+		MaxDelay:     time.Second * time.Duration(apiConfig.LoginRateLimitMaxDelaySeconds),
+		Window:       time.Second * time.Duration(apiConfig.LoginRateLimitWindowSeconds),
+	})
+
+	passwordPolicy, err := authservice.NewPasswordPolicy(apiConfig.PasswordMinLength, apiConfig.PasswordMinEntropyBits,
+		apiConfig.BreachedPasswordsDir)
+	if err != nil {
+		golog.Fatal(err)
+	}
+
+	var mail mailer.Mailer
+	switch apiConfig.MailDriver {
+	case "smtp":
+		mail, err = mailer.NewSMTPMailer(apiConfig.SMTPHost, apiConfig.SMTPPort, apiConfig.SMTPUsername,
+			apiConfig.SMTPPassword, apiConfig.MailFrom)
+		if err != nil {
+			golog.Fatal(err)
+		}
+	case "file":
+		mail = mailer.NewFileMailer(apiConfig.MailFile)
+	default:
+		golog.Fatal("MAIL_DRIVER must be one of these: smtp, file.")
+	}
+	mail = mailer.NewQueue(mail, mailQueueSize, mailQueueWorkers, func(msg mailer.Message, err error) {
+		logger.NewEvent(zerolog.ErrorLevel).Err(err).Str("subject", msg.Subject).Msg("Sending mail failed.")
+	})
+
 	gin.SetMode(apiConfig.GinMode)
 
 	router := gin.New()
+	if err := router.SetTrustedProxies(apiConfig.TrustedProxies); err != nil {
+		golog.Fatal(err)
+	}
 	router.Use(gin.Recovery())
 	router.Use(requestid.New())
 	router.Use(middlewares.LogHandler(logger))
 	router.Use(cors.New(cors.Config{
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowOrigins:     apiConfig.CORSAllowOrigins,
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type"},
+		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		AllowOrigins: apiConfig.CORSAllowOrigins,
+		AllowHeaders: []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Encryption-IV",
+			"X-Encrypted-Metadata"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	SetupRoutes(router, &apiConfig, logger, postgresDb)
+	SetupRoutes(router, &apiConfig, webAuthn, oidcProvider, loginLimiter, passwordPolicy, mail, blobStore, logger,
+		postgresDb)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
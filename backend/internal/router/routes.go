@@ -1,15 +1,20 @@
 package router
 
 import (
+	"github.com/berk-karaal/letuspass/backend/internal/common/blobstore"
 	"github.com/berk-karaal/letuspass/backend/internal/common/logging"
+	"github.com/berk-karaal/letuspass/backend/internal/common/mailer"
+	"github.com/berk-karaal/letuspass/backend/internal/common/ratelimit"
 	"github.com/berk-karaal/letuspass/backend/internal/config"
 	"github.com/berk-karaal/letuspass/backend/internal/controllers"
 	"github.com/berk-karaal/letuspass/backend/internal/middlewares"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(engine *gin.Engine, apiConfig *config.RestapiConfig, logger *logging.Logger, postgres *gorm.DB) {
+func SetupRoutes(engine *gin.Engine, apiConfig *config.RestapiConfig, webAuthn *webauthn.WebAuthn, oidcProvider *authservice.OIDCProvider, loginLimiter *ratelimit.Limiter, passwordPolicy *authservice.PasswordPolicy, mail mailer.Mailer, blobStore blobstore.BlobStore, logger *logging.Logger, postgres *gorm.DB) {
 	v1Group := engine.Group("/api/v1")
 	{
 		metricGroup := v1Group.Group("/metrics")
@@ -19,8 +24,20 @@ func SetupRoutes(engine *gin.Engine, apiConfig *config.RestapiConfig, logger *lo
 
 		authGroup := v1Group.Group("/auth")
 		{
-			authGroup.POST("/login", controllers.HandleAuthLogin(apiConfig, logger, postgres))
-			authGroup.POST("/register", controllers.HandleAuthRegister(logger, postgres))
+			authGroup.POST("/login", controllers.HandleAuthLogin(apiConfig, webAuthn, loginLimiter, mail, logger, postgres))
+			authGroup.POST("/login/totp", controllers.HandleAuthLoginTOTP(apiConfig, loginLimiter, mail, logger, postgres))
+			authGroup.POST("/login/webauthn", controllers.HandleAuthLoginWebAuthn(apiConfig, webAuthn, loginLimiter, mail, logger, postgres))
+			authGroup.POST("/passkey/begin", controllers.HandleAuthPasskeyBegin(webAuthn, loginLimiter, logger, postgres))
+			authGroup.POST("/passkey/finish", controllers.HandleAuthPasskeyFinish(apiConfig, webAuthn, loginLimiter, mail, logger, postgres))
+			authGroup.GET("/oidc/login", controllers.HandleAuthOIDCLogin(oidcProvider, logger, postgres))
+			authGroup.GET("/oidc/callback", controllers.HandleAuthOIDCCallback(apiConfig, webAuthn, oidcProvider, mail, logger, postgres))
+			authGroup.GET("/registration-policy", controllers.HandleAuthRegistrationPolicy(apiConfig))
+			authGroup.POST("/register", controllers.HandleAuthRegister(apiConfig, passwordPolicy, mail, logger, postgres))
+			authGroup.POST("/verify-email", controllers.HandleAuthVerifyEmail(apiConfig, logger, postgres))
+			authGroup.POST("/verify-email/resend", controllers.HandleAuthVerifyEmailResend(apiConfig, loginLimiter, mail, logger, postgres))
+			authGroup.POST("/password-reset", controllers.HandleAuthPasswordReset(apiConfig, loginLimiter, mail, logger, postgres))
+			authGroup.POST("/recovery/begin", controllers.HandleAuthRecoveryBegin(loginLimiter, logger, postgres))
+			authGroup.POST("/recovery/complete", controllers.HandleAuthRecoveryComplete(loginLimiter, passwordPolicy, mail, logger, postgres))
 			authGroup.POST("/logout", middlewares.CurrentUserHandler(apiConfig, logger, postgres), controllers.HandleAuthLogout(apiConfig, logger, postgres))
 		}
 
@@ -28,26 +45,91 @@ func SetupRoutes(engine *gin.Engine, apiConfig *config.RestapiConfig, logger *lo
 		{
 			userGroup.GET("/me", controllers.HandleUsersMe(logger))
 			userGroup.GET("/by-email", controllers.HandleGetUserByEmail(logger, postgres))
+			userGroup.GET("/me/sessions", controllers.HandleUsersMeSessionsList(logger, postgres))
+			userGroup.DELETE("/me/sessions/:id", controllers.HandleUsersMeSessionsDelete(logger, postgres))
+			userGroup.POST("/me/sessions/revoke-others", controllers.HandleUsersMeSessionsRevokeOthers(logger, postgres))
+			userGroup.GET("/me/failed-logins", controllers.HandleUsersMeFailedLoginsList(logger, postgres))
+			userGroup.GET("/me/vault-keys", controllers.HandleUsersMeVaultKeysList(logger, postgres))
+			userGroup.POST("/me/change-password", controllers.HandleUsersMeChangePassword(loginLimiter, passwordPolicy, mail, logger, postgres))
+			userGroup.GET("/me/keys", controllers.HandleUsersMeKeysGet(logger))
+			userGroup.POST("/me/keys", controllers.HandleUsersMeKeysSetUp(passwordPolicy, logger, postgres))
+			userGroup.PUT("/me/private-key", controllers.HandleUsersMePrivateKeyUpdate(loginLimiter, logger, postgres))
+			userGroup.GET("/me/tokens", controllers.HandleUsersMeTokensList(logger, postgres))
+			userGroup.POST("/me/tokens", controllers.HandleUsersMeTokensCreate(logger, postgres))
+			userGroup.DELETE("/me/tokens/:id", controllers.HandleUsersMeTokensRevoke(logger, postgres))
+			userGroup.GET("/me/recovery-key", controllers.HandleUsersMeRecoveryKeyGet(logger, postgres))
+			userGroup.PUT("/me/recovery-key", controllers.HandleUsersMeRecoveryKeyUpdate(logger, postgres))
+			userGroup.DELETE("/me/recovery-key", controllers.HandleUsersMeRecoveryKeyDelete(logger, postgres))
+			userGroup.GET("/me/emergency-contacts", controllers.HandleUsersMeEmergencyContactsList(logger, postgres))
+			userGroup.POST("/me/emergency-contacts", controllers.HandleUsersMeEmergencyContactsCreate(logger, postgres))
+			userGroup.DELETE("/me/emergency-contacts/:id", controllers.HandleUsersMeEmergencyContactsDelete(logger, postgres))
+			userGroup.POST("/me/emergency-contacts/:id/approve", controllers.HandleUsersMeEmergencyContactsApprove(logger, postgres))
+			userGroup.POST("/me/emergency-contacts/:id/deny", controllers.HandleUsersMeEmergencyContactsDeny(logger, postgres))
+			userGroup.GET("/me/emergency-grants", controllers.HandleUsersMeEmergencyGrantsList(logger, postgres))
+			userGroup.DELETE("/me/emergency-grants/:id", controllers.HandleUsersMeEmergencyGrantsDelete(logger, postgres))
+			userGroup.POST("/me/emergency-grants/:id/request", controllers.HandleUsersMeEmergencyGrantsRequest(mail, logger, postgres))
+			userGroup.GET("/me/emergency-grants/:id/takeover", controllers.HandleUsersMeEmergencyGrantsTakeoverGet(logger, postgres))
+			userGroup.POST("/me/emergency-grants/:id/takeover", controllers.HandleUsersMeEmergencyGrantsTakeover(passwordPolicy, mail, logger, postgres))
+			userGroup.GET("/me/invitations", controllers.HandleUsersMeInvitationsList(logger, postgres))
+			userGroup.POST("/me/invitations/:id/accept", controllers.HandleUsersMeInvitationsAccept(logger, postgres))
+			userGroup.POST("/me/invitations/:id/decline", controllers.HandleUsersMeInvitationsDecline(logger, postgres))
+			userGroup.POST("/me/totp/enroll", controllers.HandleUsersMeTOTPEnroll(logger, postgres))
+			userGroup.POST("/me/totp/confirm", controllers.HandleUsersMeTOTPConfirm(logger, postgres))
+			userGroup.POST("/me/totp/disable", controllers.HandleUsersMeTOTPDisable(logger, postgres))
+			userGroup.POST("/me/totp/recovery-codes", controllers.HandleUsersMeTOTPRecoveryCodes(logger, postgres))
+			userGroup.POST("/me/webauthn/register/begin", controllers.HandleUsersMeWebAuthnRegisterBegin(webAuthn, logger, postgres))
+			userGroup.POST("/me/webauthn/register/finish", controllers.HandleUsersMeWebAuthnRegisterFinish(webAuthn, logger, postgres))
+			userGroup.GET("/me/webauthn/credentials", controllers.HandleUsersMeWebAuthnCredentialsList(logger, postgres))
+			userGroup.DELETE("/me/webauthn/credentials/:id", controllers.HandleUsersMeWebAuthnCredentialsDelete(logger, postgres))
+			userGroup.POST("/me/oidc/link/passkey-challenge", controllers.HandleUsersMeOIDCLinkPasskeyChallenge(webAuthn, logger, postgres))
+			userGroup.POST("/me/oidc/link", controllers.HandleUsersMeOIDCLink(webAuthn, logger, postgres))
+		}
+
+		adminGroup := v1Group.Group("/admin", middlewares.CurrentUserHandler(apiConfig, logger, postgres), middlewares.AdminHandler(logger))
+		{
+			adminGroup.GET("/users", controllers.HandleAdminUsersList(logger, postgres))
+			adminGroup.POST("/users/:id/deactivate", controllers.HandleAdminUsersDeactivate(logger, postgres))
+			adminGroup.POST("/users/:id/reactivate", controllers.HandleAdminUsersReactivate(apiConfig, logger, postgres))
+			adminGroup.DELETE("/users/:id", controllers.HandleAdminUsersDelete(logger, postgres))
+			adminGroup.GET("/invite-codes", controllers.HandleAdminInviteCodesList(logger, postgres))
+			adminGroup.POST("/invite-codes", controllers.HandleAdminInviteCodesCreate(logger, postgres))
+			adminGroup.DELETE("/invite-codes/:id", controllers.HandleAdminInviteCodesRevoke(logger, postgres))
 		}
 
 		vaultGroup := v1Group.Group("/vaults", middlewares.CurrentUserHandler(apiConfig, logger, postgres))
 		{
 			vaultGroup.POST("", controllers.HandleVaultsCreate(logger, postgres))
 			vaultGroup.GET("", controllers.HandleVaultsList(logger, postgres))
+			vaultGroup.GET("/trash", controllers.HandleVaultsListTrash(logger, postgres))
 			vaultGroup.GET("/:id", controllers.HandleVaultsRetrieve(logger, postgres))
 			vaultGroup.DELETE("/:id", controllers.HandleVaultDelete(logger, postgres))
+			vaultGroup.POST("/:id/restore", controllers.HandleVaultsRestore(logger, postgres))
 
 			vaultGroup.GET("/:id/my-permissions", controllers.HandleVaultsMyPermissions(logger, postgres))
 			vaultGroup.GET("/:id/key", controllers.HandleVaultsMyKey(logger, postgres))
 			vaultGroup.POST("/:id/leave", controllers.HandleVaultsLeave(logger, postgres))
 			vaultGroup.GET("/:id/logs", controllers.HandleVaultAuditLogsList(logger, postgres))
+			vaultGroup.GET("/:id/trash", controllers.HandleVaultTrashList(logger, postgres))
+			vaultGroup.POST("/:id/trash/:itemId/restore", controllers.HandleVaultItemsRestore(apiConfig, logger, postgres))
 
 			vaultManage := vaultGroup.Group("/:id/manage")
 			{
 				vaultManage.GET("/users", controllers.HandleVaultsManageListUsers(logger, postgres))
 				vaultManage.DELETE("/users", controllers.HandleVaultsManageRemoveUser(logger, postgres))
-				vaultManage.POST("/add-user", controllers.HandleVaultsManageAddUser(logger, postgres))
+				vaultManage.PATCH("/users/:userId", controllers.HandleVaultsManageUpdateUserPermissions(logger, postgres))
+				vaultManage.POST("/add-user", controllers.HandleVaultsManageAddUser(mail, logger, postgres))
 				vaultManage.POST("/rename", controllers.HandleVaultsManageRename(logger, postgres))
+				vaultManage.POST("/transfer-ownership", controllers.HandleVaultsManageTransferOwnership(logger, postgres))
+				vaultManage.POST("/rotate-key", controllers.HandleVaultsManageRotateKey(logger, postgres))
+				vaultManage.GET("/groups", controllers.HandleVaultsManageListGroups(logger, postgres))
+				vaultManage.POST("/groups", controllers.HandleVaultsManageAddGroup(logger, postgres))
+				vaultManage.DELETE("/groups/:groupId", controllers.HandleVaultsManageRemoveGroup(logger, postgres))
+				vaultManage.GET("/pending-keys", controllers.HandleVaultsManageListPendingKeys(logger, postgres))
+				vaultManage.POST("/pending-keys", controllers.HandleVaultsManageSharePendingKeys(logger, postgres))
+				vaultManage.GET("/invitations", controllers.HandleVaultsManageListInvitations(logger, postgres))
+				vaultManage.POST("/invitations", controllers.HandleVaultsManageInviteUser(apiConfig, mail, logger, postgres))
+				vaultManage.DELETE("/invitations/:invitationId", controllers.HandleVaultsManageRevokeInvitation(logger, postgres))
+				vaultManage.POST("/invitations/:invitationId/complete", controllers.HandleVaultsManageCompleteInvitation(mail, logger, postgres))
 			}
 
 			vaultItemGroup := vaultGroup.Group("/:id/items")
@@ -57,7 +139,31 @@ func SetupRoutes(engine *gin.Engine, apiConfig *config.RestapiConfig, logger *lo
 				vaultItemGroup.GET("/:itemId", controllers.HandleVaultItemsRetrieve(logger, postgres))
 				vaultItemGroup.PUT("/:itemId", controllers.HandleVaultItemsUpdate(logger, postgres))
 				vaultItemGroup.DELETE("/:itemId", controllers.HandleVaultItemsDelete(logger, postgres))
+				vaultItemGroup.GET("/:itemId/revisions", controllers.HandleVaultItemRevisionsList(logger, postgres))
+				vaultItemGroup.POST("/:itemId/revisions/:revId/restore", controllers.HandleVaultItemRevisionsRestore(logger, postgres))
+				vaultItemGroup.POST("/:itemId/shares", controllers.HandleVaultItemSharesCreate(apiConfig, logger, postgres))
+				vaultItemGroup.GET("/:itemId/shares", controllers.HandleVaultItemSharesList(logger, postgres))
+				vaultItemGroup.DELETE("/:itemId/shares/:shareId", controllers.HandleVaultItemSharesRevoke(logger, postgres))
+				vaultItemGroup.POST("/:itemId/attachments", controllers.HandleVaultItemAttachmentsUpload(apiConfig, blobStore, logger, postgres))
+				vaultItemGroup.GET("/:itemId/attachments", controllers.HandleVaultItemAttachmentsList(logger, postgres))
+				vaultItemGroup.GET("/:itemId/attachments/:attachmentId", controllers.HandleVaultItemAttachmentsDownload(blobStore, logger, postgres))
+				vaultItemGroup.DELETE("/:itemId/attachments/:attachmentId", controllers.HandleVaultItemAttachmentsDelete(logger, postgres))
 			}
 		}
+
+		shareGroup := v1Group.Group("/share")
+		{
+			shareGroup.GET("/:token", controllers.HandleSecretSharesRetrieve(logger, postgres))
+		}
+
+		groupGroup := v1Group.Group("/groups", middlewares.CurrentUserHandler(apiConfig, logger, postgres))
+		{
+			groupGroup.POST("", controllers.HandleGroupsCreate(logger, postgres))
+			groupGroup.GET("", controllers.HandleGroupsList(logger, postgres))
+			groupGroup.GET("/:id", controllers.HandleGroupsRetrieve(logger, postgres))
+			groupGroup.DELETE("/:id", controllers.HandleGroupsDelete(logger, postgres))
+			groupGroup.POST("/:id/members", controllers.HandleGroupsAddMember(logger, postgres))
+			groupGroup.DELETE("/:id/members/:userId", controllers.HandleGroupsRemoveMember(logger, postgres))
+		}
 	}
 }
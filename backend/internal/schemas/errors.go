@@ -7,3 +7,19 @@ type BadRequestResponse struct {
 type NotFoundResponse struct {
 	Error string `json:"error" binding:"required"`
 }
+
+type ConflictResponse struct {
+	Error string `json:"error" binding:"required"`
+}
+
+type TooManyRequestsResponse struct {
+	Error string `json:"error" binding:"required"`
+}
+
+type ForbiddenResponse struct {
+	Error string `json:"error" binding:"required"`
+}
+
+type PayloadTooLargeResponse struct {
+	Error string `json:"error" binding:"required"`
+}
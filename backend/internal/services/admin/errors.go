@@ -0,0 +1,17 @@
+package admin
+
+// UserNotFoundErr is returned when the user an operation targets doesn't exist.
+type UserNotFoundErr struct{}
+
+func (e UserNotFoundErr) Error() string { return "user not found" }
+
+// UserNotDeactivatedErr is returned when a user who wasn't deactivated by an admin is reactivated.
+type UserNotDeactivatedErr struct{}
+
+func (e UserNotDeactivatedErr) Error() string { return "user is not deactivated" }
+
+// SelfManagementErr is returned when an admin tries to deactivate or delete themselves, which could leave the
+// application without an active admin.
+type SelfManagementErr struct{}
+
+func (e SelfManagementErr) Error() string { return "admins can't deactivate or delete themselves" }
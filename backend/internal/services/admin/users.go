@@ -0,0 +1,151 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	groupservice "github.com/berk-karaal/letuspass/backend/internal/services/group"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromoteAdmins gives the admin role to the users with given emails who verified their email address. It is used to
+// bootstrap the admins from the configuration, so anyone who registers with one of the emails can't become an admin
+// without access to the mailbox. Users with given emails who haven't verified their email address lose the admin role.
+func PromoteAdmins(db *gorm.DB, emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("email IN ? AND email_verified_at IS NULL AND role = ?", emails,
+			models.UserRoleAdmin).Update("role", models.UserRoleUser).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("email IN ? AND email_verified_at IS NOT NULL", emails).
+			Update("role", models.UserRoleAdmin).Error
+	})
+}
+
+// DeactivateUser deactivates given user and revokes all of their sessions, so they lose access immediately. Personal
+// access tokens are kept but can't be used until the user is reactivated.
+func DeactivateUser(db *gorm.DB, admin models.User, userId uint) (models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = lockUser(tx, admin, userId)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{"is_active": false}
+		if user.DeactivatedAt == nil {
+			updates["deactivated_at"] = time.Now()
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserSession{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.LoginChallenge{}).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// ReactivateUser undoes the deactivation of given user. If requireVerifiedEmail is true, users who haven't verified
+// their email address stay inactive until they verify it. Returns UserNotDeactivatedErr if the user isn't deactivated.
+func ReactivateUser(db *gorm.DB, userId uint, requireVerifiedEmail bool) (models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return UserNotFoundErr{}
+			}
+			return err
+		}
+		if user.DeactivatedAt == nil {
+			return UserNotDeactivatedErr{}
+		}
+
+		isActive := !requireVerifiedEmail || user.EmailVerifiedAt != nil
+		return tx.Model(&user).Updates(map[string]any{"is_active": isActive, "deactivated_at": nil}).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// DeleteUser soft-deletes given user after removing them from their vaults and groups and deleting their
+// authentication data. Vaults and groups which don't have any other member are deleted. The email of the deleted user
+// is changed, so the address can be registered again. Vault keys the user shared with others stay decryptable since
+// the public key of the deleted user is kept.
+func DeleteUser(db *gorm.DB, admin models.User, userId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		user, err := lockUser(tx, admin, userId)
+		if err != nil {
+			return err
+		}
+
+		if err := vaultservice.RemoveDeletedUserFromVaults(tx, user, admin); err != nil {
+			return err
+		}
+		if err := groupservice.RemoveDeletedUserFromGroups(tx, user, admin); err != nil {
+			return err
+		}
+
+		for _, model := range []any{&models.UserSession{}, &models.PersonalAccessToken{}, &models.RecoveryKey{},
+			&models.UserRecoveryCode{}, &models.LoginChallenge{}, &models.WebAuthnCredential{},
+			&models.WebAuthnRegistration{}, &models.FailedLogin{}, &models.EmailVerificationToken{}} {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		err = tx.Unscoped().Where("grantor_user_id = ? OR grantee_user_id = ?", user.ID, user.ID).
+			Delete(&models.EmergencyAccess{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("email = ? OR invitee_user_id = ?", user.Email, user.ID).Delete(&models.VaultInvitation{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&user).Updates(map[string]any{
+			"email":        fmt.Sprintf("deleted-%d-%s", user.ID, user.Email),
+			"oidc_subject": nil,
+			"is_active":    false,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+}
+
+// lockUser locks the row of the user with given id until the end of the transaction. Returns SelfManagementErr if the
+// user is the admin themselves.
+func lockUser(tx *gorm.DB, admin models.User, userId uint) (models.User, error) {
+	if admin.ID == userId {
+		return models.User{}, SelfManagementErr{}
+	}
+
+	var user models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, UserNotFoundErr{}
+		}
+		return models.User{}, err
+	}
+	return user, nil
+}
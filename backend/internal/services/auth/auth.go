@@ -8,6 +8,10 @@ import (
 
 const hashingCost = bcrypt.DefaultCost
 
+// dummyPasswordHash is compared against when there is no password to check, so that the response time doesn't
+// reveal whether a user or their password exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), hashingCost)
+
 // HashPassword returns hash value of given rawPassword.
 func HashPassword(rawPassword string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(rawPassword), hashingCost)
@@ -17,8 +21,13 @@ func HashPassword(rawPassword string) (string, error) {
 	return string(hashedPassword), nil
 }
 
-// ComparePassword returns true if hashValue is the hashed version of rawPassword.
+// ComparePassword returns true if hashValue is the hashed version of rawPassword. Users who haven't set a password,
+// like the ones created with OpenID Connect, have an empty hashValue which never matches.
 func ComparePassword(hashValue, rawPassword string) (bool, error) {
+	if hashValue == "" {
+		CompareDummyPassword(rawPassword)
+		return false, nil
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(hashValue), []byte(rawPassword))
 	if err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
@@ -28,3 +37,9 @@ func ComparePassword(hashValue, rawPassword string) (bool, error) {
 	}
 	return true, nil
 }
+
+// CompareDummyPassword takes as long as ComparePassword but doesn't compare rawPassword against any user. It's used
+// when the user with given email doesn't exist.
+func CompareDummyPassword(rawPassword string) {
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(rawPassword))
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB returns a migrated SQLite database which is deleted after the test.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&models.User{}, &models.UserSession{}, &models.UserRecoveryCode{}, &models.LoginChallenge{},
+		&models.WebAuthnCredential{}, &models.WebAuthnRegistration{}, &models.EmailVerificationToken{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// createTestUser creates an active user with given email.
+func createTestUser(t *testing.T, db *gorm.DB, email string) models.User {
+	t.Helper()
+	user := models.User{Email: email, Name: email, Role: models.UserRoleUser, IsActive: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
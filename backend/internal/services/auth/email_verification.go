@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateEmailVerificationToken replaces the email verification tokens of the user with a new token which expires
+// after ttl. Returns the raw token which should be mailed to the user. Returns EmailAlreadyVerifiedErr if the user
+// already verified their email.
+func CreateEmailVerificationToken(db *gorm.DB, user models.User, ttl time.Duration) (string, error) {
+	if user.EmailVerifiedAt != nil {
+		return "", EmailAlreadyVerifiedErr{}
+	}
+
+	rawToken, err := GenerateSecretToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.EmailVerificationToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.EmailVerificationToken{
+			UserID:    user.ID,
+			TokenHash: HashToken(rawToken),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return rawToken, nil
+}
+
+// VerifyEmail marks the email of the user who owns given token as verified and activates the user unless an admin
+// deactivated them. The token can only be used once. Returns EmailVerificationTokenNotFoundErr if the token is invalid
+// or expired.
+func VerifyEmail(db *gorm.DB, rawToken string) (models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var token models.EmailVerificationToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND expires_at > ?", HashToken(rawToken), time.Now()).First(&token).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return EmailVerificationTokenNotFoundErr{}
+			}
+			return err
+		}
+
+		err = tx.Unscoped().Where("user_id = ?", token.UserID).Delete(&models.EmailVerificationToken{}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(map[string]any{
+			"email_verified_at": time.Now(),
+			"is_active":         gorm.Expr("deactivated_at IS NULL"),
+		}).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
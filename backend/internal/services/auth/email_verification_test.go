@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// createUnverifiedTestUser creates an inactive user who didn't verify their email yet.
+func createUnverifiedTestUser(t *testing.T, db *gorm.DB, email string) models.User {
+	t.Helper()
+	user := createTestUser(t, db, email)
+	if err := db.Model(&user).Update("is_active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func reloadTestUser(t *testing.T, db *gorm.DB, user models.User) models.User {
+	t.Helper()
+	var reloaded models.User
+	if err := db.First(&reloaded, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return reloaded
+}
+
+func TestVerifyEmail(t *testing.T) {
+	db := newTestDB(t)
+	user := createUnverifiedTestUser(t, db, "user@example.com")
+
+	token, err := CreateEmailVerificationToken(db, user, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifiedUser, err := VerifyEmail(db, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verifiedUser.ID != user.ID {
+		t.Fatalf("verified user %d, want %d", verifiedUser.ID, user.ID)
+	}
+
+	user = reloadTestUser(t, db, user)
+	if user.EmailVerifiedAt == nil {
+		t.Fatal("email is not marked as verified")
+	}
+	if !user.IsActive {
+		t.Fatal("user is not activated")
+	}
+
+	if _, err := VerifyEmail(db, token); !errors.As(err, &EmailVerificationTokenNotFoundErr{}) {
+		t.Fatalf("reusing the token returned %v, want EmailVerificationTokenNotFoundErr", err)
+	}
+	if _, err := CreateEmailVerificationToken(db, user, time.Hour); !errors.As(err, &EmailAlreadyVerifiedErr{}) {
+		t.Fatalf("creating a token for a verified user returned %v, want EmailAlreadyVerifiedErr", err)
+	}
+}
+
+func TestVerifyEmailExpiredToken(t *testing.T) {
+	db := newTestDB(t)
+	user := createUnverifiedTestUser(t, db, "user@example.com")
+
+	token, err := CreateEmailVerificationToken(db, user, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyEmail(db, token); !errors.As(err, &EmailVerificationTokenNotFoundErr{}) {
+		t.Fatalf("VerifyEmail returned %v, want EmailVerificationTokenNotFoundErr", err)
+	}
+	if reloadTestUser(t, db, user).EmailVerifiedAt != nil {
+		t.Fatal("email is verified with an expired token")
+	}
+}
+
+func TestVerifyEmailReplacedToken(t *testing.T) {
+	db := newTestDB(t)
+	user := createUnverifiedTestUser(t, db, "user@example.com")
+
+	oldToken, err := CreateEmailVerificationToken(db, user, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := CreateEmailVerificationToken(db, user, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyEmail(db, oldToken); !errors.As(err, &EmailVerificationTokenNotFoundErr{}) {
+		t.Fatalf("VerifyEmail with the replaced token returned %v, want EmailVerificationTokenNotFoundErr", err)
+	}
+	if _, err := VerifyEmail(db, newToken); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyEmailDeactivatedUser(t *testing.T) {
+	db := newTestDB(t)
+	user := createUnverifiedTestUser(t, db, "user@example.com")
+	if err := db.Model(&user).Update("deactivated_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := CreateEmailVerificationToken(db, user, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyEmail(db, token); err != nil {
+		t.Fatal(err)
+	}
+
+	user = reloadTestUser(t, db, user)
+	if user.EmailVerifiedAt == nil {
+		t.Fatal("email is not marked as verified")
+	}
+	if user.IsActive {
+		t.Fatal("deactivated user is activated by verifying their email")
+	}
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateEmergencyAccess makes the user with granteeEmail an emergency contact of the grantor. encryptedPrivateKey is
+// the private key of the grantor encrypted with the key pairs of the grantor and the grantee.
+func CreateEmergencyAccess(db *gorm.DB, grantor models.User, granteeEmail string, waitingPeriodDays int, encryptionIV, encryptedPrivateKey string) (models.EmergencyAccess, error) {
+	var access models.EmergencyAccess
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var grantee models.User
+		err := tx.First(&grantee, "email = ?", granteeEmail).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return EmergencyAccessGranteeNotFoundErr{}
+			}
+			return err
+		}
+		if grantee.ID == grantor.ID {
+			return EmergencyAccessSelfGrantErr{}
+		}
+
+		var exists bool
+		err = tx.Unscoped().Model(&models.EmergencyAccess{}).Select("count(*) > 0").
+			Where("grantor_user_id = ? AND grantee_user_id = ?", grantor.ID, grantee.ID).Scan(&exists).Error
+		if err != nil {
+			return err
+		}
+		if exists {
+			return EmergencyAccessAlreadyExistsErr{}
+		}
+
+		access = models.EmergencyAccess{
+			GrantorUserID:       grantor.ID,
+			GranteeUserID:       grantee.ID,
+			WaitingPeriodDays:   waitingPeriodDays,
+			Status:              models.EmergencyAccessStatusIdle,
+			EncryptionIV:        encryptionIV,
+			EncryptedPrivateKey: encryptedPrivateKey,
+			GrantorUser:         grantor,
+			GranteeUser:         grantee,
+		}
+		return tx.Omit(clause.Associations).Create(&access).Error
+	})
+	if err != nil {
+		return models.EmergencyAccess{}, err
+	}
+	return access, nil
+}
+
+// ListEmergencyContacts returns the emergency accesses the user granted to other users.
+func ListEmergencyContacts(db *gorm.DB, grantorId uint) ([]models.EmergencyAccess, error) {
+	var accesses []models.EmergencyAccess
+	err := db.Preload("GranteeUser").Where("grantor_user_id = ?", grantorId).Order("id ASC").Find(&accesses).Error
+	return accesses, err
+}
+
+// ListEmergencyGrants returns the emergency accesses other users granted to the user.
+func ListEmergencyGrants(db *gorm.DB, granteeId uint) ([]models.EmergencyAccess, error) {
+	var accesses []models.EmergencyAccess
+	err := db.Preload("GrantorUser").Where("grantee_user_id = ?", granteeId).Order("id ASC").Find(&accesses).Error
+	return accesses, err
+}
+
+// IsEmergencyAccessGranted returns true if the grantor approved the request of the grantee, or the waiting period
+// passed after the request at given time.
+func IsEmergencyAccessGranted(access models.EmergencyAccess, now time.Time) bool {
+	switch access.Status {
+	case models.EmergencyAccessStatusApproved:
+		return true
+	case models.EmergencyAccessStatusRequested:
+		return access.RequestedAt != nil &&
+			!now.Before(access.RequestedAt.AddDate(0, 0, access.WaitingPeriodDays))
+	}
+	return false
+}
+
+// lockEmergencyAccess locks and returns the emergency access with given id. column is the user id column the user
+// must match, either grantor_user_id or grantee_user_id.
+func lockEmergencyAccess(tx *gorm.DB, column string, userId, accessId uint) (models.EmergencyAccess, error) {
+	var access models.EmergencyAccess
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND "+column+" = ?", accessId, userId).First(&access).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.EmergencyAccess{}, EmergencyAccessNotFoundErr{}
+		}
+		return models.EmergencyAccess{}, err
+	}
+	return access, nil
+}
+
+// DeleteEmergencyAccess deletes the emergency access with given id. Both the grantor and the grantee can delete it.
+func DeleteEmergencyAccess(db *gorm.DB, userId, accessId uint) error {
+	res := db.Unscoped().Where("id = ? AND (grantor_user_id = ? OR grantee_user_id = ?)", accessId, userId, userId).
+		Delete(&models.EmergencyAccess{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return EmergencyAccessNotFoundErr{}
+	}
+	return nil
+}
+
+// RequestEmergencyAccess starts the waiting period of the emergency access granted to the grantee. Returns
+// EmergencyAccessStatusErr if access is already requested.
+func RequestEmergencyAccess(db *gorm.DB, grantee models.User, accessId uint) (models.EmergencyAccess, error) {
+	var access models.EmergencyAccess
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		access, err = lockEmergencyAccess(tx, "grantee_user_id", grantee.ID, accessId)
+		if err != nil {
+			return err
+		}
+		if access.Status != models.EmergencyAccessStatusIdle {
+			return EmergencyAccessStatusErr{Status: access.Status}
+		}
+
+		now := time.Now()
+		access.Status = models.EmergencyAccessStatusRequested
+		access.RequestedAt = &now
+		return tx.Model(&access).Updates(map[string]any{"status": access.Status, "requested_at": now}).Error
+	})
+	if err != nil {
+		return models.EmergencyAccess{}, err
+	}
+	return access, nil
+}
+
+// ApproveEmergencyAccess grants the requested emergency access without waiting for the waiting period to pass.
+func ApproveEmergencyAccess(db *gorm.DB, grantor models.User, accessId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		access, err := lockEmergencyAccess(tx, "grantor_user_id", grantor.ID, accessId)
+		if err != nil {
+			return err
+		}
+		if access.Status != models.EmergencyAccessStatusRequested {
+			return EmergencyAccessStatusErr{Status: access.Status}
+		}
+		return tx.Model(&access).Update("status", models.EmergencyAccessStatusApproved).Error
+	})
+}
+
+// DenyEmergencyAccess rejects the request of the grantee, or revokes the access if it is already granted. The grantee
+// can request access again later.
+func DenyEmergencyAccess(db *gorm.DB, grantor models.User, accessId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		access, err := lockEmergencyAccess(tx, "grantor_user_id", grantor.ID, accessId)
+		if err != nil {
+			return err
+		}
+		if access.Status == models.EmergencyAccessStatusIdle {
+			return EmergencyAccessStatusErr{Status: access.Status}
+		}
+		return tx.Model(&access).Updates(map[string]any{
+			"status":       models.EmergencyAccessStatusIdle,
+			"requested_at": nil,
+		}).Error
+	})
+}
+
+// GetGrantedEmergencyAccess returns the emergency access of the grantee with its grantor. Returns
+// EmergencyAccessNotGrantedErr if the access isn't granted yet.
+func GetGrantedEmergencyAccess(db *gorm.DB, grantee models.User, accessId uint) (models.EmergencyAccess, error) {
+	var access models.EmergencyAccess
+	err := db.Preload("GrantorUser").Where("id = ? AND grantee_user_id = ?", accessId, grantee.ID).
+		First(&access).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.EmergencyAccess{}, EmergencyAccessNotFoundErr{}
+		}
+		return models.EmergencyAccess{}, err
+	}
+	if !IsEmergencyAccessGranted(access, time.Now()) {
+		return models.EmergencyAccess{}, EmergencyAccessNotGrantedErr{}
+	}
+	return access, nil
+}
+
+// TakeOverAccount sets a new password for the grantor of the granted emergency access. The grantee decrypts the
+// private key of the grantor to encrypt the vault keys of the grantor again with the new key pair, see
+// ChangePassword. Every session of the grantor is deleted. Returns the grantor.
+func TakeOverAccount(db *gorm.DB, grantee models.User, accessId uint, newPassword, keyDerivationSalt, publicKey string, privateKey *PrivateKeyEnvelope, vaultKeys []RewrappedVaultKey) (models.User, error) {
+	var grantor models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		access, err := lockEmergencyAccess(tx, "grantee_user_id", grantee.ID, accessId)
+		if err != nil {
+			return err
+		}
+		if !IsEmergencyAccessGranted(access, time.Now()) {
+			return EmergencyAccessNotGrantedErr{}
+		}
+		if err := tx.First(&grantor, access.GrantorUserID).Error; err != nil {
+			return err
+		}
+		return ChangePassword(tx, grantor, 0, newPassword, keyDerivationSalt, publicKey, privateKey, vaultKeys)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return grantor, nil
+}
@@ -0,0 +1,198 @@
+package auth
+
+import "fmt"
+
+// InvalidTOTPCodeErr is returned when given TOTP or recovery code is wrong or already used.
+type InvalidTOTPCodeErr struct{}
+
+func (e InvalidTOTPCodeErr) Error() string { return "invalid two-factor authentication code" }
+
+// TOTPAlreadyEnabledErr is returned when TOTP enrollment is started for a user who already has TOTP enabled.
+type TOTPAlreadyEnabledErr struct{}
+
+func (e TOTPAlreadyEnabledErr) Error() string { return "TOTP is already enabled" }
+
+// TOTPNotEnrolledErr is returned when a TOTP operation requires an enrollment which the user doesn't have.
+type TOTPNotEnrolledErr struct{}
+
+func (e TOTPNotEnrolledErr) Error() string { return "TOTP enrollment not found" }
+
+// LoginChallengeNotFoundErr is returned when the login challenge token is invalid, expired or had too many failed
+// attempts.
+type LoginChallengeNotFoundErr struct{}
+
+func (e LoginChallengeNotFoundErr) Error() string { return "login challenge not found" }
+
+// InvalidWebAuthnResponseErr is returned when the response of the authenticator can't be parsed or verified.
+type InvalidWebAuthnResponseErr struct{}
+
+func (e InvalidWebAuthnResponseErr) Error() string { return "invalid WebAuthn response" }
+
+// WebAuthnRegistrationNotFoundErr is returned when a passkey registration is finished without starting it first or
+// after it expired.
+type WebAuthnRegistrationNotFoundErr struct{}
+
+func (e WebAuthnRegistrationNotFoundErr) Error() string { return "WebAuthn registration not found" }
+
+// WebAuthnCredentialNotFoundErr is returned when the user doesn't have a credential with given id.
+type WebAuthnCredentialNotFoundErr struct{}
+
+func (e WebAuthnCredentialNotFoundErr) Error() string { return "WebAuthn credential not found" }
+
+// WebAuthnCredentialAlreadyExistsErr is returned when the registered credential is already registered.
+type WebAuthnCredentialAlreadyExistsErr struct{}
+
+func (e WebAuthnCredentialAlreadyExistsErr) Error() string { return "WebAuthn credential exists" }
+
+// UserSessionNotFoundErr is returned when the user doesn't have a session with given id.
+type UserSessionNotFoundErr struct{}
+
+func (e UserSessionNotFoundErr) Error() string { return "user session not found" }
+
+// VaultKeyMismatchErr is returned when the re-encrypted vault keys given on password change don't match the vault
+// keys encrypted with the key pair of the user.
+type VaultKeyMismatchErr struct {
+	VaultKeyId uint
+}
+
+func (e VaultKeyMismatchErr) Error() string {
+	return fmt.Sprintf("vault key %d is missing or not expected", e.VaultKeyId)
+}
+
+// UnsupportedPrivateKeyEnvelopeErr is returned when the version of a private key envelope isn't supported.
+type UnsupportedPrivateKeyEnvelopeErr struct {
+	Version int
+}
+
+func (e UnsupportedPrivateKeyEnvelopeErr) Error() string {
+	return fmt.Sprintf("private key envelope version %d is not supported", e.Version)
+}
+
+// RecoveryKeyNotFoundErr is returned when the user doesn't have a recovery key.
+type RecoveryKeyNotFoundErr struct{}
+
+func (e RecoveryKeyNotFoundErr) Error() string { return "recovery key not found" }
+
+// InvalidRecoveryKeyErr is returned when the user with given email doesn't exist, doesn't have a recovery key or the
+// recovery key verifier is wrong.
+type InvalidRecoveryKeyErr struct{}
+
+func (e InvalidRecoveryKeyErr) Error() string { return "invalid email or recovery key" }
+
+// EmergencyAccessNotFoundErr is returned when the user isn't the grantor or the grantee of the emergency access with
+// given id.
+type EmergencyAccessNotFoundErr struct{}
+
+func (e EmergencyAccessNotFoundErr) Error() string { return "emergency access not found" }
+
+// EmergencyAccessAlreadyExistsErr is returned when the grantee is already an emergency contact of the grantor.
+type EmergencyAccessAlreadyExistsErr struct{}
+
+func (e EmergencyAccessAlreadyExistsErr) Error() string { return "emergency access already exists" }
+
+// EmergencyAccessGranteeNotFoundErr is returned when there is no user with the email of the grantee.
+type EmergencyAccessGranteeNotFoundErr struct{}
+
+func (e EmergencyAccessGranteeNotFoundErr) Error() string { return "grantee user not found" }
+
+// EmergencyAccessSelfGrantErr is returned when a user tries to be their own emergency contact.
+type EmergencyAccessSelfGrantErr struct{}
+
+func (e EmergencyAccessSelfGrantErr) Error() string { return "can't grant emergency access to self" }
+
+// EmergencyAccessStatusErr is returned when the emergency access isn't in the status the operation requires, like
+// requesting access which is already requested or approving access which isn't requested.
+type EmergencyAccessStatusErr struct {
+	Status string
+}
+
+func (e EmergencyAccessStatusErr) Error() string {
+	return fmt.Sprintf("emergency access is %s", e.Status)
+}
+
+// EmergencyAccessNotGrantedErr is returned when the grantee tries to take over the account before the access is
+// granted.
+type EmergencyAccessNotGrantedErr struct{}
+
+func (e EmergencyAccessNotGrantedErr) Error() string { return "emergency access not granted yet" }
+
+// PersonalAccessTokenNotFoundErr is returned when the user doesn't have a personal access token with given id.
+type PersonalAccessTokenNotFoundErr struct{}
+
+func (e PersonalAccessTokenNotFoundErr) Error() string { return "personal access token not found" }
+
+// OIDCLoginStateNotFoundErr is returned when the state of the OpenID Connect callback is invalid or expired.
+type OIDCLoginStateNotFoundErr struct{}
+
+func (e OIDCLoginStateNotFoundErr) Error() string { return "OIDC login state not found" }
+
+// InvalidOIDCResponseErr is returned when the authorization code can't be exchanged or the ID token can't be
+// verified.
+type InvalidOIDCResponseErr struct{}
+
+func (e InvalidOIDCResponseErr) Error() string { return "invalid OIDC response" }
+
+// OIDCUserNotFoundErr is returned when no user matches the identity and auto-provisioning isn't allowed for its email,
+// or the identity doesn't have a verified email to match or create a user with.
+type OIDCUserNotFoundErr struct{}
+
+func (e OIDCUserNotFoundErr) Error() string { return "OIDC user not found" }
+
+// OIDCLinkRequiredErr is returned when the identity matches the email of a user it can't be linked to automatically.
+// Token is the token of the link request the user should confirm with ConfirmOIDCLink.
+type OIDCLinkRequiredErr struct {
+	Token string
+}
+
+func (e OIDCLinkRequiredErr) Error() string { return "OIDC identity must be linked by the user" }
+
+// OIDCLinkRequestNotFoundErr is returned when the OIDC link request is invalid, expired or belongs to another user.
+type OIDCLinkRequestNotFoundErr struct{}
+
+func (e OIDCLinkRequestNotFoundErr) Error() string { return "OIDC link request not found" }
+
+// OIDCAlreadyLinkedErr is returned when an identity is linked to a user while either of them is already linked.
+type OIDCAlreadyLinkedErr struct{}
+
+func (e OIDCAlreadyLinkedErr) Error() string { return "OIDC identity or user is already linked" }
+
+// SecondFactorRequiredErr is returned when an operation which requires the second factor of the user is done
+// without it.
+type SecondFactorRequiredErr struct{}
+
+func (e SecondFactorRequiredErr) Error() string { return "second factor is required" }
+
+// KeysAlreadySetUpErr is returned when the keys of a user are set up again.
+type KeysAlreadySetUpErr struct{}
+
+func (e KeysAlreadySetUpErr) Error() string { return "keys are already set up" }
+
+// EmailVerificationTokenNotFoundErr is returned when the email verification token is invalid or expired.
+type EmailVerificationTokenNotFoundErr struct{}
+
+func (e EmailVerificationTokenNotFoundErr) Error() string {
+	return "email verification token not found"
+}
+
+// EmailAlreadyVerifiedErr is returned when a verification mail is requested for an already verified email address.
+type EmailAlreadyVerifiedErr struct{}
+
+func (e EmailAlreadyVerifiedErr) Error() string { return "email is already verified" }
+
+// InviteCodeNotFoundErr is returned when an admin manages an invite code which doesn't exist.
+type InviteCodeNotFoundErr struct{}
+
+func (e InviteCodeNotFoundErr) Error() string { return "invite code not found" }
+
+// InviteCodeAlreadyUsedErr is returned when a used invite code is revoked.
+type InviteCodeAlreadyUsedErr struct{}
+
+func (e InviteCodeAlreadyUsedErr) Error() string { return "invite code is already used" }
+
+// InvalidInviteCodeErr is returned when the invite code given at registration doesn't exist, is expired or is
+// already used.
+type InvalidInviteCodeErr struct{}
+
+func (e InvalidInviteCodeErr) Error() string {
+	return "invite code is invalid, expired or already used"
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateInviteCode creates a single-use invite code issued by given admin which can be used until expiresAt. Returns
+// the created invite code together with its raw value which can't be retrieved again.
+func CreateInviteCode(db *gorm.DB, admin models.User, expiresAt time.Time) (models.InviteCode, string, error) {
+	rawCode, err := GenerateSecretToken()
+	if err != nil {
+		return models.InviteCode{}, "", err
+	}
+
+	inviteCode := models.InviteCode{
+		CodeHash:        HashToken(rawCode),
+		CreatedByUserID: admin.ID,
+		ExpiresAt:       expiresAt,
+	}
+	if err := db.Create(&inviteCode).Error; err != nil {
+		return models.InviteCode{}, "", err
+	}
+	return inviteCode, rawCode, nil
+}
+
+// RevokeInviteCode deletes the invite code with given id. Returns InviteCodeNotFoundErr if it doesn't exist and
+// InviteCodeAlreadyUsedErr if it is already used, used codes are kept to know who invited the user.
+func RevokeInviteCode(db *gorm.DB, inviteCodeId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var inviteCode models.InviteCode
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inviteCode, inviteCodeId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return InviteCodeNotFoundErr{}
+			}
+			return err
+		}
+		if inviteCode.UsedAt != nil {
+			return InviteCodeAlreadyUsedErr{}
+		}
+		return tx.Unscoped().Delete(&inviteCode).Error
+	})
+}
+
+// UseInviteCode marks given invite code as used by the user. It should be called in the transaction which creates the
+// user, so the code isn't used up if the registration fails. Returns InvalidInviteCodeErr if the code doesn't exist,
+// is expired or is already used.
+func UseInviteCode(tx *gorm.DB, rawCode string, userId uint) error {
+	var inviteCode models.InviteCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", HashToken(rawCode), time.Now()).
+		First(&inviteCode).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return InvalidInviteCodeErr{}
+		}
+		return err
+	}
+	return tx.Model(&inviteCode).Updates(map[string]any{"used_by_user_id": userId, "used_at": time.Now()}).Error
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IsUserLocked returns true if the account of the user is locked because of failed logins at given time.
+func IsUserLocked(user models.User, now time.Time) bool {
+	return user.LockedUntil != nil && user.LockedUntil.After(now)
+}
+
+// RecordFailedLogin saves a failed login of the user and increments their consecutive failure count. The account is
+// locked for lockoutDuration when the count reaches lockoutThreshold. Returns true if the account got locked.
+func RecordFailedLogin(db *gorm.DB, user models.User, ipAddress, userAgent string, lockoutThreshold int, lockoutDuration time.Duration) (bool, error) {
+	locked := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		failedLogin := models.FailedLogin{UserID: user.ID, IPAddress: ipAddress, UserAgent: userAgent}
+		if err := tx.Create(&failedLogin).Error; err != nil {
+			return err
+		}
+
+		var lockedUser models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lockedUser, user.ID).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{"failed_login_count": lockedUser.FailedLoginCount + 1}
+		if lockoutThreshold > 0 && lockedUser.FailedLoginCount+1 >= lockoutThreshold {
+			updates["failed_login_count"] = 0
+			updates["locked_until"] = time.Now().Add(lockoutDuration)
+			locked = true
+		}
+		return tx.Model(&lockedUser).Updates(updates).Error
+	})
+	return locked, err
+}
+
+// ResetFailedLoginCount resets the consecutive failure count of the user after a successful login.
+func ResetFailedLoginCount(db *gorm.DB, user models.User) error {
+	if user.FailedLoginCount == 0 {
+		return nil
+	}
+	return db.Model(&user).Update("failed_login_count", 0).Error
+}
@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	oidcLoginStateLifetime  = 10 * time.Minute
+	oidcLinkRequestLifetime = 10 * time.Minute
+)
+
+// OIDCProvider is the OpenID Connect identity provider users can log in with.
+type OIDCProvider struct {
+	oauth2Config oauth2.Config
+	verifier     *oidc.IDTokenVerifier
+}
+
+// NewOIDCProvider discovers the endpoints of the identity provider with given issuer URL.
+func NewOIDCProvider(ctx context.Context, issuerURL, clientID, clientSecret, redirectURL string) (*OIDCProvider, error) {
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCProvider{
+		oauth2Config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: clientID}),
+	}, nil
+}
+
+// oidcClaims are the claims of the ID token used to match the identity to a user.
+type oidcClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// BeginOIDCLogin starts a login with the identity provider. Returns the state which must be sent back to the
+// callback from the same browser and the URL of the identity provider the user should be redirected to.
+func BeginOIDCLogin(db *gorm.DB, provider *OIDCProvider) (string, string, error) {
+	state, err := GenerateSecretToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := GenerateSecretToken()
+	if err != nil {
+		return "", "", err
+	}
+	loginState := models.OIDCLoginState{
+		StateHash:    HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		ExpiresAt:    time.Now().Add(oidcLoginStateLifetime),
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&loginState).Error
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := provider.oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(loginState.CodeVerifier))
+	return state, authURL, nil
+}
+
+// CompleteOIDCLogin exchanges the authorization code given to the callback and returns the user of the identity.
+// Users are matched by the subject of the identity, then by verified email in which case the subject is linked to
+// the user. The subject is linked automatically only if the user has verified their email and has no second factor,
+// since logging in with the identity replaces the password. Otherwise OIDCLinkRequiredErr is returned and the user
+// must confirm the link with ConfirmOIDCLink. The identity never replaces the second factor, the caller must create a
+// login challenge instead of a session if the returned user has one, see GetTwoFactorMethods. A user without keys is created if no user
+// matches and canAutoProvision returns true for the email of the identity, the user sets up their keys after logging
+// in, see SetUpUserKeys.
+func CompleteOIDCLogin(ctx context.Context, db *gorm.DB, provider *OIDCProvider, state, code string, canAutoProvision func(email string) bool) (models.User, error) {
+	var loginState models.OIDCLoginState
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ? AND expires_at > ?", HashToken(state), time.Now()).First(&loginState).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return OIDCLoginStateNotFoundErr{}
+			}
+			return err
+		}
+		return tx.Unscoped().Delete(&loginState).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	token, err := provider.oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(loginState.CodeVerifier))
+	if err != nil {
+		return models.User{}, InvalidOIDCResponseErr{}
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return models.User{}, InvalidOIDCResponseErr{}
+	}
+	idToken, err := provider.verifier.Verify(ctx, rawIDToken)
+	if err != nil || idToken.Nonce != loginState.Nonce {
+		return models.User{}, InvalidOIDCResponseErr{}
+	}
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return models.User{}, InvalidOIDCResponseErr{}
+	}
+
+	var user models.User
+	var linkRequired bool
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&user, "oidc_subject = ?", idToken.Subject).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		// emails which aren't verified by the identity provider could belong to someone else
+		if claims.Email == "" || !claims.EmailVerified {
+			return OIDCUserNotFoundErr{}
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "email = ?", claims.Email).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			// the user is already linked to another identity
+			if user.OIDCSubject != nil {
+				return OIDCUserNotFoundErr{}
+			}
+			// the account may have been registered by someone else with the email, or the user protects the
+			// account with a second factor and should decide whether the identity can replace their password
+			twoFactorMethods, err := GetTwoFactorMethods(tx, user)
+			if err != nil {
+				return err
+			}
+			if user.EmailVerifiedAt == nil || len(twoFactorMethods) > 0 {
+				linkRequired = true
+				return nil
+			}
+			user.OIDCSubject = &idToken.Subject
+			return tx.Model(&user).Update("oidc_subject", idToken.Subject).Error
+		}
+		if !canAutoProvision(claims.Email) {
+			return OIDCUserNotFoundErr{}
+		}
+
+		name := claims.Name
+		if name == "" {
+			name = claims.Email
+		}
+		emailVerifiedAt := time.Now()
+		user = models.User{Email: claims.Email, Name: name, IsActive: true, EmailVerifiedAt: &emailVerifiedAt,
+			OIDCSubject: &idToken.Subject}
+		return tx.Create(&user).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	if linkRequired {
+		token, err := createOIDCLinkRequest(db, user.ID, idToken.Subject)
+		if err != nil {
+			return models.User{}, err
+		}
+		return models.User{}, OIDCLinkRequiredErr{Token: token}
+	}
+	return user, nil
+}
+
+func createOIDCLinkRequest(db *gorm.DB, userId uint, subject string) (string, error) {
+	token, err := GenerateSecretToken()
+	if err != nil {
+		return "", err
+	}
+
+	linkRequest := models.OIDCLinkRequest{
+		TokenHash: HashToken(token),
+		UserID:    userId,
+		Subject:   subject,
+		ExpiresAt: time.Now().Add(oidcLinkRequestLifetime),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("expires_at < ?", time.Now()).Delete(&models.OIDCLinkRequest{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&linkRequest).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConfirmOIDCLink links the identity of the link request with given token to the user. The caller must verify the
+// password of the user. If the user has a second factor, either code must be a TOTP or recovery code, or
+// challengeToken and credential must complete a passkey login challenge of the user, otherwise
+// SecondFactorRequiredErr is returned.
+func ConfirmOIDCLink(db *gorm.DB, wa *webauthn.WebAuthn, user models.User, linkToken, code, challengeToken string, credential []byte) error {
+	twoFactorMethods, err := GetTwoFactorMethods(db, user)
+	if err != nil {
+		return err
+	}
+	verifyTOTP := false
+	if len(twoFactorMethods) > 0 {
+		switch {
+		case code != "" && slices.Contains(twoFactorMethods, TwoFactorMethodTOTP):
+			verifyTOTP = true
+		case challengeToken != "" && slices.Contains(twoFactorMethods, TwoFactorMethodWebAuthn):
+			challengeUser, err := CompleteLoginChallengeWithWebAuthn(db, wa, challengeToken, credential)
+			if err != nil {
+				return err
+			}
+			if challengeUser.ID != user.ID {
+				return InvalidWebAuthnResponseErr{}
+			}
+		default:
+			return SecondFactorRequiredErr{}
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var linkRequest models.OIDCLinkRequest
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND user_id = ? AND expires_at > ?", HashToken(linkToken), user.ID, time.Now()).
+			First(&linkRequest).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return OIDCLinkRequestNotFoundErr{}
+			}
+			return err
+		}
+
+		if verifyTOTP {
+			if err := verifySecondFactor(tx, user, code); err != nil {
+				return err
+			}
+		}
+
+		var linkedCount int64
+		err = tx.Model(&models.User{}).
+			Where("oidc_subject = ? OR (id = ? AND oidc_subject IS NOT NULL)", linkRequest.Subject, user.ID).
+			Count(&linkedCount).Error
+		if err != nil {
+			return err
+		}
+		if linkedCount > 0 {
+			return OIDCAlreadyLinkedErr{}
+		}
+
+		err = tx.Model(&models.User{}).Where("id = ?", user.ID).Update("oidc_subject", linkRequest.Subject).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&linkRequest).Error
+	})
+}
+
+// HasKeys returns true if the user has set up the key pair their vault keys are encrypted with. Users created with
+// OpenID Connect don't have keys until they set them up with SetUpUserKeys.
+func HasKeys(user models.User) bool {
+	return user.PublicKey != ""
+}
+
+// SetUpUserKeys sets the password, the key derivation salt and the key pair of a user who doesn't have keys yet.
+// The password is used for deriving keys on the client and for confirming sensitive operations. Returns
+// KeysAlreadySetUpErr if the user already has keys.
+func SetUpUserKeys(db *gorm.DB, user models.User, password, keyDerivationSalt, publicKey string, privateKey *PrivateKeyEnvelope) error {
+	if privateKey != nil {
+		if err := ValidatePrivateKeyEnvelope(*privateKey); err != nil {
+			return err
+		}
+	}
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var lockedUser models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lockedUser, user.ID).Error
+		if err != nil {
+			return err
+		}
+		if HasKeys(lockedUser) {
+			return KeysAlreadySetUpErr{}
+		}
+
+		updates := privateKeyEnvelopeColumns(privateKey)
+		updates["password"] = hashedPassword
+		updates["key_derivation_salt"] = keyDerivationSalt
+		updates["public_key"] = publicKey
+		return tx.Model(&lockedUser).Updates(updates).Error
+	})
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RewrappedVaultKey is a vault key encrypted again with the key pair derived from the new password.
+type RewrappedVaultKey struct {
+	VaultKeyId        uint
+	EncryptionIV      string
+	EncryptedVaultKey string
+}
+
+// GetUserVaultKeysToRewrap returns the vault keys which are encrypted with the key pair of the user. Those are the
+// vault keys the user owns and the vault keys the user encrypted for other users.
+func GetUserVaultKeysToRewrap(db *gorm.DB, userId uint) (owned, issued []models.VaultKey, err error) {
+	// inviters may be deleted users, their public key is still needed to decrypt the vault key
+	err = db.Preload("InviterUser", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("key_owner_user_id = ?", userId).Order("id ASC").Find(&owned).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	err = db.Preload("KeyOwnerUser").Where("inviter_user_id = ? AND key_owner_user_id != ?", userId, userId).
+		Order("id ASC").Find(&issued).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return owned, issued, nil
+}
+
+// ChangePassword sets the new password of the user together with the new key derivation salt and the public key
+// derived from them. Every vault key returned from GetUserVaultKeysToRewrap must be given in vaultKeys encrypted with
+// the new key pair, vault keys owned by the user are encrypted by the user themselves. privateKey is the new private
+// key encrypted with the new password, the stored private key is cleared if it is nil. The recovery key of the user
+// and the emergency accesses the user is part of are deleted since they are encrypted with the old key pair. Every
+// session of the user except the one with currentSessionId is deleted.
+func ChangePassword(db *gorm.DB, user models.User, currentSessionId uint, newPassword, keyDerivationSalt, publicKey string, privateKey *PrivateKeyEnvelope, vaultKeys []RewrappedVaultKey) error {
+	if privateKey != nil {
+		if err := ValidatePrivateKeyEnvelope(*privateKey); err != nil {
+			return err
+		}
+	}
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var lockedUser models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lockedUser, user.ID).Error
+		if err != nil {
+			return err
+		}
+
+		var currentKeys []models.VaultKey
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key_owner_user_id = ? OR inviter_user_id = ?", user.ID, user.ID).Find(&currentKeys).Error
+		if err != nil {
+			return err
+		}
+		currentKeysById := map[uint]models.VaultKey{}
+		for _, k := range currentKeys {
+			currentKeysById[k.ID] = k
+		}
+		rewrappedIds := map[uint]bool{}
+		for _, k := range vaultKeys {
+			if _, ok := currentKeysById[k.VaultKeyId]; !ok || rewrappedIds[k.VaultKeyId] {
+				return VaultKeyMismatchErr{VaultKeyId: k.VaultKeyId}
+			}
+			rewrappedIds[k.VaultKeyId] = true
+		}
+		for _, k := range currentKeys {
+			if !rewrappedIds[k.ID] {
+				return VaultKeyMismatchErr{VaultKeyId: k.ID}
+			}
+		}
+
+		for _, k := range vaultKeys {
+			updates := map[string]any{"encryption_iv": k.EncryptionIV, "encrypted_vault_key": k.EncryptedVaultKey}
+			if currentKeysById[k.VaultKeyId].KeyOwnerUserID == user.ID {
+				updates["inviter_user_id"] = user.ID
+			}
+			err := tx.Model(&models.VaultKey{}).Where("id = ?", k.VaultKeyId).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		updates := privateKeyEnvelopeColumns(privateKey)
+		updates["password"] = hashedPassword
+		updates["key_derivation_salt"] = keyDerivationSalt
+		updates["public_key"] = publicKey
+		updates["failed_login_count"] = 0
+		updates["locked_until"] = nil
+		err = tx.Model(&lockedUser).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.RecoveryKey{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Where("grantor_user_id = ? OR grantee_user_id = ?", user.ID, user.ID).
+			Delete(&models.EmergencyAccess{}).Error
+		if err != nil {
+			return err
+		}
+
+		_, err = RevokeOtherUserSessions(tx, user.ID, currentSessionId)
+		return err
+	})
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	PasswordRuleMinLength  = "min"
+	PasswordRuleMinEntropy = "min_entropy"
+	PasswordRuleBreached   = "breached"
+)
+
+// breachedHashPrefixLength is the length of the SHA-1 hash prefixes which the breached password hashes are grouped by.
+const breachedHashPrefixLength = 5
+
+// PasswordPolicy is the set of requirements new passwords must meet.
+type PasswordPolicy struct {
+	minLength            int
+	minEntropyBits       int
+	breachedPasswordsDir string
+}
+
+// PasswordPolicyViolation is a requirement of the password policy which the password doesn't meet. Param is the value
+// of the requirement, it is empty for PasswordRuleBreached.
+type PasswordPolicyViolation struct {
+	Rule  string
+	Param string
+}
+
+// NewPasswordPolicy creates a PasswordPolicy. breachedPasswordsDir contains the uppercase SHA-1 hashes of breached
+// passwords grouped by their first 5 characters, like the Pwned Passwords range API: <PREFIX>.txt files contain
+// <SUFFIX>:<COUNT> lines. Passwords are only checked against the files of their own prefix, so the whole dataset is
+// never loaded. Breached password check is disabled if breachedPasswordsDir is empty.
+func NewPasswordPolicy(minLength, minEntropyBits int, breachedPasswordsDir string) (*PasswordPolicy, error) {
+	if breachedPasswordsDir != "" {
+		info, err := os.Stat(breachedPasswordsDir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("breached passwords path %q is not a directory", breachedPasswordsDir)
+		}
+	}
+	return &PasswordPolicy{
+		minLength:            minLength,
+		minEntropyBits:       minEntropyBits,
+		breachedPasswordsDir: breachedPasswordsDir,
+	}, nil
+}
+
+// CheckPassword returns the requirements of the policy which given password doesn't meet.
+func (p *PasswordPolicy) CheckPassword(password string) ([]PasswordPolicyViolation, error) {
+	violations := []PasswordPolicyViolation{}
+	if utf8.RuneCountInString(password) < p.minLength {
+		violations = append(violations, PasswordPolicyViolation{
+			Rule: PasswordRuleMinLength, Param: strconv.Itoa(p.minLength)})
+	}
+	if estimatePasswordEntropy(password) < float64(p.minEntropyBits) {
+		violations = append(violations, PasswordPolicyViolation{
+			Rule: PasswordRuleMinEntropy, Param: strconv.Itoa(p.minEntropyBits)})
+	}
+
+	breached, err := p.isBreached(password)
+	if err != nil {
+		return nil, err
+	}
+	if breached {
+		violations = append(violations, PasswordPolicyViolation{Rule: PasswordRuleBreached})
+	}
+	return violations, nil
+}
+
+// isBreached returns true if the hash of given password is in the breached passwords dataset.
+func (p *PasswordPolicy) isBreached(password string) (bool, error) {
+	if p.breachedPasswordsDir == "" {
+		return false, nil
+	}
+
+	hash := sha1.Sum([]byte(password))
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	prefix, suffix := hexHash[:breachedHashPrefixLength], hexHash[breachedHashPrefixLength:]
+
+	file, err := os.Open(filepath.Join(p.breachedPasswordsDir, prefix+".txt"))
+	if err != nil {
+		// datasets may leave out the prefixes without any breached password
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(lineSuffix, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// estimatePasswordEntropy returns a rough estimate of the entropy of given password in bits, calculated from the
+// size of the character classes it uses. Characters repeating the previous character aren't counted, so "aaaaaaaa"
+// is as weak as "a".
+func estimatePasswordEntropy(password string) float64 {
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+	length := 0
+	var previous rune = -1
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			hasOther = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+		if r != previous {
+			length++
+		}
+		previous = r
+	}
+
+	poolSize := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{hasLower, 26}, {hasUpper, 26}, {hasDigit, 10}, {hasSymbol, 33}, {hasOther, 100}} {
+		if class.used {
+			poolSize += class.size
+		}
+	}
+	if poolSize == 0 {
+		return 0
+	}
+	return float64(length) * math.Log2(float64(poolSize))
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix is prepended to personal access tokens, so they can be told apart from other secrets.
+const PersonalAccessTokenPrefix = "lp_pat_"
+
+// CreatePersonalAccessToken creates a read-only token of the user which can access given vaults until expiresAt.
+// Returns the created token together with its raw value which can't be retrieved again.
+func CreatePersonalAccessToken(db *gorm.DB, user models.User, name string, vaultIds []uint, expiresAt time.Time) (models.PersonalAccessToken, string, error) {
+	secret, err := GenerateSecretToken()
+	if err != nil {
+		return models.PersonalAccessToken{}, "", err
+	}
+	rawToken := PersonalAccessTokenPrefix + secret
+
+	token := models.PersonalAccessToken{
+		UserID:      user.ID,
+		Name:        name,
+		TokenHash:   HashToken(rawToken),
+		TokenPrefix: rawToken[:len(PersonalAccessTokenPrefix)+4],
+		VaultIDs:    vaultIds,
+		ExpiresAt:   expiresAt,
+	}
+	if err := db.Create(&token).Error; err != nil {
+		return models.PersonalAccessToken{}, "", err
+	}
+	return token, rawToken, nil
+}
+
+// ListPersonalAccessTokens returns the personal access tokens of the user including the expired ones.
+func ListPersonalAccessTokens(db *gorm.DB, userId uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := db.Where("user_id = ?", userId).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokePersonalAccessToken deletes the personal access token of the user. Returns PersonalAccessTokenNotFoundErr if
+// the user doesn't have a token with given id.
+func RevokePersonalAccessToken(db *gorm.DB, userId, tokenId uint) error {
+	res := db.Where("id = ? AND user_id = ?", tokenId, userId).Delete(&models.PersonalAccessToken{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return PersonalAccessTokenNotFoundErr{}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PrivateKeyEnvelopeV1 is the private key encrypted with AES-256-GCM using the key derived from the password and
+// KeyDerivationSalt of the user with PBKDF2-SHA256 and 100000 iterations.
+const PrivateKeyEnvelopeV1 = 1
+
+// supportedPrivateKeyEnvelopeVersions are the envelope versions the clients can decrypt. A new version is added
+// when the encryption of the private key changes, so clients can detect private keys which should be encrypted
+// again.
+var supportedPrivateKeyEnvelopeVersions = []int{PrivateKeyEnvelopeV1}
+
+// PrivateKeyEnvelope is the private key of a user encrypted on the client. The server can't decrypt it, it is
+// stored so that the user can get their private key on a new device.
+type PrivateKeyEnvelope struct {
+	Version             int
+	EncryptionIV        string
+	EncryptedPrivateKey string
+}
+
+// ValidatePrivateKeyEnvelope returns UnsupportedPrivateKeyEnvelopeErr if the version of the envelope isn't
+// supported.
+func ValidatePrivateKeyEnvelope(envelope PrivateKeyEnvelope) error {
+	if !slices.Contains(supportedPrivateKeyEnvelopeVersions, envelope.Version) {
+		return UnsupportedPrivateKeyEnvelopeErr{Version: envelope.Version}
+	}
+	return nil
+}
+
+// GetPrivateKeyEnvelope returns the private key envelope of the user, or false if the user hasn't stored one.
+func GetPrivateKeyEnvelope(user models.User) (PrivateKeyEnvelope, bool) {
+	if user.PrivateKeyEnvelopeVersion == 0 {
+		return PrivateKeyEnvelope{}, false
+	}
+	return PrivateKeyEnvelope{
+		Version:             user.PrivateKeyEnvelopeVersion,
+		EncryptionIV:        user.PrivateKeyEncryptionIV,
+		EncryptedPrivateKey: user.EncryptedPrivateKey,
+	}, true
+}
+
+// SetPrivateKeyEnvelope stores the private key envelope of the user.
+func SetPrivateKeyEnvelope(db *gorm.DB, user models.User, envelope PrivateKeyEnvelope) error {
+	if err := ValidatePrivateKeyEnvelope(envelope); err != nil {
+		return err
+	}
+	return db.Model(&user).Updates(privateKeyEnvelopeColumns(&envelope)).Error
+}
+
+// privateKeyEnvelopeColumns returns the user columns to store given envelope. The stored envelope is cleared if
+// envelope is nil.
+func privateKeyEnvelopeColumns(envelope *PrivateKeyEnvelope) map[string]any {
+	if envelope == nil {
+		return map[string]any{"private_key_envelope_version": 0, "private_key_encryption_iv": "", "encrypted_private_key": ""}
+	}
+	return map[string]any{
+		"private_key_envelope_version": envelope.Version,
+		"private_key_encryption_iv":    envelope.EncryptionIV,
+		"encrypted_private_key":        envelope.EncryptedPrivateKey,
+	}
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetRecoveryKey returns the recovery key of the user, or RecoveryKeyNotFoundErr if the user doesn't have one.
+func GetRecoveryKey(db *gorm.DB, userId uint) (models.RecoveryKey, error) {
+	var recoveryKey models.RecoveryKey
+	err := db.Where("user_id = ?", userId).First(&recoveryKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.RecoveryKey{}, RecoveryKeyNotFoundErr{}
+		}
+		return models.RecoveryKey{}, err
+	}
+	return recoveryKey, nil
+}
+
+// SetRecoveryKey stores the private key of the user encrypted with a new recovery key, replacing the previous
+// recovery key of the user. verifier is hashed before it's stored.
+func SetRecoveryKey(db *gorm.DB, user models.User, verifier string, privateKey PrivateKeyEnvelope) (models.RecoveryKey, error) {
+	if err := ValidatePrivateKeyEnvelope(privateKey); err != nil {
+		return models.RecoveryKey{}, err
+	}
+	verifierHash, err := HashPassword(verifier)
+	if err != nil {
+		return models.RecoveryKey{}, err
+	}
+
+	recoveryKey := models.RecoveryKey{
+		UserID:              user.ID,
+		VerifierHash:        verifierHash,
+		EnvelopeVersion:     privateKey.Version,
+		EncryptionIV:        privateKey.EncryptionIV,
+		EncryptedPrivateKey: privateKey.EncryptedPrivateKey,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.RecoveryKey{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&recoveryKey).Error
+	})
+	if err != nil {
+		return models.RecoveryKey{}, err
+	}
+	return recoveryKey, nil
+}
+
+// DeleteRecoveryKey deletes the recovery key of the user. Returns RecoveryKeyNotFoundErr if the user doesn't have
+// one.
+func DeleteRecoveryKey(db *gorm.DB, userId uint) error {
+	res := db.Unscoped().Where("user_id = ?", userId).Delete(&models.RecoveryKey{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return RecoveryKeyNotFoundErr{}
+	}
+	return nil
+}
+
+// VerifyRecoveryKey returns the user with given email and their recovery key if verifier matches the recovery key.
+// InvalidRecoveryKeyErr is returned if the user doesn't exist, doesn't have a recovery key or verifier is wrong.
+func VerifyRecoveryKey(db *gorm.DB, email, verifier string) (models.User, models.RecoveryKey, error) {
+	var user models.User
+	err := db.First(&user, "email = ?", email).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, models.RecoveryKey{}, InvalidRecoveryKeyErr{}
+		}
+		return models.User{}, models.RecoveryKey{}, err
+	}
+
+	recoveryKey, err := GetRecoveryKey(db, user.ID)
+	if err != nil {
+		if errors.Is(err, RecoveryKeyNotFoundErr{}) {
+			return models.User{}, models.RecoveryKey{}, InvalidRecoveryKeyErr{}
+		}
+		return models.User{}, models.RecoveryKey{}, err
+	}
+
+	ok, err := ComparePassword(recoveryKey.VerifierHash, verifier)
+	if err != nil {
+		return models.User{}, models.RecoveryKey{}, err
+	}
+	if !ok {
+		return models.User{}, models.RecoveryKey{}, InvalidRecoveryKeyErr{}
+	}
+	return user, recoveryKey, nil
+}
+
+// RecoverAccount sets a new password for the user with given email if verifier matches their recovery key. The
+// client decrypts the private key stored with the recovery key to encrypt the vault keys again with the new key
+// pair, see ChangePassword. Every session of the user is deleted.
+func RecoverAccount(db *gorm.DB, email, verifier, newPassword, keyDerivationSalt, publicKey string, privateKey *PrivateKeyEnvelope, vaultKeys []RewrappedVaultKey) (models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// lock the user first, so the recovery key can't be used again by a concurrent recovery
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("email = ?", email).Find(&models.User{}).Error
+		if err != nil {
+			return err
+		}
+		user, _, err = VerifyRecoveryKey(tx, email, verifier)
+		if err != nil {
+			return err
+		}
+		return ChangePassword(tx, user, 0, newPassword, keyDerivationSalt, publicKey, privateKey, vaultKeys)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
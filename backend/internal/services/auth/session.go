@@ -1,9 +1,39 @@
 package auth
 
 import (
+	"github.com/berk-karaal/letuspass/backend/internal/models"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func GenerateSessionToken() string {
 	return uuid.NewString()
 }
+
+// RevokeUserSession deletes the session of the user with given id.
+func RevokeUserSession(db *gorm.DB, userId, sessionId uint) error {
+	res := db.Where("id = ? AND user_id = ?", sessionId, userId).Delete(&models.UserSession{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return UserSessionNotFoundErr{}
+	}
+	return nil
+}
+
+// RevokeOtherUserSessions deletes every session of the user except the one with currentSessionId. Returns the number
+// of deleted sessions.
+func RevokeOtherUserSessions(db *gorm.DB, userId, currentSessionId uint) (int64, error) {
+	res := db.Where("user_id = ? AND id != ?", userId, currentSessionId).Delete(&models.UserSession{})
+	return res.RowsAffected, res.Error
+}
+
+// IsNewLoginDevice returns true if none of the sessions of the user were created from given IP address and user
+// agent.
+func IsNewLoginDevice(db *gorm.DB, userId uint, ip, userAgent string) (bool, error) {
+	var exists bool
+	err := db.Model(&models.UserSession{}).Select("count(*) > 0").
+		Where("user_id = ? AND last_seen_ip = ? AND user_agent = ?", userId, ip, userAgent).Scan(&exists).Error
+	return !exists, err
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpIssuer = "LetusPass"
+	totpPeriod = 30
+	// totpSkew is the number of periods before and after the current one in which a code is still accepted.
+	totpSkew = 1
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+// GenerateTOTPSecret returns a new TOTP secret for given account and its otpauth:// provisioning URI which
+// authenticator apps can read from a QR code.
+func GenerateTOTPSecret(accountName string) (secret, provisioningURI string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+		Algorithm:   otp.AlgorithmSHA1,
+		Digits:      otp.DigitsSix,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return key.Secret(), key.URL(), nil
+}
+
+// ValidateTOTPCode checks given code against the secret at given time. If the code is valid, it returns the time
+// step counter the code belongs to so that callers can reject codes which are already used.
+func ValidateTOTPCode(secret, code string, now time.Time) (counter int64, ok bool) {
+	if secret == "" {
+		return 0, false
+	}
+	currentCounter := now.Unix() / totpPeriod
+	for c := currentCounter - totpSkew; c <= currentCounter+totpSkew; c++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(c*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Algorithm: otp.AlgorithmSHA1,
+			Digits:    otp.DigitsSix,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns random single use codes which can be used instead of a TOTP code. Only the hashes of
+// the codes should be stored, see HashToken.
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		codes[i] = strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)[:recoveryCodeLength])
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode removes the characters users may add while typing a recovery code so that it matches the
+// hash of the generated code.
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TwoFactorMethodTOTP is the second factor method which accepts TOTP and recovery codes.
+const TwoFactorMethodTOTP = "totp"
+
+const (
+	loginChallengeLifetime       = 5 * time.Minute
+	loginChallengeMaxFailedTries = 5
+)
+
+// StartTOTPEnrollment generates a new TOTP secret for the user. TOTP isn't required on login until the enrollment is
+// confirmed with ConfirmTOTPEnrollment. Returns the secret and its provisioning URI.
+func StartTOTPEnrollment(db *gorm.DB, user models.User) (secret, provisioningURI string, err error) {
+	if user.TOTPEnabled {
+		return "", "", TOTPAlreadyEnabledErr{}
+	}
+
+	secret, provisioningURI, err = GenerateTOTPSecret(user.Email)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = db.Model(&user).Updates(map[string]any{"totp_secret": secret, "totp_last_counter": 0}).Error
+	if err != nil {
+		return "", "", err
+	}
+	return secret, provisioningURI, nil
+}
+
+// ConfirmTOTPEnrollment enables TOTP for the user if given code is generated with the secret of the enrollment.
+// Returns the recovery codes of the user, they can't be retrieved later.
+func ConfirmTOTPEnrollment(db *gorm.DB, user models.User, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, TOTPAlreadyEnabledErr{}
+	}
+	if user.TOTPSecret == "" {
+		return nil, TOTPNotEnrolledErr{}
+	}
+
+	counter, ok := ValidateTOTPCode(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, InvalidTOTPCodeErr{}
+	}
+
+	var recoveryCodes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]any{"totp_enabled": true, "totp_last_counter": counter}).Error
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// DisableTOTP removes the TOTP secret and the recovery codes of the user after verifying given TOTP or recovery
+// code.
+func DisableTOTP(db *gorm.DB, user models.User, code string) error {
+	if !user.TOTPEnabled {
+		return TOTPNotEnrolledErr{}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := verifySecondFactor(tx, user, code); err != nil {
+			return err
+		}
+
+		err := tx.Model(&user).
+			Updates(map[string]any{"totp_enabled": false, "totp_secret": "", "totp_last_counter": 0}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.UserRecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user with new ones after verifying given TOTP or
+// recovery code.
+func RegenerateRecoveryCodes(db *gorm.DB, user models.User, code string) ([]string, error) {
+	if !user.TOTPEnabled {
+		return nil, TOTPNotEnrolledErr{}
+	}
+
+	var recoveryCodes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := verifySecondFactor(tx, user, code); err != nil {
+			return err
+		}
+
+		var err error
+		recoveryCodes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// CreateLoginChallenge creates a login challenge for the user who passed the password check. methods are the second
+// factor methods of the user, see GetTwoFactorMethods. Expired challenges of the user are deleted. Returns the
+// challenge token which should be sent back with the second factor, and the options which should be passed to the
+// authenticator if the user has a passkey.
+func CreateLoginChallenge(db *gorm.DB, wa *webauthn.WebAuthn, user models.User, methods []string) (string, *protocol.CredentialAssertion, error) {
+	err := db.Unscoped().Where("user_id = ? AND expires_at <= ?", user.ID, time.Now()).
+		Delete(&models.LoginChallenge{}).Error
+	if err != nil {
+		return "", nil, err
+	}
+
+	var assertion *protocol.CredentialAssertion
+	var session *webauthn.SessionData
+	if slices.Contains(methods, TwoFactorMethodWebAuthn) {
+		waUser, err := getWebAuthnUser(db, user)
+		if err != nil {
+			return "", nil, err
+		}
+		assertion, session, err = wa.BeginLogin(waUser)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	token, err := createLoginChallenge(db, user.ID, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, assertion, nil
+}
+
+// CompleteLoginChallengeWithTOTP verifies given TOTP or recovery code for the login challenge and deletes the
+// challenge on success. The challenge is deleted after too many failed attempts. Returns the user of the challenge.
+func CompleteLoginChallengeWithTOTP(db *gorm.DB, token, code string) (models.User, error) {
+	return completeLoginChallenge(db, token, func(tx *gorm.DB, challenge models.LoginChallenge) (models.User, error) {
+		if challenge.UserID == 0 {
+			return models.User{}, LoginChallengeNotFoundErr{}
+		}
+		var user models.User
+		if err := tx.First(&user, challenge.UserID).Error; err != nil {
+			return models.User{}, err
+		}
+		if !user.TOTPEnabled {
+			return models.User{}, InvalidTOTPCodeErr{}
+		}
+		if err := verifySecondFactor(tx, user, code); err != nil {
+			return models.User{}, err
+		}
+		return user, nil
+	})
+}
+
+// GetLoginChallengeUser returns the user of the login challenge with given token without completing the challenge.
+// Returns LoginChallengeNotFoundErr if the token is invalid or expired, or it belongs to a passwordless login.
+func GetLoginChallengeUser(db *gorm.DB, token string) (models.User, error) {
+	var challenge models.LoginChallenge
+	err := db.Where("token_hash = ? AND expires_at > ?", HashToken(token), time.Now()).First(&challenge).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, LoginChallengeNotFoundErr{}
+		}
+		return models.User{}, err
+	}
+	if challenge.UserID == 0 {
+		return models.User{}, LoginChallengeNotFoundErr{}
+	}
+
+	var user models.User
+	if err := db.First(&user, challenge.UserID).Error; err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// PurgeExpiredLoginChallenges permanently deletes the login challenges which expired before given time, including the
+// ones of passwordless logins which are never completed. Returns the number of deleted challenges.
+func PurgeExpiredLoginChallenges(db *gorm.DB, expiredBefore time.Time) (int64, error) {
+	res := db.Unscoped().Where("expires_at <= ?", expiredBefore).Delete(&models.LoginChallenge{})
+	return res.RowsAffected, res.Error
+}
+
+func createLoginChallenge(db *gorm.DB, userId uint, session *webauthn.SessionData) (string, error) {
+	token, err := GenerateSecretToken()
+	if err != nil {
+		return "", err
+	}
+
+	challenge := models.LoginChallenge{
+		TokenHash: HashToken(token),
+		UserID:    userId,
+		ExpiresAt: time.Now().Add(loginChallengeLifetime),
+	}
+	if session != nil {
+		sessionData, err := json.Marshal(session)
+		if err != nil {
+			return "", err
+		}
+		challenge.WebAuthnSession = datatypes.JSON(sessionData)
+	}
+	if err := db.Create(&challenge).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// completeLoginChallenge locks the login challenge with given token and calls verify with it. The challenge is
+// deleted if verify succeeds. If verify fails because of a wrong second factor, the failed attempt is saved and the
+// challenge is deleted after too many failed attempts.
+func completeLoginChallenge(db *gorm.DB, token string, verify func(tx *gorm.DB, challenge models.LoginChallenge) (models.User, error)) (models.User, error) {
+	var user models.User
+	var verifyErr error
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var challenge models.LoginChallenge
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND expires_at > ?", HashToken(token), time.Now()).First(&challenge).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return LoginChallengeNotFoundErr{}
+			}
+			return err
+		}
+
+		user, verifyErr = verify(tx, challenge)
+		if verifyErr != nil {
+			if !errors.Is(verifyErr, InvalidTOTPCodeErr{}) && !errors.Is(verifyErr, InvalidWebAuthnResponseErr{}) {
+				return verifyErr
+			}
+			// failed attempt is saved, so the transaction is committed and the error is returned afterwards
+			challenge.FailedAttempts++
+			if challenge.FailedAttempts >= loginChallengeMaxFailedTries {
+				return tx.Unscoped().Delete(&challenge).Error
+			}
+			return tx.Model(&challenge).Update("failed_attempts", challenge.FailedAttempts).Error
+		}
+
+		return tx.Unscoped().Delete(&challenge).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	if verifyErr != nil {
+		return models.User{}, verifyErr
+	}
+	return user, nil
+}
+
+// verifySecondFactor checks given code as a TOTP code first and as a recovery code if it's not a valid TOTP code.
+// Accepted TOTP codes and used recovery codes can't be used again.
+func verifySecondFactor(tx *gorm.DB, user models.User, code string) error {
+	counter, ok := ValidateTOTPCode(user.TOTPSecret, code, time.Now())
+	if ok {
+		// conditional update makes concurrent requests with the same code accept it only once
+		res := tx.Model(&models.User{}).Where("id = ? AND totp_last_counter < ?", user.ID, counter).
+			Update("totp_last_counter", counter)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return InvalidTOTPCodeErr{}
+		}
+		return nil
+	}
+
+	res := tx.Unscoped().Where("user_id = ? AND code_hash = ?", user.ID, HashToken(NormalizeRecoveryCode(code))).
+		Delete(&models.UserRecoveryCode{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return InvalidTOTPCodeErr{}
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userId uint) ([]string, error) {
+	err := tx.Unscoped().Where("user_id = ?", userId).Delete(&models.UserRecoveryCode{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]models.UserRecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = models.UserRecoveryCode{UserID: userId, CodeHash: HashToken(code)}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
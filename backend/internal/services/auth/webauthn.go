@@ -0,0 +1,324 @@
+package auth
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// TwoFactorMethodWebAuthn is the second factor method which accepts passkeys and security keys.
+const TwoFactorMethodWebAuthn = "webauthn"
+
+const webAuthnRegistrationLifetime = 5 * time.Minute
+
+// NewWebAuthn creates the WebAuthn relying party which is used on passkey registrations and logins.
+func NewWebAuthn(rpId, rpDisplayName string, rpOrigins []string) (*webauthn.WebAuthn, error) {
+	return webauthn.New(&webauthn.Config{
+		RPID:          rpId,
+		RPDisplayName: rpDisplayName,
+		RPOrigins:     rpOrigins,
+	})
+}
+
+// webAuthnUser adapts a user and their credentials to webauthn.User.
+type webAuthnUser struct {
+	user        models.User
+	credentials []models.WebAuthnCredential
+}
+
+func (u webAuthnUser) WebAuthnID() []byte {
+	return webAuthnUserHandle(u.user.ID)
+}
+
+func (u webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Name
+}
+
+func (u webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.credentials))
+	for i, c := range u.credentials {
+		transports := make([]protocol.AuthenticatorTransport, len(c.Transports))
+		for j, t := range c.Transports {
+			transports[j] = protocol.AuthenticatorTransport(t)
+		}
+		credentials[i] = webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: c.BackupEligible,
+				BackupState:    c.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:       c.AAGUID,
+				SignCount:    c.SignCount,
+				CloneWarning: c.CloneWarning,
+				Attachment:   protocol.AuthenticatorAttachment(c.Attachment),
+			},
+		}
+	}
+	return credentials
+}
+
+// webAuthnUserHandle returns the user handle of the user which is stored on the authenticator. It is the user id so
+// it doesn't contain any personal information.
+func webAuthnUserHandle(userId uint) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(userId))
+}
+
+func getWebAuthnUser(db *gorm.DB, user models.User) (webAuthnUser, error) {
+	var credentials []models.WebAuthnCredential
+	err := db.Where("user_id = ?", user.ID).Order("id ASC").Find(&credentials).Error
+	if err != nil {
+		return webAuthnUser{}, err
+	}
+	return webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+// GetTwoFactorMethods returns the second factor methods the user has set up. Login requires one of them if the list
+// isn't empty.
+func GetTwoFactorMethods(db *gorm.DB, user models.User) ([]string, error) {
+	methods := []string{}
+	if user.TOTPEnabled {
+		methods = append(methods, TwoFactorMethodTOTP)
+	}
+
+	var credentialCount int64
+	err := db.Model(&models.WebAuthnCredential{}).Where("user_id = ?", user.ID).Count(&credentialCount).Error
+	if err != nil {
+		return nil, err
+	}
+	if credentialCount > 0 {
+		methods = append(methods, TwoFactorMethodWebAuthn)
+	}
+	return methods, nil
+}
+
+// BeginWebAuthnRegistration starts registering a new passkey for the user. Previously started registration of the
+// user is discarded. Returns the options which should be passed to the authenticator.
+func BeginWebAuthnRegistration(db *gorm.DB, wa *webauthn.WebAuthn, user models.User) (*protocol.CredentialCreation, error) {
+	waUser, err := getWebAuthnUser(db, user)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, len(waUser.credentials))
+	for i, c := range waUser.WebAuthnCredentials() {
+		exclusions[i] = c.Descriptor()
+	}
+	creation, session, err := wa.BeginRegistration(waUser,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred))
+	if err != nil {
+		return nil, err
+	}
+	sessionData, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.WebAuthnRegistration{}).Error
+		if err != nil {
+			return err
+		}
+		registration := models.WebAuthnRegistration{
+			UserID:      user.ID,
+			SessionData: datatypes.JSON(sessionData),
+			ExpiresAt:   time.Now().Add(webAuthnRegistrationLifetime),
+		}
+		return tx.Create(&registration).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// FinishWebAuthnRegistration verifies the response of the authenticator to the registration started with
+// BeginWebAuthnRegistration and saves the new credential with given name.
+func FinishWebAuthnRegistration(db *gorm.DB, wa *webauthn.WebAuthn, user models.User, name string, response []byte) (models.WebAuthnCredential, error) {
+	var credential models.WebAuthnCredential
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var registration models.WebAuthnRegistration
+		err := tx.Where("user_id = ? AND expires_at > ?", user.ID, time.Now()).First(&registration).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return WebAuthnRegistrationNotFoundErr{}
+			}
+			return err
+		}
+		if err := tx.Unscoped().Delete(&registration).Error; err != nil {
+			return err
+		}
+
+		var session webauthn.SessionData
+		if err := json.Unmarshal(registration.SessionData, &session); err != nil {
+			return err
+		}
+		waUser, err := getWebAuthnUser(tx, user)
+		if err != nil {
+			return err
+		}
+
+		parsedResponse, err := protocol.ParseCredentialCreationResponseBytes(response)
+		if err != nil {
+			return InvalidWebAuthnResponseErr{}
+		}
+		created, err := wa.CreateCredential(waUser, session, parsedResponse)
+		if err != nil {
+			return InvalidWebAuthnResponseErr{}
+		}
+
+		var existingCount int64
+		err = tx.Unscoped().Model(&models.WebAuthnCredential{}).Where("credential_id = ?", created.ID).
+			Count(&existingCount).Error
+		if err != nil {
+			return err
+		}
+		if existingCount > 0 {
+			return WebAuthnCredentialAlreadyExistsErr{}
+		}
+
+		transports := make([]string, len(created.Transport))
+		for i, t := range created.Transport {
+			transports[i] = string(t)
+		}
+		credential = models.WebAuthnCredential{
+			UserID:          user.ID,
+			Name:            name,
+			CredentialID:    created.ID,
+			PublicKey:       created.PublicKey,
+			AttestationType: created.AttestationType,
+			Transports:      transports,
+			AAGUID:          created.Authenticator.AAGUID,
+			SignCount:       created.Authenticator.SignCount,
+			Attachment:      string(created.Authenticator.Attachment),
+			BackupEligible:  created.Flags.BackupEligible,
+			BackupState:     created.Flags.BackupState,
+		}
+		return tx.Create(&credential).Error
+	})
+	if err != nil {
+		return models.WebAuthnCredential{}, err
+	}
+	return credential, nil
+}
+
+// DeleteWebAuthnCredential permanently deletes the credential of the user so it can be registered again later.
+func DeleteWebAuthnCredential(db *gorm.DB, user models.User, credentialId uint) error {
+	res := db.Unscoped().Where("id = ? AND user_id = ?", credentialId, user.ID).Delete(&models.WebAuthnCredential{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return WebAuthnCredentialNotFoundErr{}
+	}
+	return nil
+}
+
+// BeginPasskeyLogin starts a passwordless login with a passkey stored on the authenticator. The user is identified
+// by the passkey, so user verification is required by the authenticator. Returns the login challenge token and the
+// options which should be passed to the authenticator.
+func BeginPasskeyLogin(db *gorm.DB, wa *webauthn.WebAuthn) (string, *protocol.CredentialAssertion, error) {
+	assertion, session, err := wa.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := createLoginChallenge(db, 0, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, assertion, nil
+}
+
+// CompleteLoginChallengeWithWebAuthn verifies the response of the authenticator for the login challenge and deletes
+// the challenge on success. Both second factor and passwordless login challenges are accepted. The challenge is
+// deleted after too many failed attempts. Returns the user of the challenge.
+func CompleteLoginChallengeWithWebAuthn(db *gorm.DB, wa *webauthn.WebAuthn, token string, response []byte) (models.User, error) {
+	return completeLoginChallenge(db, token, func(tx *gorm.DB, challenge models.LoginChallenge) (models.User, error) {
+		if challenge.WebAuthnSession == nil {
+			return models.User{}, LoginChallengeNotFoundErr{}
+		}
+		var session webauthn.SessionData
+		if err := json.Unmarshal(challenge.WebAuthnSession, &session); err != nil {
+			return models.User{}, err
+		}
+
+		parsedResponse, err := protocol.ParseCredentialRequestResponseBytes(response)
+		if err != nil {
+			return models.User{}, InvalidWebAuthnResponseErr{}
+		}
+
+		var waUser webAuthnUser
+		var credential *webauthn.Credential
+		if challenge.UserID == 0 {
+			var userErr error
+			handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+				if len(userHandle) != 8 {
+					return nil, InvalidWebAuthnResponseErr{}
+				}
+				var user models.User
+				userErr = tx.First(&user, binary.BigEndian.Uint64(userHandle)).Error
+				if userErr != nil {
+					return nil, userErr
+				}
+				waUser, userErr = getWebAuthnUser(tx, user)
+				return waUser, userErr
+			}
+			credential, err = wa.ValidateDiscoverableLogin(handler, session, parsedResponse)
+			if userErr != nil && !errors.Is(userErr, gorm.ErrRecordNotFound) {
+				return models.User{}, userErr
+			}
+		} else {
+			var user models.User
+			if err := tx.First(&user, challenge.UserID).Error; err != nil {
+				return models.User{}, err
+			}
+			waUser, err = getWebAuthnUser(tx, user)
+			if err != nil {
+				return models.User{}, err
+			}
+			credential, err = wa.ValidateLogin(waUser, session, parsedResponse)
+		}
+		if err != nil {
+			return models.User{}, InvalidWebAuthnResponseErr{}
+		}
+
+		now := time.Now()
+		res := tx.Model(&models.WebAuthnCredential{}).
+			Where("user_id = ? AND credential_id = ?", waUser.user.ID, credential.ID).
+			Updates(map[string]any{
+				"sign_count":    credential.Authenticator.SignCount,
+				"clone_warning": credential.Authenticator.CloneWarning,
+				"backup_state":  credential.Flags.BackupState,
+				"last_used_at":  now,
+			})
+		if res.Error != nil {
+			return models.User{}, res.Error
+		}
+		// credential may be deleted while the login is in progress
+		if res.RowsAffected == 0 {
+			return models.User{}, InvalidWebAuthnResponseErr{}
+		}
+		// sign count going backwards means the credential may be cloned, so it isn't trusted anymore
+		if credential.Authenticator.CloneWarning {
+			return models.User{}, InvalidWebAuthnResponseErr{}
+		}
+		return waUser.user, nil
+	})
+}
@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+const (
+	testRPID   = "letuspass.test"
+	testOrigin = "https://letuspass.test"
+)
+
+// softAuthenticator is a software authenticator with a single ES256 credential. It answers the ceremonies the way a
+// browser and a platform authenticator would, without any attestation.
+type softAuthenticator struct {
+	t            *testing.T
+	credentialId []byte
+	key          *ecdsa.PrivateKey
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialId := make([]byte, 16)
+	if _, err := rand.Read(credentialId); err != nil {
+		t.Fatal(err)
+	}
+	return &softAuthenticator{t: t, credentialId: credentialId, key: key}
+}
+
+// create returns the credential creation response for given registration options.
+func (a *softAuthenticator) create(options *protocol.CredentialCreation, userId uint) []byte {
+	a.t.Helper()
+	a.userHandle = webAuthnUserHandle(userId)
+
+	coseKey, err := cbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	authData := a.authenticatorData(protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagAttestedCredentialData)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialId)))
+	authData = append(authData, a.credentialId...)
+	authData = append(authData, coseKey...)
+
+	attestationObject, err := cbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return a.marshalCredential(map[string]string{
+		"clientDataJSON":    encodeBase64(a.clientData("webauthn.create", options.Response.Challenge)),
+		"attestationObject": encodeBase64(attestationObject),
+	})
+}
+
+// get returns the assertion response for given login options, signed with the next sign count.
+func (a *softAuthenticator) get(options *protocol.CredentialAssertion) []byte {
+	a.t.Helper()
+	a.signCount++
+
+	clientData := a.clientData("webauthn.get", options.Response.Challenge)
+	authData := a.authenticatorData(protocol.FlagUserPresent | protocol.FlagUserVerified)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return a.marshalCredential(map[string]string{
+		"clientDataJSON":    encodeBase64(clientData),
+		"authenticatorData": encodeBase64(authData),
+		"signature":         encodeBase64(signature),
+		"userHandle":        encodeBase64(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) authenticatorData(flags protocol.AuthenticatorFlags) []byte {
+	rpIdHash := sha256.Sum256([]byte(testRPID))
+	authData := append(rpIdHash[:], byte(flags))
+	return binary.BigEndian.AppendUint32(authData, a.signCount)
+}
+
+func (a *softAuthenticator) clientData(ceremony string, challenge protocol.URLEncodedBase64) []byte {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge.String(),
+		"origin":    testOrigin,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return clientData
+}
+
+func (a *softAuthenticator) marshalCredential(response map[string]string) []byte {
+	credential, err := json.Marshal(map[string]any{
+		"id":       encodeBase64(a.credentialId),
+		"rawId":    encodeBase64(a.credentialId),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return credential
+}
+
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newTestWebAuthn(t *testing.T) *webauthn.WebAuthn {
+	t.Helper()
+	wa, err := NewWebAuthn(testRPID, "LetusPass", []string{testOrigin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wa
+}
+
+// registerSoftAuthenticator registers a new software authenticator for the user and returns it.
+func registerSoftAuthenticator(t *testing.T, db *gorm.DB, wa *webauthn.WebAuthn, user models.User) *softAuthenticator {
+	t.Helper()
+	authenticator := newSoftAuthenticator(t)
+	options, err := BeginWebAuthnRegistration(db, wa, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = FinishWebAuthnRegistration(db, wa, user, "soft key", authenticator.create(options, user.ID))
+	if err != nil {
+		t.Fatalf("finishing registration failed: %v", err)
+	}
+	return authenticator
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	db := newTestDB(t)
+	wa := newTestWebAuthn(t)
+	user := createTestUser(t, db, "user@letuspass.test")
+
+	authenticator := registerSoftAuthenticator(t, db, wa, user)
+
+	var credential models.WebAuthnCredential
+	if err := db.First(&credential, "user_id = ?", user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if string(credential.CredentialID) != string(authenticator.credentialId) || credential.Name != "soft key" {
+		t.Errorf("unexpected credential %+v", credential)
+	}
+	methods, err := GetTwoFactorMethods(db, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 1 || methods[0] != TwoFactorMethodWebAuthn {
+		t.Errorf("expected webauthn method, got %v", methods)
+	}
+
+	// the registration can only be finished once
+	_, err = FinishWebAuthnRegistration(db, wa, user, "soft key", authenticator.create(&protocol.CredentialCreation{}, user.ID))
+	if !errors.Is(err, WebAuthnRegistrationNotFoundErr{}) {
+		t.Errorf("expected WebAuthnRegistrationNotFoundErr, got %v", err)
+	}
+
+	// the same credential can't be registered twice
+	options, err := BeginWebAuthnRegistration(db, wa, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = FinishWebAuthnRegistration(db, wa, user, "copy", authenticator.create(options, user.ID))
+	if !errors.Is(err, WebAuthnCredentialAlreadyExistsErr{}) {
+		t.Errorf("expected WebAuthnCredentialAlreadyExistsErr, got %v", err)
+	}
+}
+
+func TestWebAuthnRegistrationRejectsWrongChallenge(t *testing.T) {
+	db := newTestDB(t)
+	wa := newTestWebAuthn(t)
+	user := createTestUser(t, db, "user@letuspass.test")
+
+	if _, err := BeginWebAuthnRegistration(db, wa, user); err != nil {
+		t.Fatal(err)
+	}
+	options := &protocol.CredentialCreation{}
+	options.Response.Challenge = protocol.URLEncodedBase64("not the challenge")
+	_, err := FinishWebAuthnRegistration(db, wa, user, "soft key", newSoftAuthenticator(t).create(options, user.ID))
+	if !errors.Is(err, InvalidWebAuthnResponseErr{}) {
+		t.Errorf("expected InvalidWebAuthnResponseErr, got %v", err)
+	}
+}
+
+func TestWebAuthnSecondFactorLogin(t *testing.T) {
+	db := newTestDB(t)
+	wa := newTestWebAuthn(t)
+	user := createTestUser(t, db, "user@letuspass.test")
+	authenticator := registerSoftAuthenticator(t, db, wa, user)
+
+	token, options, err := CreateLoginChallenge(db, wa, user, []string{TwoFactorMethodWebAuthn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options == nil {
+		t.Fatal("expected webauthn options")
+	}
+	response := authenticator.get(options)
+
+	loggedInUser, err := CompleteLoginChallengeWithWebAuthn(db, wa, token, response)
+	if err != nil {
+		t.Fatalf("completing login failed: %v", err)
+	}
+	if loggedInUser.ID != user.ID {
+		t.Errorf("expected user %d, got %d", user.ID, loggedInUser.ID)
+	}
+
+	var credential models.WebAuthnCredential
+	if err := db.First(&credential, "user_id = ?", user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if credential.SignCount != 1 || credential.LastUsedAt == nil {
+		t.Errorf("expected sign count and last use to be updated, got %+v", credential)
+	}
+
+	// the challenge is deleted after the login
+	_, err = CompleteLoginChallengeWithWebAuthn(db, wa, token, response)
+	if !errors.Is(err, LoginChallengeNotFoundErr{}) {
+		t.Errorf("expected LoginChallengeNotFoundErr, got %v", err)
+	}
+}
+
+func TestWebAuthnSecondFactorLoginRejectsOtherUsersCredential(t *testing.T) {
+	db := newTestDB(t)
+	wa := newTestWebAuthn(t)
+	user := createTestUser(t, db, "user@letuspass.test")
+	registerSoftAuthenticator(t, db, wa, user)
+	otherUser := createTestUser(t, db, "other@letuspass.test")
+	otherAuthenticator := registerSoftAuthenticator(t, db, wa, otherUser)
+
+	token, options, err := CreateLoginChallenge(db, wa, user, []string{TwoFactorMethodWebAuthn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CompleteLoginChallengeWithWebAuthn(db, wa, token, otherAuthenticator.get(options))
+	if !errors.Is(err, InvalidWebAuthnResponseErr{}) {
+		t.Errorf("expected InvalidWebAuthnResponseErr, got %v", err)
+	}
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	db := newTestDB(t)
+	wa := newTestWebAuthn(t)
+	user := createTestUser(t, db, "user@letuspass.test")
+	authenticator := registerSoftAuthenticator(t, db, wa, user)
+
+	token, options, err := BeginPasskeyLogin(db, wa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loggedInUser, err := CompleteLoginChallengeWithWebAuthn(db, wa, token, authenticator.get(options))
+	if err != nil {
+		t.Fatalf("completing passkey login failed: %v", err)
+	}
+	if loggedInUser.ID != user.ID {
+		t.Errorf("expected user %d, got %d", user.ID, loggedInUser.ID)
+	}
+
+	// passwordless challenges can't be completed with a TOTP code
+	token, _, err = BeginPasskeyLogin(db, wa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CompleteLoginChallengeWithTOTP(db, token, "123456")
+	if !errors.Is(err, LoginChallengeNotFoundErr{}) {
+		t.Errorf("expected LoginChallengeNotFoundErr, got %v", err)
+	}
+}
+
+func TestPasskeyLoginRejectsClonedCredential(t *testing.T) {
+	db := newTestDB(t)
+	wa := newTestWebAuthn(t)
+	user := createTestUser(t, db, "user@letuspass.test")
+	authenticator := registerSoftAuthenticator(t, db, wa, user)
+
+	authenticator.signCount = 4
+	token, options, err := BeginPasskeyLogin(db, wa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CompleteLoginChallengeWithWebAuthn(db, wa, token, authenticator.get(options)); err != nil {
+		t.Fatalf("completing passkey login failed: %v", err)
+	}
+
+	// a copy of the credential signs with a sign count which isn't greater than the last one
+	authenticator.signCount = 2
+	token, options, err = BeginPasskeyLogin(db, wa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CompleteLoginChallengeWithWebAuthn(db, wa, token, authenticator.get(options))
+	if !errors.Is(err, InvalidWebAuthnResponseErr{}) {
+		t.Errorf("expected InvalidWebAuthnResponseErr, got %v", err)
+	}
+
+	var credential models.WebAuthnCredential
+	if err := db.First(&credential, "user_id = ?", user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !credential.CloneWarning {
+		t.Error("expected clone warning to be saved")
+	}
+
+	// the credential isn't trusted anymore even if the sign count moves forward again
+	authenticator.signCount = 10
+	token, options, err = BeginPasskeyLogin(db, wa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CompleteLoginChallengeWithWebAuthn(db, wa, token, authenticator.get(options))
+	if !errors.Is(err, InvalidWebAuthnResponseErr{}) {
+		t.Errorf("expected InvalidWebAuthnResponseErr, got %v", err)
+	}
+}
+
+func TestPurgeExpiredLoginChallenges(t *testing.T) {
+	db := newTestDB(t)
+	wa := newTestWebAuthn(t)
+
+	if _, _, err := BeginPasskeyLogin(db, wa); err != nil {
+		t.Fatal(err)
+	}
+
+	purgedCount, err := PurgeExpiredLoginChallenges(db, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purgedCount != 0 {
+		t.Errorf("expected no challenge to be purged before it expires, purged %d", purgedCount)
+	}
+
+	purgedCount, err = PurgeExpiredLoginChallenges(db, time.Now().Add(loginChallengeLifetime))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purgedCount != 1 {
+		t.Errorf("expected the expired challenge to be purged, purged %d", purgedCount)
+	}
+}
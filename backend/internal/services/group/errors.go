@@ -0,0 +1,26 @@
+package group
+
+// GroupNotFoundErr is returned when the group an operation targets doesn't exist.
+type GroupNotFoundErr struct{}
+
+func (e GroupNotFoundErr) Error() string { return "group not found" }
+
+// UserNotFoundErr is returned when the user an operation targets doesn't exist.
+type UserNotFoundErr struct{}
+
+func (e UserNotFoundErr) Error() string { return "user not found" }
+
+// UserAlreadyGroupMemberErr is returned when a user is added to a group which they are already member of.
+type UserAlreadyGroupMemberErr struct{}
+
+func (e UserAlreadyGroupMemberErr) Error() string { return "user is already a member of the group" }
+
+// UserNotGroupMemberErr is returned when an operation targets a user who is not a member of the group.
+type UserNotGroupMemberErr struct{}
+
+func (e UserNotGroupMemberErr) Error() string { return "user is not a member of the group" }
+
+// LastGroupManagerErr is returned when an operation would leave the group without any manager.
+type LastGroupManagerErr struct{}
+
+func (e LastGroupManagerErr) Error() string { return "group must have at least one manager" }
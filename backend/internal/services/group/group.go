@@ -0,0 +1,238 @@
+package group
+
+import (
+	"errors"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	vaultservice "github.com/berk-karaal/letuspass/backend/internal/services/vault"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CheckUserIsGroupMember returns whether given user is a member of given group and whether they are a manager of
+// the group.
+func CheckUserIsGroupMember(db *gorm.DB, userId, groupId uint) (isMember, isManager bool, err error) {
+	var members []models.GroupMember
+	err = db.Where("group_id = ? AND user_id = ?", groupId, userId).Limit(1).Find(&members).Error
+	if err != nil || len(members) == 0 {
+		return false, false, err
+	}
+	return true, members[0].IsManager, nil
+}
+
+// CreateGroup creates a new group and adds its creator to the group as a manager.
+func CreateGroup(db *gorm.DB, creator models.User, name string) (models.Group, error) {
+	group := models.Group{Name: name}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		member := models.GroupMember{GroupID: group.ID, UserID: creator.ID, IsManager: true}
+		return tx.Create(&member).Error
+	})
+	return group, err
+}
+
+// DeleteGroup soft deletes the group with its memberships and vault permissions. Vault keys of the members who
+// don't have access to those vaults anymore are deleted. Returns vault.LastVaultManagerErr if the group members are
+// the last managers of a vault. manager is the user who deletes the group.
+func DeleteGroup(db *gorm.DB, groupId uint, manager models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		group, err := lockGroup(tx, groupId)
+		if err != nil {
+			return err
+		}
+
+		var memberIds []uint
+		err = tx.Model(&models.GroupMember{}).Where("group_id = ?", group.ID).Pluck("user_id", &memberIds).Error
+		if err != nil {
+			return err
+		}
+		vaultIds, err := getGroupVaultIds(tx, group.ID)
+		if err != nil {
+			return err
+		}
+		managedVaultIds, err := getGroupManagedVaultIds(tx, group.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.VaultGroupPermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&group).Error; err != nil {
+			return err
+		}
+		for _, vaultId := range managedVaultIds {
+			if err := vaultservice.EnsureVaultHasManager(tx, vaultId); err != nil {
+				return err
+			}
+		}
+
+		if err := vaultservice.RevokeOrphanVaultKeys(tx, vaultIds, memberIds); err != nil {
+			return err
+		}
+
+		for _, vaultId := range vaultIds {
+			auditLog := models.VaultAuditLog{
+				VaultID:     vaultId,
+				VaultItemID: 0,
+				UserID:      manager.ID,
+				ActionCode:  models.AuditLogActionVaultRemoveGroup,
+				ActionData:  models.AuditLogDataVaultRemoveGroup(group.Name),
+			}
+			if err := tx.Create(&auditLog).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddGroupMember adds the user with given email to the group. The new member doesn't get vault keys of the vaults
+// the group has access to, those are listed as pending until a manager of the vault shares the vault key.
+func AddGroupMember(db *gorm.DB, groupId uint, email string, isManager bool) (models.User, error) {
+	var newMember models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		group, err := lockGroup(tx, groupId)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("email = ?", email).First(&newMember).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return UserNotFoundErr{}
+			}
+			return err
+		}
+
+		isMember, _, err := CheckUserIsGroupMember(tx, newMember.ID, group.ID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			return UserAlreadyGroupMemberErr{}
+		}
+
+		member := models.GroupMember{GroupID: group.ID, UserID: newMember.ID, IsManager: isManager}
+		return tx.Create(&member).Error
+	})
+	return newMember, err
+}
+
+// RemoveGroupMember removes given user from the group. Vault keys of the user on the vaults they don't have access
+// to anymore are deleted. It refuses to remove the last manager of the group, and returns vault.LastVaultManagerErr
+// if the user is the last manager of a vault through the group.
+func RemoveGroupMember(db *gorm.DB, groupId, userId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		group, err := lockGroup(tx, groupId)
+		if err != nil {
+			return err
+		}
+
+		isMember, isManager, err := CheckUserIsGroupMember(tx, userId, group.ID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return UserNotGroupMemberErr{}
+		}
+
+		if isManager {
+			var otherManagerCount int64
+			err = tx.Model(&models.GroupMember{}).
+				Where("group_id = ? AND user_id != ? AND is_manager = ?", group.ID, userId, true).
+				Count(&otherManagerCount).Error
+			if err != nil {
+				return err
+			}
+			if otherManagerCount == 0 {
+				return LastGroupManagerErr{}
+			}
+		}
+
+		err = tx.Where("group_id = ? AND user_id = ?", group.ID, userId).Delete(&models.GroupMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		managedVaultIds, err := getGroupManagedVaultIds(tx, group.ID)
+		if err != nil {
+			return err
+		}
+		for _, vaultId := range managedVaultIds {
+			if err := vaultservice.EnsureVaultHasManager(tx, vaultId); err != nil {
+				return err
+			}
+		}
+
+		vaultIds, err := getGroupVaultIds(tx, group.ID)
+		if err != nil {
+			return err
+		}
+		return vaultservice.RevokeOrphanVaultKeys(tx, vaultIds, []uint{userId})
+	})
+}
+
+// RemoveDeletedUserFromGroups removes given user from every group before the user is deleted. Groups which don't
+// have any other member are deleted. Returns LastGroupManagerErr if the user is the last manager of a group which has
+// other members. admin is the user who deletes the user.
+func RemoveDeletedUserFromGroups(tx *gorm.DB, user, admin models.User) error {
+	groupIds := []uint{}
+	err := tx.Model(&models.GroupMember{}).Where("user_id = ?", user.ID).Pluck("group_id", &groupIds).Error
+	if err != nil {
+		return err
+	}
+
+	for _, groupId := range groupIds {
+		var otherMemberCount int64
+		err := tx.Model(&models.GroupMember{}).Where("group_id = ? AND user_id != ?", groupId, user.ID).
+			Count(&otherMemberCount).Error
+		if err != nil {
+			return err
+		}
+		if otherMemberCount == 0 {
+			err = DeleteGroup(tx, groupId, admin)
+		} else {
+			err = RemoveGroupMember(tx, groupId, user.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockGroup fetches the group with given id and locks its row until the end of the transaction so membership
+// changes of the same group are serialized.
+func lockGroup(tx *gorm.DB, groupId uint) (models.Group, error) {
+	var group models.Group
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&group, groupId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Group{}, GroupNotFoundErr{}
+		}
+		return models.Group{}, err
+	}
+	return group, nil
+}
+
+func getGroupVaultIds(db *gorm.DB, groupId uint) ([]uint, error) {
+	vaultIds := []uint{}
+	err := db.Model(&models.VaultGroupPermission{}).Distinct("vault_id").Where("group_id = ?", groupId).
+		Pluck("vault_id", &vaultIds).Error
+	return vaultIds, err
+}
+
+// getGroupManagedVaultIds returns the ids of the vaults which the group has the manage_vault permission on.
+func getGroupManagedVaultIds(db *gorm.DB, groupId uint) ([]uint, error) {
+	vaultIds := []uint{}
+	err := db.Model(&models.VaultGroupPermission{}).Distinct("vault_id").
+		Where("group_id = ? AND permission = ?", groupId, models.VaultPermissionManageVault).
+		Pluck("vault_id", &vaultIds).Error
+	return vaultIds, err
+}
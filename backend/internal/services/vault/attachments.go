@@ -0,0 +1,159 @@
+package vault
+
+import (
+	"errors"
+	"io"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common/blobstore"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"gorm.io/gorm"
+)
+
+// attachmentPurgeBatchSize is the maximum number of attachments PurgeDeletedAttachments deletes in a single run.
+const attachmentPurgeBatchSize = 500
+
+// purgeableAttachmentsCondition matches the deleted attachments whose blobs can be deleted. Attachments which were
+// deleted together with their vault item are kept while the item is in the trash, so they come back if the item is
+// restored.
+const purgeableAttachmentsCondition = `vault_item_attachments.deleted_at IS NOT NULL AND NOT EXISTS (
+	SELECT 1 FROM vault_items WHERE vault_items.id = vault_item_attachments.vault_item_id
+	AND vault_items.deleted_at IS NOT NULL AND vault_items.deleted_at <= vault_item_attachments.deleted_at)`
+
+// UploadAttachment streams content into the blob store and attaches it to given vault item. content is read until
+// it ends or exceeds maxSize or the remaining attachment quota of the vault, in which case AttachmentTooLargeErr or
+// AttachmentQuotaExceededErr is returned. The quota is checked again while the vault is locked so concurrent uploads
+// can't exceed it together. Returns VaultItemNotFoundErr if the item is deleted during the upload.
+func UploadAttachment(db *gorm.DB, store blobstore.BlobStore, vaultItem models.VaultItem, uploader models.User,
+	encryptionIV, encryptedMetadata string, content io.Reader, maxSize, vaultQuota int64,
+) (models.VaultItemAttachment, error) {
+	usedSize, err := VaultAttachmentsSize(db, vaultItem.VaultID)
+	if err != nil {
+		return models.VaultItemAttachment{}, err
+	}
+	limit := max(min(maxSize, vaultQuota-usedSize), 0)
+
+	blobKey, err := authservice.GenerateSecretToken()
+	if err != nil {
+		return models.VaultItemAttachment{}, err
+	}
+	// read one more byte than the limit to tell whether the content exceeds it
+	size, err := store.Put(blobKey, io.LimitReader(content, limit+1))
+	if err != nil {
+		return models.VaultItemAttachment{}, errors.Join(err, store.Delete(blobKey))
+	}
+	if size > limit {
+		err = AttachmentQuotaExceededErr{Quota: vaultQuota}
+		if limit == maxSize {
+			err = AttachmentTooLargeErr{MaxSize: maxSize}
+		}
+		return models.VaultItemAttachment{}, errors.Join(err, store.Delete(blobKey))
+	}
+
+	attachment := models.VaultItemAttachment{
+		VaultID:           vaultItem.VaultID,
+		VaultItemID:       vaultItem.ID,
+		UploaderUserID:    uploader.ID,
+		BlobKey:           blobKey,
+		Size:              size,
+		EncryptionIV:      encryptionIV,
+		EncryptedMetadata: encryptedMetadata,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultItem.VaultID); err != nil {
+			return err
+		}
+
+		// the item may have been moved to the trash while the content was being uploaded
+		var itemCount int64
+		err := tx.Model(&models.VaultItem{}).Where("id = ? AND deleted_at IS NULL", vaultItem.ID).Count(&itemCount).Error
+		if err != nil {
+			return err
+		}
+		if itemCount == 0 {
+			return VaultItemNotFoundErr{}
+		}
+
+		usedSize, err := VaultAttachmentsSize(tx, vaultItem.VaultID)
+		if err != nil {
+			return err
+		}
+		if usedSize+size > vaultQuota {
+			return AttachmentQuotaExceededErr{Quota: vaultQuota}
+		}
+
+		if err := tx.Create(&attachment).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultItem.VaultID,
+			VaultItemID: vaultItem.ID,
+			UserID:      uploader.ID,
+			ActionCode:  models.AuditLogActionVaultItemAttachmentAdd,
+			ActionData:  models.AuditLogDataVaultItemAttachmentAdd(vaultItem.Title, attachment.ID, size),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		return models.VaultItemAttachment{}, errors.Join(err, store.Delete(blobKey))
+	}
+	return attachment, nil
+}
+
+// VaultAttachmentsSize returns the total size of the attachments of given vault in bytes. Attachments of the items
+// in the trash are not counted.
+func VaultAttachmentsSize(db *gorm.DB, vaultId uint) (int64, error) {
+	var size int64
+	err := db.Model(&models.VaultItemAttachment{}).Where("vault_id = ?", vaultId).
+		Select("COALESCE(SUM(size), 0)").Scan(&size).Error
+	return size, err
+}
+
+// DeleteAttachment soft-deletes given attachment of the vault item. Its blob is deleted later by
+// PurgeDeletedAttachments.
+func DeleteAttachment(db *gorm.DB, vaultItem models.VaultItem, user models.User, attachmentId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND vault_item_id = ?", attachmentId, vaultItem.ID).Delete(&models.VaultItemAttachment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return AttachmentNotFoundErr{}
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultItem.VaultID,
+			VaultItemID: vaultItem.ID,
+			UserID:      user.ID,
+			ActionCode:  models.AuditLogActionVaultItemAttachmentDelete,
+			ActionData:  models.AuditLogDataVaultItemAttachmentDelete(vaultItem.Title, attachmentId),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// PurgeDeletedAttachments deletes the blobs of the deleted attachments and then permanently deletes the attachments.
+// Attachments of the vault items in the trash are skipped until the items are permanently deleted. Returns the
+// number of purged attachments.
+func PurgeDeletedAttachments(db *gorm.DB, store blobstore.BlobStore) (int64, error) {
+	var attachments []models.VaultItemAttachment
+	err := db.Unscoped().Where(purgeableAttachmentsCondition).Limit(attachmentPurgeBatchSize).
+		Find(&attachments).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var purgedCount int64
+	for _, attachment := range attachments {
+		// the blob is deleted first, the attachment is picked up again by the next run if deleting it fails
+		if err := store.Delete(attachment.BlobKey); err != nil {
+			return purgedCount, err
+		}
+		if err := db.Unscoped().Delete(&attachment).Error; err != nil {
+			return purgedCount, err
+		}
+		purgedCount++
+	}
+	return purgedCount, nil
+}
@@ -0,0 +1,187 @@
+package vault
+
+import "fmt"
+
+// VaultNotFoundErr is returned when the vault an operation targets doesn't exist.
+type VaultNotFoundErr struct{}
+
+func (e VaultNotFoundErr) Error() string { return "vault not found" }
+
+// VaultItemNotFoundErr is returned when the vault item an operation targets doesn't exist or is in the trash.
+type VaultItemNotFoundErr struct{}
+
+func (e VaultItemNotFoundErr) Error() string { return "vault item not found" }
+
+// UserNotFoundErr is returned when the user an operation targets doesn't exist.
+type UserNotFoundErr struct{}
+
+func (e UserNotFoundErr) Error() string { return "user not found" }
+
+// UserAlreadyVaultMemberErr is returned when a user is added to a vault which they already have access to.
+type UserAlreadyVaultMemberErr struct{}
+
+func (e UserAlreadyVaultMemberErr) Error() string { return "user is already a member of the vault" }
+
+// UserNotVaultMemberErr is returned when an operation targets a user who doesn't have access to the vault.
+type UserNotVaultMemberErr struct{}
+
+func (e UserNotVaultMemberErr) Error() string { return "user is not a member of the vault" }
+
+// InvalidPermissionErr is returned when a permission which can't be granted to a vault member is given.
+type InvalidPermissionErr struct {
+	Permission string
+}
+
+func (e InvalidPermissionErr) Error() string {
+	return fmt.Sprintf("invalid permission '%s'", e.Permission)
+}
+
+// LastVaultManagerErr is returned when an operation would leave the vault without any user who has the
+// manage_vault permission.
+type LastVaultManagerErr struct{}
+
+func (e LastVaultManagerErr) Error() string { return "vault must have at least one manager" }
+
+// VaultOwnerErr is returned when an operation which isn't allowed on the vault owner, like leaving the vault or
+// dropping the manage_vault permission, targets the owner.
+type VaultOwnerErr struct{}
+
+func (e VaultOwnerErr) Error() string { return "operation is not allowed on the vault owner" }
+
+// NotVaultOwnerErr is returned when an operation which only the vault owner can do is done by another user.
+type NotVaultOwnerErr struct{}
+
+func (e NotVaultOwnerErr) Error() string { return "user is not the owner of the vault" }
+
+// VaultChangedErr is returned when the members or the items of a vault don't match the ones an operation was
+// prepared for, usually because they were changed concurrently.
+type VaultChangedErr struct{}
+
+func (e VaultChangedErr) Error() string { return "vault members or items changed" }
+
+// DuplicateEntryErr is returned when the same user or item is given more than once.
+type DuplicateEntryErr struct{}
+
+func (e DuplicateEntryErr) Error() string { return "duplicate entry" }
+
+// GroupNotFoundErr is returned when the group an operation targets doesn't exist.
+type GroupNotFoundErr struct{}
+
+func (e GroupNotFoundErr) Error() string { return "group not found" }
+
+// NotGroupManagerErr is returned when a user who isn't a manager of the group tries to give the group access to a
+// vault.
+type NotGroupManagerErr struct{}
+
+func (e NotGroupManagerErr) Error() string { return "user is not a manager of the group" }
+
+// GroupAlreadyVaultMemberErr is returned when a group is given access to a vault which it already has access to.
+type GroupAlreadyVaultMemberErr struct{}
+
+func (e GroupAlreadyVaultMemberErr) Error() string { return "group already has access to the vault" }
+
+// GroupNotVaultMemberErr is returned when an operation targets a group which doesn't have access to the vault.
+type GroupNotVaultMemberErr struct{}
+
+func (e GroupNotVaultMemberErr) Error() string { return "group doesn't have access to the vault" }
+
+// VaultKeyNotPendingErr is returned when a vault key is given for a user who either doesn't have access to the vault
+// or already has a vault key.
+type VaultKeyNotPendingErr struct {
+	UserId uint
+}
+
+func (e VaultKeyNotPendingErr) Error() string {
+	return fmt.Sprintf("vault key of user %d is not pending", e.UserId)
+}
+
+// InvitationNotFoundErr is returned when the vault invitation an operation targets doesn't exist.
+type InvitationNotFoundErr struct{}
+
+func (e InvitationNotFoundErr) Error() string { return "vault invitation not found" }
+
+// InvitationAlreadyExistsErr is returned when an email address is invited to a vault which it already has an open
+// invitation to.
+type InvitationAlreadyExistsErr struct{}
+
+func (e InvitationAlreadyExistsErr) Error() string { return "email is already invited to the vault" }
+
+// InvitationNotAcceptedErr is returned when a vault invitation is completed before the invitee accepts it.
+type InvitationNotAcceptedErr struct{}
+
+func (e InvitationNotAcceptedErr) Error() string { return "vault invitation is not accepted yet" }
+
+// InvitationAlreadyAcceptedErr is returned when a vault invitation which is already accepted is accepted or declined.
+type InvitationAlreadyAcceptedErr struct{}
+
+func (e InvitationAlreadyAcceptedErr) Error() string { return "vault invitation is already accepted" }
+
+// EmailNotVerifiedErr is returned when a user who didn't verify their email address accepts or declines a vault
+// invitation sent to it.
+type EmailNotVerifiedErr struct{}
+
+func (e EmailNotVerifiedErr) Error() string { return "email address is not verified" }
+
+// InvalidExpiryErr is returned when the given expiry time of a vault access or a share link is not in the future, or
+// a share link would live longer than allowed.
+type InvalidExpiryErr struct{}
+
+func (e InvalidExpiryErr) Error() string { return "expiry time is invalid" }
+
+// SecretShareNotFoundErr is returned when the secret share an operation targets doesn't exist, is expired or has
+// been viewed as many times as it's allowed.
+type SecretShareNotFoundErr struct{}
+
+func (e SecretShareNotFoundErr) Error() string { return "secret share not found" }
+
+// InvalidVaultItemTypeErr is returned when a vault item is given a type which doesn't exist.
+type InvalidVaultItemTypeErr struct {
+	Type string
+}
+
+func (e InvalidVaultItemTypeErr) Error() string {
+	return fmt.Sprintf("invalid vault item type '%s'", e.Type)
+}
+
+// UnsupportedVaultItemDataErr is returned when the version of a vault item data isn't supported.
+type UnsupportedVaultItemDataErr struct {
+	Version int
+}
+
+func (e UnsupportedVaultItemDataErr) Error() string {
+	return fmt.Sprintf("vault item data version %d is not supported", e.Version)
+}
+
+// VaultItemDataMissingErr is returned when the vault key is rotated without re-encrypting the data of a vault item
+// which has one.
+type VaultItemDataMissingErr struct {
+	VaultItemId uint
+}
+
+func (e VaultItemDataMissingErr) Error() string {
+	return fmt.Sprintf("data of vault item %d must be re-encrypted", e.VaultItemId)
+}
+
+// AttachmentNotFoundErr is returned when the vault item attachment an operation targets doesn't exist.
+type AttachmentNotFoundErr struct{}
+
+func (e AttachmentNotFoundErr) Error() string { return "attachment not found" }
+
+// AttachmentTooLargeErr is returned when an uploaded attachment is larger than the maximum attachment size.
+type AttachmentTooLargeErr struct {
+	MaxSize int64
+}
+
+func (e AttachmentTooLargeErr) Error() string {
+	return fmt.Sprintf("attachment is larger than %d bytes", e.MaxSize)
+}
+
+// AttachmentQuotaExceededErr is returned when an uploaded attachment or the attachments of a restored vault item
+// don't fit in the attachment quota of the vault.
+type AttachmentQuotaExceededErr struct {
+	Quota int64
+}
+
+func (e AttachmentQuotaExceededErr) Error() string {
+	return fmt.Sprintf("vault attachments exceed the quota of %d bytes", e.Quota)
+}
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"errors"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// RevokeExpiredVaultAccess deletes the vault permissions which expired before given time and the vault keys of the
+// users who don't have access to those vaults anymore. Each revoked access is recorded in the audit log of the
+// vault. Returns the number of revoked accesses.
+func RevokeExpiredVaultAccess(db *gorm.DB, now time.Time) (revokedCount int64, err error) {
+	var expiredAccesses []struct {
+		VaultID uint
+		UserID  uint
+	}
+	err = db.Model(&models.VaultPermission{}).Distinct("vault_id", "user_id").
+		Where("expires_at <= ?", now).Scan(&expiredAccesses).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, access := range expiredAccesses {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if _, err := lockVault(tx, access.VaultID); err != nil {
+				return err
+			}
+
+			var user models.User
+			if err := tx.First(&user, access.UserID).Error; err != nil {
+				return err
+			}
+
+			var permissions []string
+			err := tx.Model(&models.VaultPermission{}).
+				Where("vault_id = ? AND user_id = ? AND expires_at <= ?", access.VaultID, access.UserID, now).
+				Order("permission ASC").Pluck("permission", &permissions).Error
+			if err != nil {
+				return err
+			}
+			if len(permissions) == 0 {
+				// permissions were changed after they were queried
+				return nil
+			}
+
+			err = tx.Where("vault_id = ? AND user_id = ? AND expires_at <= ?", access.VaultID, access.UserID, now).
+				Delete(&models.VaultPermission{}).Error
+			if err != nil {
+				return err
+			}
+
+			if err := RevokeOrphanVaultKeys(tx, []uint{access.VaultID}, []uint{access.UserID}); err != nil {
+				return err
+			}
+			// vault key is kept if the user still has access through a group, it shouldn't expire in that case
+			err = tx.Model(&models.VaultKey{}).Where("vault_id = ? AND key_owner_user_id = ?", access.VaultID, access.UserID).
+				Update("expires_at", nil).Error
+			if err != nil {
+				return err
+			}
+
+			auditLog := models.VaultAuditLog{
+				VaultID:     access.VaultID,
+				VaultItemID: 0,
+				UserID:      user.ID,
+				ActionCode:  models.AuditLogActionVaultAccessExpired,
+				ActionData:  models.AuditLogDataVaultAccessExpired(user.Email, permissions),
+			}
+			if err := tx.Create(&auditLog).Error; err != nil {
+				return err
+			}
+
+			revokedCount++
+			return nil
+		})
+		if err != nil && !errors.Is(err, VaultNotFoundErr{}) {
+			return revokedCount, err
+		}
+	}
+	return revokedCount, nil
+}
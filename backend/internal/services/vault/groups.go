@@ -0,0 +1,232 @@
+package vault
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// GrantGroupVaultAccess gives given permissions on the vault to every member of the group. Read permission is
+// always granted in addition to given permissions. manager must be a manager of the group too, otherwise
+// NotGroupManagerErr is returned. keys are the vault key encrypted for the group members who don't have a vault key
+// yet, members left out remain in the pending vault key list until a manager shares the key.
+func GrantGroupVaultAccess(db *gorm.DB, vaultId uint, manager models.User, groupId uint, permissions []string,
+	keys []WrappedVaultKey) error {
+	for _, p := range permissions {
+		if !slices.Contains(grantablePermissions, p) {
+			return InvalidPermissionErr{Permission: p}
+		}
+	}
+
+	newPermissions := []string{models.VaultPermissionRead}
+	for _, p := range permissions {
+		if !slices.Contains(newPermissions, p) {
+			newPermissions = append(newPermissions, p)
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultId); err != nil {
+			return err
+		}
+
+		var group models.Group
+		err := tx.First(&group, groupId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return GroupNotFoundErr{}
+			}
+			return err
+		}
+
+		var isGroupManager bool
+		err = tx.Model(&models.GroupMember{}).Select("count(*) > 0").
+			Where("group_id = ? AND user_id = ? AND is_manager = ?", group.ID, manager.ID, true).
+			Scan(&isGroupManager).Error
+		if err != nil {
+			return err
+		}
+		if !isGroupManager {
+			return NotGroupManagerErr{}
+		}
+
+		var isAlreadyAdded bool
+		err = tx.Model(&models.VaultGroupPermission{}).Select("count(*) > 0").
+			Where("vault_id = ? AND group_id = ?", vaultId, group.ID).Scan(&isAlreadyAdded).Error
+		if err != nil {
+			return err
+		}
+		if isAlreadyAdded {
+			return GroupAlreadyVaultMemberErr{}
+		}
+
+		groupPermissions := []models.VaultGroupPermission{}
+		for _, p := range newPermissions {
+			groupPermissions = append(groupPermissions, models.VaultGroupPermission{
+				VaultID:    vaultId,
+				GroupID:    group.ID,
+				Permission: p,
+			})
+		}
+		if err := tx.Create(&groupPermissions).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      manager.ID,
+			ActionCode:  models.AuditLogActionVaultAddGroup,
+			ActionData: models.AuditLogDataVaultAddGroup(group.Name, common.Map(groupPermissions,
+				func(i models.VaultGroupPermission) string { return i.Permission })),
+		}
+		if err := tx.Create(&auditLog).Error; err != nil {
+			return err
+		}
+
+		return sharePendingVaultKeys(tx, vaultId, manager, keys)
+	})
+}
+
+// RevokeGroupVaultAccess removes the permissions of the group on the vault. Vault keys of the group members who
+// don't have access to the vault anymore are deleted. It refuses to take away the manage_vault permission of the last
+// managers of the vault.
+func RevokeGroupVaultAccess(db *gorm.DB, vaultId uint, manager models.User, groupId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultId); err != nil {
+			return err
+		}
+
+		var group models.Group
+		err := tx.First(&group, groupId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return GroupNotFoundErr{}
+			}
+			return err
+		}
+
+		var hadManagePermission bool
+		err = tx.Model(&models.VaultGroupPermission{}).Select("count(*) > 0").
+			Where("vault_id = ? AND group_id = ? AND permission = ?", vaultId, group.ID, models.VaultPermissionManageVault).
+			Scan(&hadManagePermission).Error
+		if err != nil {
+			return err
+		}
+
+		res := tx.Where("vault_id = ? AND group_id = ?", vaultId, group.ID).Delete(&models.VaultGroupPermission{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return GroupNotVaultMemberErr{}
+		}
+		if hadManagePermission {
+			if err := EnsureVaultHasManager(tx, vaultId); err != nil {
+				return err
+			}
+		}
+
+		var memberIds []uint
+		err = tx.Model(&models.GroupMember{}).Where("group_id = ?", group.ID).Pluck("user_id", &memberIds).Error
+		if err != nil {
+			return err
+		}
+		if err := RevokeOrphanVaultKeys(tx, []uint{vaultId}, memberIds); err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      manager.ID,
+			ActionCode:  models.AuditLogActionVaultRemoveGroup,
+			ActionData:  models.AuditLogDataVaultRemoveGroup(group.Name),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// ListPendingVaultKeyUsers returns the users who have access to the vault but don't have a vault key yet. This
+// happens when a user joins a group after the group is given access to the vault.
+func ListPendingVaultKeyUsers(db *gorm.DB, vaultId uint) ([]models.User, error) {
+	users := []models.User{}
+	err := db.Where("id IN (?)", EffectivePermissions(db).Select("user_id").Where("vault_id = ?", vaultId)).
+		Where("id NOT IN (?)", db.Model(&models.VaultKey{}).Select("key_owner_user_id").Where("vault_id = ?", vaultId)).
+		Order("email ASC").Find(&users).Error
+	return users, err
+}
+
+// SharePendingVaultKeys saves given vault keys for the users who have access to the vault but don't have a vault
+// key yet. manager is the user who encrypted the vault keys.
+func SharePendingVaultKeys(db *gorm.DB, vaultId uint, manager models.User, keys []WrappedVaultKey) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultId); err != nil {
+			return err
+		}
+		return sharePendingVaultKeys(tx, vaultId, manager, keys)
+	})
+}
+
+func sharePendingVaultKeys(tx *gorm.DB, vaultId uint, manager models.User, keys []WrappedVaultKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pendingUsers, err := ListPendingVaultKeyUsers(tx, vaultId)
+	if err != nil {
+		return err
+	}
+
+	vaultKeys := []models.VaultKey{}
+	userEmails := []string{}
+	for _, key := range keys {
+		i := slices.IndexFunc(pendingUsers, func(u models.User) bool { return u.ID == key.UserId })
+		if i == -1 {
+			return VaultKeyNotPendingErr{UserId: key.UserId}
+		}
+		userEmails = append(userEmails, pendingUsers[i].Email)
+		// a user can't have two keys for the same vault
+		pendingUsers = slices.Delete(pendingUsers, i, i+1)
+
+		vaultKeys = append(vaultKeys, models.VaultKey{
+			VaultID:           vaultId,
+			KeyOwnerUserID:    key.UserId,
+			InviterUserID:     manager.ID,
+			EncryptionIV:      key.EncryptionIV,
+			EncryptedVaultKey: key.EncryptedVaultKey,
+		})
+	}
+	if err := tx.Create(&vaultKeys).Error; err != nil {
+		return err
+	}
+
+	auditLog := models.VaultAuditLog{
+		VaultID:     vaultId,
+		VaultItemID: 0,
+		UserID:      manager.ID,
+		ActionCode:  models.AuditLogActionVaultShareKeys,
+		ActionData:  models.AuditLogDataVaultShareKeys(userEmails),
+	}
+	return tx.Create(&auditLog).Error
+}
+
+// RevokeOrphanVaultKeys deletes the vault keys of given users on given vaults if they don't have any permission on
+// the vault anymore, neither directly nor through a group.
+func RevokeOrphanVaultKeys(tx *gorm.DB, vaultIds []uint, userIds []uint) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	for _, vaultId := range vaultIds {
+		err := tx.Unscoped().Where("vault_id = ? AND key_owner_user_id IN ?", vaultId, userIds).
+			Where("key_owner_user_id NOT IN (?)", EffectivePermissions(tx).Select("user_id").Where("vault_id = ?", vaultId)).
+			Delete(&models.VaultKey{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package vault
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// InviteToVault creates an invitation to the vault for given email address. The email doesn't need to belong to a
+// registered user. Read permission is always granted in addition to given permissions once the invitation is
+// completed.
+func InviteToVault(db *gorm.DB, vaultId uint, inviter models.User, email string, permissions []string) (
+	models.VaultInvitation, error) {
+	for _, p := range permissions {
+		if !slices.Contains(grantablePermissions, p) {
+			return models.VaultInvitation{}, InvalidPermissionErr{Permission: p}
+		}
+	}
+
+	invitation := models.VaultInvitation{
+		VaultID:       vaultId,
+		Email:         email,
+		InviterUserID: inviter.ID,
+		Permissions:   permissions,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultId); err != nil {
+			return err
+		}
+
+		var isAlreadyMember bool
+		err := tx.Model(&models.VaultPermission{}).Select("count(*) > 0").
+			Joins("INNER JOIN users ON users.id = vault_permissions.user_id").
+			Where("vault_permissions.vault_id = ? AND users.email = ?", vaultId, email).
+			Scan(&isAlreadyMember).Error
+		if err != nil {
+			return err
+		}
+		if isAlreadyMember {
+			return UserAlreadyVaultMemberErr{}
+		}
+
+		var isAlreadyInvited bool
+		err = tx.Model(&models.VaultInvitation{}).Select("count(*) > 0").
+			Where("vault_id = ? AND email = ?", vaultId, email).Scan(&isAlreadyInvited).Error
+		if err != nil {
+			return err
+		}
+		if isAlreadyInvited {
+			return InvitationAlreadyExistsErr{}
+		}
+
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      inviter.ID,
+			ActionCode:  models.AuditLogActionVaultInviteUser,
+			ActionData: models.AuditLogDataVaultInviteUser(email,
+				append(slices.Clone(permissions), models.VaultPermissionRead)),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		return models.VaultInvitation{}, err
+	}
+	return invitation, nil
+}
+
+// RevokeVaultInvitation deletes given invitation of the vault whether it's accepted or not.
+func RevokeVaultInvitation(db *gorm.DB, vaultId uint, manager models.User, invitationId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		invitation, err := getVaultInvitation(tx, vaultId, invitationId)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&invitation).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      manager.ID,
+			ActionCode:  models.AuditLogActionVaultRevokeInvitation,
+			ActionData:  models.AuditLogDataVaultRevokeInvitation(invitation.Email),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// AcceptVaultInvitation marks the invitation sent to the email of given user as accepted by them. The invitee gets
+// access to the vault once a vault manager completes the invitation.
+func AcceptVaultInvitation(db *gorm.DB, invitationId uint, invitee models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		invitation, err := getUserInvitation(tx, invitationId, invitee)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&invitation).Update("invitee_user_id", invitee.ID).Error
+		if err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     invitation.VaultID,
+			VaultItemID: 0,
+			UserID:      invitee.ID,
+			ActionCode:  models.AuditLogActionVaultAcceptInvitation,
+			ActionData:  models.AuditLogDataVaultAcceptInvitation(invitation.Email),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// DeclineVaultInvitation deletes the invitation sent to the email of given user.
+func DeclineVaultInvitation(db *gorm.DB, invitationId uint, invitee models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		invitation, err := getUserInvitation(tx, invitationId, invitee)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&invitation).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     invitation.VaultID,
+			VaultItemID: 0,
+			UserID:      invitee.ID,
+			ActionCode:  models.AuditLogActionVaultDeclineInvitation,
+			ActionData:  models.AuditLogDataVaultDeclineInvitation(invitation.Email),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// CompleteVaultInvitation gives the invitee of an accepted invitation access to the vault with the permissions in
+// the invitation. encryptionIV and encryptedVaultKey are the vault key encrypted for the invitee by the manager.
+// Returns the added user.
+func CompleteVaultInvitation(db *gorm.DB, vaultId uint, manager models.User, invitationId uint, encryptionIV,
+	encryptedVaultKey string) (models.User, error) {
+	var invitee models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultId); err != nil {
+			return err
+		}
+
+		invitation, err := getVaultInvitation(tx, vaultId, invitationId)
+		if err != nil {
+			return err
+		}
+		if invitation.InviteeUserID == 0 {
+			return InvitationNotAcceptedErr{}
+		}
+
+		err = tx.First(&invitee, invitation.InviteeUserID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return UserNotFoundErr{}
+			}
+			return err
+		}
+
+		if err := tx.Delete(&invitation).Error; err != nil {
+			return err
+		}
+
+		return addMember(tx, vaultId, manager, invitee, invitation.Permissions, nil, encryptionIV,
+			encryptedVaultKey)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return invitee, nil
+}
+
+func getVaultInvitation(tx *gorm.DB, vaultId, invitationId uint) (models.VaultInvitation, error) {
+	var invitation models.VaultInvitation
+	err := tx.Where("vault_id = ?", vaultId).First(&invitation, invitationId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.VaultInvitation{}, InvitationNotFoundErr{}
+		}
+		return models.VaultInvitation{}, err
+	}
+	return invitation, nil
+}
+
+// getUserInvitation returns the invitation with given id if it's sent to the email of given user and not accepted
+// yet. Returns EmailNotVerifiedErr if the user didn't prove they own the email.
+func getUserInvitation(tx *gorm.DB, invitationId uint, user models.User) (models.VaultInvitation, error) {
+	if user.EmailVerifiedAt == nil {
+		return models.VaultInvitation{}, EmailNotVerifiedErr{}
+	}
+
+	var invitation models.VaultInvitation
+	err := tx.Where("email = ?", user.Email).First(&invitation, invitationId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.VaultInvitation{}, InvitationNotFoundErr{}
+		}
+		return models.VaultInvitation{}, err
+	}
+	if invitation.InviteeUserID != 0 {
+		return models.VaultInvitation{}, InvitationAlreadyAcceptedErr{}
+	}
+	return invitation, nil
+}
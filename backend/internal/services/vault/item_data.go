@@ -0,0 +1,63 @@
+package vault
+
+import (
+	"slices"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+)
+
+// VaultItemDataV1 is a JSON object encrypted with AES-256-GCM using the vault key:
+//
+//	{"fields": [{"label": "Username", "type": "text", "value": "john"}]}
+//
+// Type of a field is one of "text", "hidden", "url" and "totp", the value of a totp field is the base32 encoded TOTP
+// seed. Fields are kept in the order the user entered them.
+const VaultItemDataV1 = 1
+
+// supportedVaultItemDataVersions are the item data versions the clients can decrypt. A new version is added when the
+// format or the encryption of the item data changes.
+var supportedVaultItemDataVersions = []int{VaultItemDataV1}
+
+var vaultItemTypes = []string{models.VaultItemTypeLogin, models.VaultItemTypeSecureNote,
+	models.VaultItemTypeCreditCard, models.VaultItemTypeAPIKey, models.VaultItemTypeSSHKey}
+
+// VaultItemData is the encrypted payload of a vault item which holds its fields. The server can't decrypt it.
+type VaultItemData struct {
+	Version       int
+	EncryptionIV  string
+	EncryptedData string
+}
+
+// ValidateVaultItem returns InvalidVaultItemTypeErr if itemType isn't a known vault item type and
+// UnsupportedVaultItemDataErr if the version of data isn't supported. data may be nil.
+func ValidateVaultItem(itemType string, data *VaultItemData) error {
+	if !slices.Contains(vaultItemTypes, itemType) {
+		return InvalidVaultItemTypeErr{Type: itemType}
+	}
+	if data != nil && !slices.Contains(supportedVaultItemDataVersions, data.Version) {
+		return UnsupportedVaultItemDataErr{Version: data.Version}
+	}
+	return nil
+}
+
+// SetVaultItemData sets the data columns of given vault item without saving it. The data is cleared if data is nil.
+func SetVaultItemData(vaultItem *models.VaultItem, data *VaultItemData) {
+	if data == nil {
+		data = &VaultItemData{}
+	}
+	vaultItem.DataVersion = data.Version
+	vaultItem.DataEncryptionIV = data.EncryptionIV
+	vaultItem.EncryptedData = data.EncryptedData
+}
+
+// vaultItemDataColumns returns the vault item columns to store given data. The data is cleared if data is nil.
+func vaultItemDataColumns(data *VaultItemData) map[string]any {
+	if data == nil {
+		return map[string]any{"data_version": 0, "data_encryption_iv": "", "encrypted_data": ""}
+	}
+	return map[string]any{
+		"data_version":       data.Version,
+		"data_encryption_iv": data.EncryptionIV,
+		"encrypted_data":     data.EncryptedData,
+	}
+}
@@ -0,0 +1,173 @@
+package vault
+
+import (
+	"slices"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// RotatedVaultItem is the payload of a vault item re-encrypted with the new vault key. Data must be given if the
+// vault item has data.
+type RotatedVaultItem struct {
+	Id                uint
+	EncryptionIV      string
+	EncryptedUsername string
+	EncryptedPassword string
+	EncryptedNote     string
+	Data              *VaultItemData
+}
+
+// RotatedAttachment is the metadata of a vault item attachment re-encrypted with the new vault key. The content of
+// the attachment is encrypted with its own key which is a part of the metadata, so it doesn't change.
+type RotatedAttachment struct {
+	Id                uint
+	EncryptionIV      string
+	EncryptedMetadata string
+}
+
+// RotateVaultKey replaces the vault key of every member, the payload of every vault item and the metadata of every
+// attachment in a single transaction. items, attachments and keys must cover exactly the current items, attachments
+// and members of the vault, otherwise VaultChangedErr is returned. VaultItemDataMissingErr is returned if the data
+// of an item which has one isn't given. Revisions and deleted items of the vault are permanently deleted since they
+// are encrypted with the old vault key.
+func RotateVaultKey(db *gorm.DB, vaultId uint, manager models.User, items []RotatedVaultItem,
+	attachments []RotatedAttachment, keys []WrappedVaultKey) error {
+	itemsById := make(map[uint]RotatedVaultItem, len(items))
+	for _, item := range items {
+		if item.Data != nil && !slices.Contains(supportedVaultItemDataVersions, item.Data.Version) {
+			return UnsupportedVaultItemDataErr{Version: item.Data.Version}
+		}
+		itemsById[item.Id] = item
+	}
+	attachmentIds := make(map[uint]bool, len(attachments))
+	for _, attachment := range attachments {
+		attachmentIds[attachment.Id] = true
+	}
+	keysByUserId := make(map[uint]WrappedVaultKey, len(keys))
+	for _, key := range keys {
+		keysByUserId[key.UserId] = key
+	}
+	if len(itemsById) != len(items) || len(attachmentIds) != len(attachments) || len(keysByUserId) != len(keys) {
+		return DuplicateEntryErr{}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultId); err != nil {
+			return err
+		}
+
+		var memberIds []uint
+		err := EffectivePermissions(tx).Distinct("user_id").
+			Where("vault_id = ?", vaultId).Pluck("user_id", &memberIds).Error
+		if err != nil {
+			return err
+		}
+		if !sameIds(memberIds, common.Map(keys, func(k WrappedVaultKey) uint { return k.UserId })) {
+			return VaultChangedErr{}
+		}
+
+		var itemIds []uint
+		err = tx.Model(&models.VaultItem{}).Where("vault_id = ?", vaultId).Pluck("id", &itemIds).Error
+		if err != nil {
+			return err
+		}
+		if !sameIds(itemIds, common.Map(items, func(i RotatedVaultItem) uint { return i.Id })) {
+			return VaultChangedErr{}
+		}
+
+		var itemIdsWithData []uint
+		err = tx.Model(&models.VaultItem{}).Where("vault_id = ? AND data_version <> 0", vaultId).
+			Pluck("id", &itemIdsWithData).Error
+		if err != nil {
+			return err
+		}
+		for _, itemId := range itemIdsWithData {
+			if itemsById[itemId].Data == nil {
+				return VaultItemDataMissingErr{VaultItemId: itemId}
+			}
+		}
+
+		for _, item := range items {
+			updates := vaultItemDataColumns(item.Data)
+			updates["encryption_iv"] = item.EncryptionIV
+			updates["encrypted_username"] = item.EncryptedUsername
+			updates["encrypted_password"] = item.EncryptedPassword
+			updates["encrypted_note"] = item.EncryptedNote
+			err = tx.Model(&models.VaultItem{}).Where("id = ?", item.Id).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var currentAttachmentIds []uint
+		err = tx.Model(&models.VaultItemAttachment{}).Where("vault_id = ?", vaultId).
+			Pluck("id", &currentAttachmentIds).Error
+		if err != nil {
+			return err
+		}
+		if !sameIds(currentAttachmentIds, common.Map(attachments, func(a RotatedAttachment) uint { return a.Id })) {
+			return VaultChangedErr{}
+		}
+		for _, attachment := range attachments {
+			err = tx.Model(&models.VaultItemAttachment{}).Where("id = ?", attachment.Id).Updates(map[string]any{
+				"encryption_iv":      attachment.EncryptionIV,
+				"encrypted_metadata": attachment.EncryptedMetadata,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// revisions and deleted items can't be decrypted with the new vault key anymore
+		err = tx.Unscoped().Where("vault_item_id IN ?", itemIds).Delete(&models.VaultItemRevision{}).Error
+		if err != nil {
+			return err
+		}
+		var deletedItemIds []uint
+		err = tx.Unscoped().Model(&models.VaultItem{}).Where("vault_id = ? AND deleted_at IS NOT NULL", vaultId).
+			Pluck("id", &deletedItemIds).Error
+		if err != nil {
+			return err
+		}
+		if _, err = hardDeleteVaultItems(tx, deletedItemIds); err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Where("vault_id = ?", vaultId).Delete(&models.VaultKey{}).Error
+		if err != nil {
+			return err
+		}
+		newVaultKeys := make([]models.VaultKey, 0, len(keys))
+		for _, key := range keys {
+			newVaultKeys = append(newVaultKeys, models.VaultKey{
+				VaultID:           vaultId,
+				KeyOwnerUserID:    key.UserId,
+				InviterUserID:     manager.ID,
+				EncryptionIV:      key.EncryptionIV,
+				EncryptedVaultKey: key.EncryptedVaultKey,
+			})
+		}
+		if err := tx.Create(&newVaultKeys).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      manager.ID,
+			ActionCode:  models.AuditLogActionVaultKeyRotated,
+			ActionData:  models.AuditLogDataVaultKeyRotated(len(keys), len(items)),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// sameIds returns true if given id lists contain the same ids regardless of their order.
+func sameIds(a, b []uint) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,464 @@
+package vault
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/common"
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// grantablePermissions are the permissions which can be given to a vault member. Read permission is not listed
+// because every member has it.
+var grantablePermissions = []string{
+	models.VaultPermissionManageVault,
+	models.VaultPermissionDeleteVault,
+	models.VaultPermissionManageItems,
+}
+
+// AddUserToVault gives the user with given email access to the vault. encryptionIV and encryptedVaultKey are the
+// vault key encrypted for the new member by the inviter. Read permission is always granted in addition to given
+// permissions. If expiresAt is not nil, the access is revoked at that time. Returns the added user.
+func AddUserToVault(db *gorm.DB, vaultId uint, inviter models.User, email string, permissions []string,
+	expiresAt *time.Time, encryptionIV, encryptedVaultKey string) (models.User, error) {
+	for _, p := range permissions {
+		if !slices.Contains(grantablePermissions, p) {
+			return models.User{}, InvalidPermissionErr{Permission: p}
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return models.User{}, InvalidExpiryErr{}
+	}
+
+	var newUser models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&newUser, "email = ?", email).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return UserNotFoundErr{}
+			}
+			return err
+		}
+
+		return addMember(tx, vaultId, inviter, newUser, permissions, expiresAt, encryptionIV, encryptedVaultKey)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return newUser, nil
+}
+
+// LeaveVault removes the permissions and the vault key of given user from the vault. The owner of the vault and the
+// last user with the manage_vault permission can't leave the vault.
+func LeaveVault(db *gorm.DB, vaultId uint, user models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		vault, err := lockVault(tx, vaultId)
+		if err != nil {
+			return err
+		}
+
+		if err := removeMember(tx, vault, user.ID); err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      user.ID,
+			ActionCode:  models.AuditLogActionVaultUserLeft,
+			ActionData:  models.AuditLogDataVaultUserLeft(),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// RemoveUserFromVault removes the permissions and the vault key of given member from the vault. The owner of the
+// vault and the last user with the manage_vault permission can't be removed. Returns the removed user.
+func RemoveUserFromVault(db *gorm.DB, vaultId uint, manager models.User, memberId uint) (models.User, error) {
+	var member models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		vault, err := lockVault(tx, vaultId)
+		if err != nil {
+			return err
+		}
+
+		err = tx.First(&member, memberId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return UserNotFoundErr{}
+			}
+			return err
+		}
+
+		if err := removeMember(tx, vault, member.ID); err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      manager.ID,
+			ActionCode:  models.AuditLogActionVaultRemoveUser,
+			ActionData:  models.AuditLogDataVaultRemoveUser(member.Email),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return member, nil
+}
+
+// RemoveDeletedUserFromVaults removes the direct access of given user to every vault before the user is deleted.
+// Vaults which don't have any other member are deleted, and the ownership of the other vaults the user owns is
+// cleared, so one of their managers can take it over. Returns LastVaultManagerErr if the user is the last manager of
+// a vault which has other members. admin is the user who deletes the user.
+func RemoveDeletedUserFromVaults(tx *gorm.DB, user, admin models.User) error {
+	vaultIds := []uint{}
+	err := tx.Model(&models.VaultPermission{}).Distinct("vault_id").Where("user_id = ?", user.ID).
+		Pluck("vault_id", &vaultIds).Error
+	if err != nil {
+		return err
+	}
+
+	for _, vaultId := range vaultIds {
+		vault, err := lockVault(tx, vaultId)
+		if err != nil {
+			return err
+		}
+
+		var hasOtherMembers bool
+		err = EffectivePermissions(tx).Select("count(*) > 0").Where("vault_id = ? AND user_id <> ?", vaultId, user.ID).
+			Scan(&hasOtherMembers).Error
+		if err != nil {
+			return err
+		}
+		if !hasOtherMembers {
+			if err := DeleteVault(tx, vaultId, admin.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if vault.OwnerUserID == user.ID {
+			if err := tx.Model(&vault).Update("owner_user_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		if _, err := RemoveUserFromVault(tx, vaultId, admin, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransferVaultOwnership makes given member the owner of the vault. Only the current owner can transfer the
+// ownership, or any user with the manage_vault permission if the vault has no owner yet. The new owner is given
+// the manage_vault permission if they don't have it already.
+func TransferVaultOwnership(db *gorm.DB, vaultId uint, currentUser models.User, newOwnerId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		vault, err := lockVault(tx, vaultId)
+		if err != nil {
+			return err
+		}
+		if vault.OwnerUserID != 0 && vault.OwnerUserID != currentUser.ID {
+			return NotVaultOwnerErr{}
+		}
+
+		var newOwner models.User
+		err = tx.First(&newOwner, newOwnerId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return UserNotFoundErr{}
+			}
+			return err
+		}
+
+		permissions, err := getMemberPermissions(tx, vaultId, newOwner.ID)
+		if err != nil {
+			return err
+		}
+		if len(permissions) == 0 {
+			return UserNotVaultMemberErr{}
+		}
+		if !slices.Contains(permissions, models.VaultPermissionManageVault) {
+			err = tx.Create(&models.VaultPermission{
+				VaultID:    vaultId,
+				UserID:     newOwner.ID,
+				Permission: models.VaultPermissionManageVault,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// owner's access never expires
+		err = tx.Model(&models.VaultPermission{}).Where("vault_id = ? AND user_id = ?", vaultId, newOwner.ID).
+			Update("expires_at", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.VaultKey{}).Where("vault_id = ? AND key_owner_user_id = ?", vaultId, newOwner.ID).
+			Update("expires_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&vault).Update("owner_user_id", newOwner.ID).Error
+		if err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      currentUser.ID,
+			ActionCode:  models.AuditLogActionVaultTransferOwnership,
+			ActionData:  models.AuditLogDataVaultTransferOwnership(currentUser.Email, newOwner.Email),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// UpdateMemberPermissions replaces the permissions of given vault member with given permissions. Read permission is
+// always kept. Returns the sorted permission sets of the member before and after the update.
+func UpdateMemberPermissions(db *gorm.DB, vaultId uint, manager models.User, memberId uint,
+	permissions []string) (oldPermissions, newPermissions []string, err error) {
+	for _, p := range permissions {
+		if !slices.Contains(grantablePermissions, p) {
+			return nil, nil, InvalidPermissionErr{Permission: p}
+		}
+	}
+
+	newPermissions = []string{models.VaultPermissionRead}
+	for _, p := range permissions {
+		if !slices.Contains(newPermissions, p) {
+			newPermissions = append(newPermissions, p)
+		}
+	}
+	slices.Sort(newPermissions)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		vault, err := lockVault(tx, vaultId)
+		if err != nil {
+			return err
+		}
+
+		var member models.User
+		err = tx.First(&member, memberId).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return UserNotFoundErr{}
+			}
+			return err
+		}
+
+		oldPermissions, err = getMemberPermissions(tx, vaultId, member.ID)
+		if err != nil {
+			return err
+		}
+		if len(oldPermissions) == 0 {
+			return UserNotVaultMemberErr{}
+		}
+
+		removesManagePermission := slices.Contains(oldPermissions, models.VaultPermissionManageVault) &&
+			!slices.Contains(newPermissions, models.VaultPermissionManageVault)
+		if removesManagePermission && vault.OwnerUserID == member.ID {
+			return VaultOwnerErr{}
+		}
+
+		// added permissions expire together with the existing ones
+		var existingPermission models.VaultPermission
+		err = tx.Where("vault_id = ? AND user_id = ?", vaultId, member.ID).First(&existingPermission).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("vault_id = ? AND user_id = ? AND permission NOT IN ?", vaultId, member.ID, newPermissions).
+			Delete(&models.VaultPermission{}).Error
+		if err != nil {
+			return err
+		}
+		if removesManagePermission {
+			if err := EnsureVaultHasManager(tx, vaultId); err != nil {
+				return err
+			}
+		}
+
+		addedPermissions := []models.VaultPermission{}
+		for _, p := range newPermissions {
+			if !slices.Contains(oldPermissions, p) {
+				addedPermissions = append(addedPermissions, models.VaultPermission{
+					VaultID:    vaultId,
+					UserID:     member.ID,
+					Permission: p,
+					ExpiresAt:  existingPermission.ExpiresAt,
+				})
+			}
+		}
+		if len(addedPermissions) > 0 {
+			if err := tx.Create(&addedPermissions).Error; err != nil {
+				return err
+			}
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      manager.ID,
+			ActionCode:  models.AuditLogActionVaultUpdatePermissions,
+			ActionData:  models.AuditLogDataVaultUpdatePermissions(member.Email, oldPermissions, newPermissions),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldPermissions, newPermissions, nil
+}
+
+// addMember gives given permissions and the vault key to newUser and records it in the audit log. Permissions must
+// be validated by the caller.
+func addMember(tx *gorm.DB, vaultId uint, inviter, newUser models.User, permissions []string, expiresAt *time.Time,
+	encryptionIV, encryptedVaultKey string) error {
+	var isAlreadyAdded bool
+	err := tx.Model(&models.VaultPermission{}).Select("count(*) > 0").
+		Where("vault_id = ? AND user_id = ?", vaultId, newUser.ID).Scan(&isAlreadyAdded).Error
+	if err != nil {
+		return err
+	}
+	if isAlreadyAdded {
+		return UserAlreadyVaultMemberErr{}
+	}
+
+	newUserVaultPermissions := []models.VaultPermission{}
+	for _, p := range permissions {
+		newUserVaultPermissions = append(newUserVaultPermissions, models.VaultPermission{
+			VaultID:    vaultId,
+			UserID:     newUser.ID,
+			Permission: p,
+			ExpiresAt:  expiresAt,
+		})
+	}
+	// append the read permission since it's mandatory
+	newUserVaultPermissions = append(newUserVaultPermissions, models.VaultPermission{
+		VaultID:    vaultId,
+		UserID:     newUser.ID,
+		Permission: models.VaultPermissionRead,
+		ExpiresAt:  expiresAt,
+	})
+	if err := tx.Create(&newUserVaultPermissions).Error; err != nil {
+		return err
+	}
+
+	// user may already have a vault key if they have access to the vault through a group
+	var hasVaultKey bool
+	err = tx.Model(&models.VaultKey{}).Select("count(*) > 0").
+		Where("vault_id = ? AND key_owner_user_id = ?", vaultId, newUser.ID).Scan(&hasVaultKey).Error
+	if err != nil {
+		return err
+	}
+	if !hasVaultKey {
+		vaultKeyRecord := models.VaultKey{
+			VaultID:           vaultId,
+			KeyOwnerUserID:    newUser.ID,
+			InviterUserID:     inviter.ID,
+			EncryptionIV:      encryptionIV,
+			EncryptedVaultKey: encryptedVaultKey,
+			ExpiresAt:         expiresAt,
+		}
+		if err := tx.Create(&vaultKeyRecord).Error; err != nil {
+			return err
+		}
+	}
+
+	auditLog := models.VaultAuditLog{
+		VaultID:     vaultId,
+		VaultItemID: 0,
+		UserID:      inviter.ID,
+		ActionCode:  models.AuditLogActionVaultAddUser,
+		ActionData: models.AuditLogDataVaultAddUser(newUser.Email, common.Map(newUserVaultPermissions,
+			func(i models.VaultPermission) string { return i.Permission })),
+	}
+	return tx.Create(&auditLog).Error
+}
+
+// lockVault locks the row of given vault until the end of the transaction and returns the vault. It's used to
+// serialize operations which check the member set of a vault before modifying it.
+func lockVault(tx *gorm.DB, vaultId uint) (models.Vault, error) {
+	var vault models.Vault
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&vault, vaultId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Vault{}, VaultNotFoundErr{}
+		}
+		return models.Vault{}, err
+	}
+	return vault, nil
+}
+
+// removeMember deletes the permissions and the vault key of given user on given vault. The vault key is kept if the
+// user still has access to the vault through a group. It refuses to remove the owner of the vault and the last user
+// with the manage_vault permission.
+func removeMember(tx *gorm.DB, vault models.Vault, userId uint) error {
+	if vault.OwnerUserID == userId {
+		return VaultOwnerErr{}
+	}
+
+	permissions, err := getMemberPermissions(tx, vault.ID, userId)
+	if err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return UserNotVaultMemberErr{}
+	}
+
+	err = tx.Where("vault_id = ? AND user_id = ?", vault.ID, userId).Delete(&models.VaultPermission{}).Error
+	if err != nil {
+		return err
+	}
+	if slices.Contains(permissions, models.VaultPermissionManageVault) {
+		if err := EnsureVaultHasManager(tx, vault.ID); err != nil {
+			return err
+		}
+	}
+
+	return RevokeOrphanVaultKeys(tx, []uint{vault.ID}, []uint{userId})
+}
+
+// getMemberPermissions returns the sorted permissions of given user on given vault.
+func getMemberPermissions(db *gorm.DB, vaultId, userId uint) ([]string, error) {
+	permissions := []string{}
+	err := db.Model(&models.VaultPermission{}).Select("permission").
+		Where("vault_id = ? AND user_id = ?", vaultId, userId).Order("permission ASC").Scan(&permissions).Error
+	return permissions, err
+}
+
+// EnsureVaultHasManager returns LastVaultManagerErr if nobody has the manage_vault permission on given vault
+// anymore, neither directly nor through a group. Managers whose access expires are not counted. It's called in the
+// transaction after the changes which may take the permission away from the last manager, so returning the error
+// rolls them back. The vault is locked to serialize the check with the other membership changes of the vault, deleted
+// vaults are skipped.
+func EnsureVaultHasManager(tx *gorm.DB, vaultId uint) error {
+	if _, err := lockVault(tx, vaultId); err != nil {
+		if errors.Is(err, VaultNotFoundErr{}) {
+			return nil
+		}
+		return err
+	}
+
+	var hasManager bool
+	err := EffectivePermissions(tx).Select("count(*) > 0").
+		Where("vault_id = ? AND permission = ? AND expires_at IS NULL", vaultId, models.VaultPermissionManageVault).
+		Scan(&hasManager).Error
+	if err != nil {
+		return err
+	}
+	if !hasManager {
+		return LastVaultManagerErr{}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package vault
+
+import (
+	"errors"
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	authservice "github.com/berk-karaal/letuspass/backend/internal/services/auth"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateSecretShare creates a share link for given vault item. encryptedData is the item data encrypted by the
+// creator with a key that is shared with the recipient outside the server. Returns the created share and its token,
+// the token can't be retrieved later since only its hash is stored. Returns InvalidExpiryErr if expiresAt isn't in
+// the future or it's later than maxLifetime from now.
+func CreateSecretShare(db *gorm.DB, vaultItem models.VaultItem, creator models.User, encryptedData string,
+	maxViews int, expiresAt time.Time, maxLifetime time.Duration) (models.SecretShare, string, error) {
+	timeNow := time.Now()
+	if !expiresAt.After(timeNow) || expiresAt.After(timeNow.Add(maxLifetime)) {
+		return models.SecretShare{}, "", InvalidExpiryErr{}
+	}
+
+	token, err := authservice.GenerateSecretToken()
+	if err != nil {
+		return models.SecretShare{}, "", err
+	}
+
+	share := models.SecretShare{
+		VaultID:       vaultItem.VaultID,
+		VaultItemID:   vaultItem.ID,
+		CreatorUserID: creator.ID,
+		TokenHash:     authservice.HashToken(token),
+		EncryptedData: encryptedData,
+		MaxViews:      maxViews,
+		ExpiresAt:     expiresAt,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&share).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultItem.VaultID,
+			VaultItemID: vaultItem.ID,
+			UserID:      creator.ID,
+			ActionCode:  models.AuditLogActionVaultItemShareCreate,
+			ActionData:  models.AuditLogDataVaultItemShareCreate(vaultItem.Title, maxViews, expiresAt),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		return models.SecretShare{}, "", err
+	}
+	return share, token, nil
+}
+
+// ConsumeSecretShare returns the share with given token and counts the view. The share is permanently deleted when
+// it reaches its view limit. The view is recorded in the audit log on behalf of the share creator since the viewer
+// has no account.
+func ConsumeSecretShare(db *gorm.DB, token string) (models.SecretShare, error) {
+	var share models.SecretShare
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("VaultItem").
+			Where("token_hash = ? AND expires_at > ?", authservice.HashToken(token), time.Now()).First(&share).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return SecretShareNotFoundErr{}
+			}
+			return err
+		}
+
+		share.ViewCount++
+		if share.ViewCount >= share.MaxViews {
+			err = tx.Unscoped().Delete(&share).Error
+		} else {
+			err = tx.Model(&share).Update("view_count", share.ViewCount).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     share.VaultID,
+			VaultItemID: share.VaultItemID,
+			UserID:      share.CreatorUserID,
+			ActionCode:  models.AuditLogActionVaultItemShareView,
+			ActionData: models.AuditLogDataVaultItemShareView(share.VaultItem.Title, share.ViewCount,
+				share.MaxViews),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		return models.SecretShare{}, err
+	}
+	return share, nil
+}
+
+// RevokeSecretShare permanently deletes given share of the vault item.
+func RevokeSecretShare(db *gorm.DB, vaultItem models.VaultItem, user models.User, shareId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("id = ? AND vault_item_id = ?", shareId, vaultItem.ID).Delete(&models.SecretShare{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return SecretShareNotFoundErr{}
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultItem.VaultID,
+			VaultItemID: vaultItem.ID,
+			UserID:      user.ID,
+			ActionCode:  models.AuditLogActionVaultItemShareRevoke,
+			ActionData:  models.AuditLogDataVaultItemShareRevoke(vaultItem.Title),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// PurgeExpiredSecretShares permanently deletes the shares which expired before given time. Returns the number of
+// deleted shares.
+func PurgeExpiredSecretShares(db *gorm.DB, expiredBefore time.Time) (int64, error) {
+	res := db.Unscoped().Where("expires_at <= ?", expiredBefore).Delete(&models.SecretShare{})
+	return res.RowsAffected, res.Error
+}
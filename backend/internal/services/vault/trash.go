@@ -0,0 +1,146 @@
+package vault
+
+import (
+	"time"
+
+	"github.com/berk-karaal/letuspass/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// RestoreVault restores a soft-deleted vault along with the permissions, keys, items and attachments that were
+// deleted together with it. Records which were deleted before the vault itself (e.g. permissions of removed users or
+// items that were already in the trash) stay deleted. userId is the id of the user who restores the vault.
+func RestoreVault(db *gorm.DB, vault models.Vault, userId uint) error {
+	deletedAt := vault.DeletedAt.Time
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Vault{}).Where("id = ?", vault.ID).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		for _, model := range []any{&models.VaultPermission{}, &models.VaultGroupPermission{}, &models.VaultKey{},
+			&models.VaultInvitation{}, &models.VaultItem{}, &models.VaultItemAttachment{}} {
+			err = tx.Unscoped().Model(model).Where("vault_id = ? AND deleted_at >= ?", vault.ID, deletedAt).
+				Update("deleted_at", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vault.ID,
+			VaultItemID: 0,
+			UserID:      userId,
+			ActionCode:  models.AuditLogActionVaultRestore,
+			ActionData:  models.AuditLogDataVaultRestore(vault.Name),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// RestoreVaultItem restores a soft-deleted vault item along with the attachments that were deleted together with it.
+// Returns AttachmentQuotaExceededErr if the restored attachments don't fit in vaultQuota. The quota is checked while
+// the vault is locked like in UploadAttachment, so restoring items can't be used to exceed it.
+func RestoreVaultItem(db *gorm.DB, vaultItem models.VaultItem, vaultQuota int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockVault(tx, vaultItem.VaultID); err != nil {
+			return err
+		}
+
+		attachments := tx.Unscoped().Model(&models.VaultItemAttachment{}).
+			Where("vault_item_id = ? AND deleted_at >= ?", vaultItem.ID, vaultItem.DeletedAt.Time)
+		var restoredSize int64
+		err := attachments.Session(&gorm.Session{}).Select("COALESCE(SUM(size), 0)").Scan(&restoredSize).Error
+		if err != nil {
+			return err
+		}
+		if restoredSize > 0 {
+			usedSize, err := VaultAttachmentsSize(tx, vaultItem.VaultID)
+			if err != nil {
+				return err
+			}
+			if usedSize+restoredSize > vaultQuota {
+				return AttachmentQuotaExceededErr{Quota: vaultQuota}
+			}
+		}
+
+		if err := attachments.Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&vaultItem).Update("deleted_at", nil).Error
+	})
+}
+
+// PurgeTrash hard-deletes vaults and vault items which were soft-deleted before given time. Returns the number of
+// purged vaults and vault items.
+func PurgeTrash(db *gorm.DB, deletedBefore time.Time) (purgedVaults int64, purgedItems int64, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var vaultIds []uint
+		err := tx.Unscoped().Model(&models.Vault{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).Pluck("id", &vaultIds).Error
+		if err != nil {
+			return err
+		}
+
+		var itemIds []uint
+		err = tx.Unscoped().Model(&models.VaultItem{}).
+			Where("(deleted_at IS NOT NULL AND deleted_at < ?) OR vault_id IN ?", deletedBefore, vaultIds).
+			Pluck("id", &itemIds).Error
+		if err != nil {
+			return err
+		}
+
+		purgedItems, err = hardDeleteVaultItems(tx, itemIds)
+		if err != nil {
+			return err
+		}
+
+		if len(vaultIds) > 0 {
+			for _, model := range []any{&models.VaultAuditLog{}, &models.VaultPermission{}, &models.VaultGroupPermission{},
+				&models.VaultKey{}, &models.VaultInvitation{}} {
+				err = tx.Unscoped().Where("vault_id IN ?", vaultIds).Delete(model).Error
+				if err != nil {
+					return err
+				}
+			}
+			res := tx.Unscoped().Where("id IN ?", vaultIds).Delete(&models.Vault{})
+			if res.Error != nil {
+				return res.Error
+			}
+			purgedVaults = res.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return purgedVaults, purgedItems, nil
+}
+
+// hardDeleteVaultItems permanently deletes given vault items with their revisions and secret shares. Their
+// attachments are soft-deleted so PurgeDeletedAttachments deletes their blobs. Returns the number of deleted vault
+// items.
+func hardDeleteVaultItems(tx *gorm.DB, itemIds []uint) (int64, error) {
+	if len(itemIds) == 0 {
+		return 0, nil
+	}
+
+	// audit logs of the vault outlive the item, the item title is still kept in the action data
+	err := tx.Unscoped().Model(&models.VaultAuditLog{}).Where("vault_item_id IN ?", itemIds).
+		Update("vault_item_id", nil).Error
+	if err != nil {
+		return 0, err
+	}
+	for _, model := range []any{&models.VaultItemRevision{}, &models.SecretShare{}} {
+		err = tx.Unscoped().Where("vault_item_id IN ?", itemIds).Delete(model).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = tx.Where("vault_item_id IN ?", itemIds).Delete(&models.VaultItemAttachment{}).Error
+	if err != nil {
+		return 0, err
+	}
+	res := tx.Unscoped().Where("id IN ?", itemIds).Delete(&models.VaultItem{})
+	return res.RowsAffected, res.Error
+}
@@ -1,13 +1,37 @@
 package vault
 
 import (
+	"time"
+
 	"github.com/berk-karaal/letuspass/backend/internal/models"
 	"gorm.io/gorm"
 )
 
+// WrappedVaultKey is a vault key encrypted for a vault member.
+type WrappedVaultKey struct {
+	UserId            uint
+	EncryptionIV      string
+	EncryptedVaultKey string
+}
+
+// EffectivePermissions returns a query of (vault_id, user_id, permission, expires_at) rows which contains both the
+// permissions given to users directly and the ones given to the groups they are member of. Expired permissions are
+// left out even if they are not revoked yet. expires_at is always null for the permissions given to groups.
+func EffectivePermissions(db *gorm.DB) *gorm.DB {
+	direct := db.Model(&models.VaultPermission{}).Select("vault_id, user_id, permission, expires_at").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	viaGroup := db.Model(&models.VaultGroupPermission{}).
+		Select("vault_group_permissions.vault_id, group_members.user_id, vault_group_permissions.permission, " +
+			"NULL AS expires_at").
+		Joins("INNER JOIN group_members ON group_members.group_id = vault_group_permissions.group_id " +
+			"AND group_members.deleted_at IS NULL")
+	return db.Table("(? UNION ?) AS effective_permissions", direct, viaGroup)
+}
+
 // CheckUserHasVaultPermission returns true if given user has given permission on given vault, if not returns false.
+// Permissions inherited from groups are taken into account.
 func CheckUserHasVaultPermission(db *gorm.DB, userId, vaultId int, permission string) (hasPermission bool, err error) {
-	err = db.Model(&models.VaultPermission{}).Select("count(*) > 0").
+	err = EffectivePermissions(db).Select("count(*) > 0").
 		Where("vault_id = ? AND user_id = ? AND permission = ?", vaultId, userId, permission).
 		Scan(&hasPermission).Error
 	if err != nil {
@@ -15,3 +39,112 @@ func CheckUserHasVaultPermission(db *gorm.DB, userId, vaultId int, permission st
 	}
 	return hasPermission, nil
 }
+
+// GetUserVaultPermissions returns the sorted permissions of given user on given vault, including the ones inherited
+// from groups.
+func GetUserVaultPermissions(db *gorm.DB, userId, vaultId uint) ([]string, error) {
+	permissions := []string{}
+	err := EffectivePermissions(db).Distinct("permission").
+		Where("vault_id = ? AND user_id = ?", vaultId, userId).Order("permission ASC").Scan(&permissions).Error
+	return permissions, err
+}
+
+// CreateVault creates a new vault owned by its creator and gives every vault permission to the creator.
+// encryptionIV and encryptedVaultKey are the vault key encrypted by the creator.
+func CreateVault(db *gorm.DB, creator models.User, name, encryptionIV, encryptedVaultKey string) (models.Vault, error) {
+	vault := models.Vault{Name: name, OwnerUserID: creator.ID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&vault).Error; err != nil {
+			return err
+		}
+
+		vaultKey := models.VaultKey{
+			VaultID:           vault.ID,
+			KeyOwnerUserID:    creator.ID,
+			InviterUserID:     creator.ID,
+			EncryptionIV:      encryptionIV,
+			EncryptedVaultKey: encryptedVaultKey,
+		}
+		if err := tx.Create(&vaultKey).Error; err != nil {
+			return err
+		}
+
+		vaultPermissions := []models.VaultPermission{
+			{VaultID: vault.ID, UserID: creator.ID, Permission: models.VaultPermissionManageVault},
+			{VaultID: vault.ID, UserID: creator.ID, Permission: models.VaultPermissionDeleteVault},
+			{VaultID: vault.ID, UserID: creator.ID, Permission: models.VaultPermissionManageItems},
+			{VaultID: vault.ID, UserID: creator.ID, Permission: models.VaultPermissionRead},
+		}
+		if err := tx.Create(&vaultPermissions).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vault.ID,
+			VaultItemID: 0,
+			UserID:      creator.ID,
+			ActionCode:  models.AuditLogActionVaultCreate,
+			ActionData:  models.AuditLogDataVaultCreate(vault.Name),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		return models.Vault{}, err
+	}
+	return vault, nil
+}
+
+// DeleteVault soft-deletes given vault together with its permissions, keys, items and attachments. Secret shares of
+// the vault items are permanently deleted so that their links stop working. userId is the id of the user who deletes
+// the vault.
+func DeleteVault(db *gorm.DB, vaultId, userId uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.Vault{}, vaultId)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return VaultNotFoundErr{}
+		}
+
+		for _, model := range []any{&models.VaultPermission{}, &models.VaultGroupPermission{}, &models.VaultKey{},
+			&models.VaultInvitation{}, &models.VaultItem{}, &models.VaultItemAttachment{}} {
+			if err := tx.Where("vault_id = ?", vaultId).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("vault_id = ?", vaultId).Delete(&models.SecretShare{}).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.VaultAuditLog{
+			VaultID:     vaultId,
+			VaultItemID: 0,
+			UserID:      userId,
+			ActionCode:  models.AuditLogActionVaultDelete,
+			ActionData:  models.AuditLogDataVaultDelete(),
+		}
+		return tx.Create(&auditLog).Error
+	})
+}
+
+// CreateVaultItemRevision saves the current encrypted payload of given vault item as a revision. It should be called
+// before the vault item is overwritten. userId is the id of the user who is overwriting the vault item. The new
+// payload must be encrypted with new IVs since the revision keeps the current one under the same vault key.
+func CreateVaultItemRevision(db *gorm.DB, vaultItem models.VaultItem, userId uint) (models.VaultItemRevision, error) {
+	revision := models.VaultItemRevision{
+		VaultItemID:       vaultItem.ID,
+		UserID:            userId,
+		Type:              vaultItem.Type,
+		Title:             vaultItem.Title,
+		EncryptionIV:      vaultItem.EncryptionIV,
+		EncryptedUsername: vaultItem.EncryptedUsername,
+		EncryptedPassword: vaultItem.EncryptedPassword,
+		EncryptedNote:     vaultItem.EncryptedNote,
+		DataVersion:       vaultItem.DataVersion,
+		DataEncryptionIV:  vaultItem.DataEncryptionIV,
+		EncryptedData:     vaultItem.EncryptedData,
+	}
+	err := db.Create(&revision).Error
+	return revision, err
+}
@@ -15,106 +15,97 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
+        "/admin/invite-codes": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Login user",
-                "operationId": "authLogin",
+                "summary": "List invite codes",
+                "operationId": "adminInviteCodesList",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.HandleAuthLogin.LoginRequest"
-                        }
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "expires_at",
+                            "-expires_at"
+                        ],
+                        "type": "string",
+                        "description": "Ordering",
+                        "name": "ordering",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleAuthLogin.LoginResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/schemas.BadRequestResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleAdminInviteCodesList_InviteCodeResponseItem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Logout user",
-                "operationId": "authLogout",
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/auth/register": {
+            },
             "post": {
+                "description": "The invite code can be used once to register while the registration mode is invite. The code is\nonly returned in this response.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register user",
-                "operationId": "authRegister",
+                "summary": "Create invite code",
+                "operationId": "adminInviteCodesCreate",
                 "parameters": [
                     {
-                        "description": "User Registration Data",
+                        "description": "Invite code data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleAuthRegister.RegisterRequest"
+                            "$ref": "#/definitions/controllers.HandleAdminInviteCodesCreate.CreateInviteCodeRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                            "$ref": "#/definitions/controllers.HandleAdminInviteCodesCreate.CreateInviteCodeResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -127,83 +118,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/metrics/status": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "metrics"
-                ],
-                "summary": "Get status of the server",
-                "operationId": "getServerStatus",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.HandleMetricsStatus.MetricsStatusResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/users/by-email": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
+        "/admin/invite-codes/{id}": {
+            "delete": {
+                "description": "Used invite codes can't be revoked.",
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Get user by email",
-                "operationId": "getUserByEmail",
+                "summary": "Revoke invite code",
+                "operationId": "adminInviteCodesRevoke",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Email of the user",
-                        "name": "email",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "Invite code id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleGetUserByEmail.UserResponse"
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
-                    "404": {
-                        "description": "Not Found"
+                    "403": {
+                        "description": "Forbidden"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/users/me": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Get currently logged-in user",
-                "operationId": "getCurrentUser",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleUsersMe.MeResponse"
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -211,17 +169,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/vaults": {
+        "/admin/users": {
             "get": {
+                "description": "search matches the email or the name of the user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vaults"
+                    "admin"
                 ],
-                "summary": "List vaults that user has read access to",
-                "operationId": "listVaults",
+                "summary": "List users",
+                "operationId": "adminUsersList",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by active status",
+                        "name": "is_active",
+                        "in": "query"
+                    },
                     {
                         "minimum": 1,
                         "type": "integer",
@@ -239,8 +210,8 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
-                            "name",
-                            "-name",
+                            "email",
+                            "-email",
                             "created_at",
                             "-created_at"
                         ],
@@ -254,51 +225,70 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultsList_VaultResponseItem"
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleAdminUsersList_UserResponseItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "post": {
-                "produces": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/users/{id}": {
+            "delete": {
+                "description": "The user is removed from their vaults and groups, vaults and groups which don't have any other\nmember are deleted. 409 is returned if the user is the last manager of a vault or a group which\nhas other members, another member must be given the manager permission first.",
                 "tags": [
-                    "vaults"
+                    "admin"
                 ],
-                "summary": "Create a new vault",
-                "operationId": "createVault",
+                "summary": "Delete user",
+                "operationId": "adminUsersDelete",
                 "parameters": [
                     {
-                        "description": "New vault data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsCreate.VaultCreateRequest"
-                        }
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsCreate.VaultCreateResponse"
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
                         }
                     },
                     "500": {
@@ -307,31 +297,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/vaults/{id}": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
+        "/admin/users/{id}/deactivate": {
+            "post": {
+                "description": "Every session of the user is revoked and the user can't login or use their personal access tokens\nuntil they are reactivated.",
                 "tags": [
-                    "vaults"
+                    "admin"
                 ],
-                "summary": "Retrieve vault by id",
-                "operationId": "retrieveVault",
+                "summary": "Deactivate user",
+                "operationId": "adminUsersDeactivate",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Vault id",
+                        "description": "User id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsCreate.VaultCreateResponse"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -342,35 +327,14 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            },
-            "delete": {
-                "tags": [
-                    "vaults"
-                ],
-                "summary": "Delete vault by id",
-                "operationId": "deleteVault",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "401": {
-                        "description": "Unauthorized"
+                    "403": {
+                        "description": "Forbidden"
                     },
                     "404": {
-                        "description": "Not Found"
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -378,64 +342,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/vaults/{id}/items": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
+        "/admin/users/{id}/reactivate": {
+            "post": {
+                "description": "If email verification is required, users who haven't verified their email address stay inactive\nuntil they verify it. 400 is returned if the user isn't deactivated.",
                 "tags": [
-                    "vault items"
+                    "admin"
                 ],
-                "summary": "List items of a vault",
-                "operationId": "listVaultItems",
+                "summary": "Reactivate user",
+                "operationId": "adminUsersReactivate",
                 "parameters": [
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Item count per page",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "title",
-                            "-title",
-                            "created_at",
-                            "-created_at"
-                        ],
-                        "type": "string",
-                        "description": "Ordering",
-                        "name": "ordering",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Search by title",
-                        "name": "title",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
-                        "description": "Vault id",
+                        "description": "User id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultItemsList_VaultItemResponseItem"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -449,43 +375,51 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
+            }
+        },
+        "/auth/login": {
             "post": {
+                "description": "If the user has two-factor authentication enabled, no session is created and 202 is returned with\na token which should be sent to the second step of the login together with the second factor. The\nattempt counts as failed until the second factor is verified. webauthn_options are passed to navigator.credentials.get() if the user has a passkey.\nFailed logins are delayed per IP and email, 429 is returned with Retry-After header when the next\nattempt is delayed. The account is locked temporarily after too many consecutive failures, logins\nto a locked account fail with wrong credentials like logins with an unknown email.\n403 is returned if the user is deactivated or hasn't verified their email address yet.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vault items"
+                    "auth"
                 ],
-                "summary": "Create a new vault item",
-                "operationId": "createVaultItem",
+                "summary": "Login user",
+                "operationId": "authLogin",
                 "parameters": [
                     {
-                        "description": "New vault item data",
+                        "description": "Login credentials",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultItemsCreate.VaultItemCreateRequest"
+                            "$ref": "#/definitions/controllers.HandleAuthLogin.LoginRequest"
                         }
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultItemsCreate.VaultItemCreateResponse"
+                            "$ref": "#/definitions/controllers.HandleAuthLogin.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthLogin.TwoFactorRequiredResponse"
                         }
                     },
                     "400": {
@@ -494,11 +428,11 @@ const docTemplate = `{
                             "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
                     "403": {
-                        "description": "Forbidden"
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
+                        }
                     },
                     "422": {
                         "description": "Unprocessable Entity",
@@ -506,40 +440,45 @@ const docTemplate = `{
                             "$ref": "#/definitions/bodybinder.validationErrorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/vaults/{id}/items/{itemId}": {
-            "get": {
+        "/auth/login/totp": {
+            "post": {
+                "description": "Wrong codes count as failed logins of the user and lock the account like wrong passwords. Attempts\nare delayed per IP and email like the logins, codes of a locked account are rejected as wrong.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "vault items"
+                    "auth"
                 ],
-                "summary": "Retrieve a new vault item",
-                "operationId": "retrieveVaultItem",
+                "summary": "Complete login with a TOTP or recovery code",
+                "operationId": "authLoginTOTP",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Vault Item id",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
+                        "description": "Login challenge token and the code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthLoginTOTP.LoginTOTPRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultItemsRetrieve.VaultItemRetrieveResponse"
+                            "$ref": "#/definitions/controllers.HandleAuthLoginTOTP.LoginResponse"
                         }
                     },
                     "400": {
@@ -548,16 +487,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
                     "403": {
-                        "description": "Forbidden"
-                    },
-                    "404": {
-                        "description": "Not Found",
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
                         }
                     },
                     "422": {
@@ -566,50 +499,45 @@ const docTemplate = `{
                             "$ref": "#/definitions/bodybinder.validationErrorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/auth/login/webauthn": {
+            "post": {
+                "description": "credential is the result of navigator.credentials.get() called with the webauthn_options returned\nfrom the login endpoint.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vault items"
+                    "auth"
                 ],
-                "summary": "Update a new vault item",
-                "operationId": "updateVaultItem",
+                "summary": "Complete login with a passkey",
+                "operationId": "authLoginWebAuthn",
                 "parameters": [
                     {
-                        "description": "New vault item data",
+                        "description": "Login challenge token and the passkey assertion",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultItemsUpdate.VaultItemUpdateRequest"
+                            "$ref": "#/definitions/controllers.HandleAuthLoginWebAuthn.LoginWebAuthnRequest"
                         }
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Vault Item id",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultItemsUpdate.VaultItemUpdateResponse"
+                            "$ref": "#/definitions/controllers.HandleAuthLoginWebAuthn.LoginResponse"
                         }
                     },
                     "400": {
@@ -618,16 +546,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
                     "403": {
-                        "description": "Forbidden"
-                    },
-                    "404": {
-                        "description": "Not Found",
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
                         }
                     },
                     "422": {
@@ -640,194 +562,114 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
-                "tags": [
-                    "vault items"
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "Delete a vault item",
-                "operationId": "deleteVaultItem",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Vault Item id",
-                        "name": "itemId",
-                        "in": "path",
-                        "required": true
-                    }
+                "tags": [
+                    "auth"
                 ],
+                "summary": "Logout user",
+                "operationId": "authLogout",
                 "responses": {
                     "204": {
                         "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/schemas.BadRequestResponse"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
-                    "403": {
-                        "description": "Forbidden"
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/schemas.NotFoundResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/vaults/{id}/key": {
+        "/auth/oidc/callback": {
             "get": {
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Called by the identity provider. Starts a session and redirects the browser to the frontend, with\noidc_error query parameter if the login fails. Users created on their first login must set up their\nkeys before using vaults. If the identity matches a user it can't be linked to automatically,\noidc_error is link_required and oidc_link_token is given, the user should log in and confirm the\nlink with /users/me/oidc/link. If the user has a second factor, no session is created and\noidc_error is two_factor_required with two_factor_token, two_factor_methods (comma separated) and\nwebauthn_options (base64url encoded JSON, if webauthn is one of the methods) given like the 202\nresponse of /auth/login. The login is completed with /auth/login/totp or /auth/login/webauthn.",
                 "tags": [
-                    "vaults"
+                    "auth"
                 ],
-                "summary": "Retrieve current user's vault key record for the vault",
-                "operationId": "retrieveMyVaultKey",
+                "summary": "Complete login with the OpenID Connect identity provider",
+                "operationId": "authOIDCCallback",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "State given to the identity provider",
+                        "name": "state",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsMyKey.VaultKeyResponse"
-                        }
+                    "302": {
+                        "description": "Found"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "404": {
-                        "description": "Not Found"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/vaults/{id}/leave": {
-            "post": {
-                "produces": [
-                    "application/json"
-                ],
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redirects the browser to the identity provider which redirects back to /auth/oidc/callback.",
                 "tags": [
-                    "vaults"
-                ],
-                "summary": "Leave from the vault",
-                "operationId": "leaveVault",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Start login with the OpenID Connect identity provider",
+                "operationId": "authOIDCLogin",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "302": {
+                        "description": "Found"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/vaults/{id}/logs": {
-            "get": {
+        "/auth/passkey/begin": {
+            "post": {
+                "description": "options are passed to navigator.credentials.get(), the result is sent to the passkey finish endpoint\ntogether with the token. Passkey logins which aren't finished are delayed per IP.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vaults"
-                ],
-                "summary": "List audit logs of vault",
-                "operationId": "listVaultAuditLogs",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Item count per page",
-                        "name": "page_size",
-                        "in": "query"
-                    }
+                    "auth"
                 ],
+                "summary": "Start passwordless login with a passkey",
+                "operationId": "authPasskeyBegin",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultAuditLogsList_AuditLogResponseItem"
+                            "$ref": "#/definitions/controllers.HandleAuthPasskeyBegin.PasskeyBeginResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "404": {
-                        "description": "Not Found"
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -835,37 +677,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/vaults/{id}/manage/add-user": {
+        "/auth/passkey/finish": {
             "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vault manage"
+                    "auth"
                 ],
-                "summary": "Add user to vault",
-                "operationId": "addUserToVault",
+                "summary": "Finish passwordless login with a passkey",
+                "operationId": "authPasskeyFinish",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "New user data",
+                        "description": "Passkey login token and the passkey assertion",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsManageAddUser.AddUserRequest"
+                            "$ref": "#/definitions/controllers.HandleAuthPasskeyFinish.PasskeyFinishRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthPasskeyFinish.LoginResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -873,11 +711,11 @@ const docTemplate = `{
                             "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
                     "403": {
-                        "description": "Forbidden"
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
+                        }
                     },
                     "422": {
                         "description": "Unprocessable Entity",
@@ -891,52 +729,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/vaults/{id}/manage/rename": {
+        "/auth/password-reset": {
             "post": {
+                "description": "The password can't be reset without the recovery key since the vault keys are encrypted with the\nkey pair protected by the password. The mail links to account recovery if the user has a recovery\nkey. 204 is returned whether a mail is sent or not, so the endpoint can't be used to find out\nregistered emails. Requests are delayed per IP and email.",
                 "tags": [
-                    "vault manage"
+                    "auth"
                 ],
-                "summary": "Rename vault",
-                "operationId": "renameVault",
+                "summary": "Request a password reset mail",
+                "operationId": "authPasswordReset",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "New name of the vault",
+                        "description": "Email of the user",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsManageRename.RenameVaultRequest"
+                            "$ref": "#/definitions/controllers.HandleAuthPasswordReset.PasswordResetRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsManageRename.RenameVaultResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/schemas.BadRequestResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "403": {
-                        "description": "Forbidden"
-                    },
-                    "404": {
-                        "description": "Not Found"
+                    "204": {
+                        "description": "No Content"
                     },
                     "422": {
                         "description": "Unprocessable Entity",
@@ -944,73 +758,87 @@ const docTemplate = `{
                             "$ref": "#/definitions/bodybinder.validationErrorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/vaults/{id}/manage/users": {
-            "get": {
+        "/auth/recovery/begin": {
+            "post": {
+                "description": "Returns the private key of the user encrypted with the recovery key and the vault keys which must\nbe encrypted again with the new key pair to complete the recovery. Failed attempts are delayed per\nIP and email like logins.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vault manage"
+                    "auth"
                 ],
-                "summary": "List users who have access to vault",
-                "operationId": "listVaultUsers",
+                "summary": "Start account recovery with a recovery key",
+                "operationId": "authRecoveryBegin",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Email and recovery key verifier",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthRecoveryBegin.RecoveryBeginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/controllers.HandleVaultsManageListUsers.UsersResponseItem"
-                            }
+                            "$ref": "#/definitions/controllers.HandleAuthRecoveryBegin.RecoveryBeginResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
                     },
-                    "403": {
-                        "description": "Forbidden"
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/recovery/complete": {
+            "post": {
+                "description": "Works like password change, every vault key returned from /auth/recovery/begin must be encrypted\nagain with the new key pair. The recovery key, emergency accesses and every session of the user\nare removed.",
                 "tags": [
-                    "vault manage"
+                    "auth"
                 ],
-                "summary": "Remove user from vault",
-                "operationId": "removeUserFromVault",
+                "summary": "Complete account recovery by setting a new password",
+                "operationId": "authRecoveryComplete",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "ID of the user which will be removed",
+                        "description": "Recovery key verifier and new password with the re-encrypted vault keys",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.HandleVaultsManageRemoveUser.RemoveUserRequest"
+                            "$ref": "#/definitions/controllers.HandleAuthRecoveryComplete.RecoveryCompleteRequest"
                         }
                     }
                 ],
@@ -1024,53 +852,276 @@ const docTemplate = `{
                             "$ref": "#/definitions/schemas.BadRequestResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "403": {
-                        "description": "Forbidden"
-                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/bodybinder.validationErrorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/vaults/{id}/my-permissions": {
-            "get": {
+        "/auth/register": {
+            "post": {
+                "description": "If email verification is required, a verification mail is sent and the user can't login until the\nemail is verified. 403 is returned if the registration policy doesn't allow the registration, see\n/auth/registration-policy. invite_code is required if the registration mode is invite. The password\nmust meet the password policy.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vaults"
+                    "auth"
                 ],
-                "summary": "List current user's permission on vault",
-                "operationId": "listMyVaultPermissions",
+                "summary": "Register user",
+                "operationId": "authRegister",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Vault id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "User Registration Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthRegister.RegisterRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthRegister.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/auth/registration-policy": {
+            "get": {
+                "description": "mode is open, closed or invite. Only emails of allowed_domains can register if it isn't empty.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get registration policy",
+                "operationId": "authRegistrationPolicy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthRegistrationPolicy.RegistrationPolicyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Activates the user with the token from the verification mail.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email address",
+                "operationId": "authVerifyEmail",
+                "parameters": [
+                    {
+                        "description": "Token from the verification mail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthVerifyEmail.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/auth/verify-email/resend": {
+            "post": {
+                "description": "Previous verification links stop working. 204 is returned whether a mail is sent or not, so the\nendpoint can't be used to find out registered emails. Requests are delayed per IP and email.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Send the verification mail again",
+                "operationId": "authVerifyEmailResend",
+                "parameters": [
+                    {
+                        "description": "Email of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleAuthVerifyEmailResend.VerifyEmailResendRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "List groups that user is member of",
+                "operationId": "listGroups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/controllers.HandleGroupsList.GroupResponseItem"
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create a new group",
+                "operationId": "createGroup",
+                "parameters": [
+                    {
+                        "description": "New group data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleGroupsCreate.GroupCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleGroupsCreate.GroupCreateResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/groups/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Retrieve group by id with its members",
+                "operationId": "retrieveGroup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleGroupsRetrieve.GroupResponse"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -1080,217 +1131,6554 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "bodybinder.validationErrorResponse": {
-            "type": "object",
-            "required": [
-                "errors"
-            ],
-            "properties": {
-                "errors": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/bodybinder.validationErrorResponseItem"
+            },
+            "delete": {
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete group",
+                "operationId": "deleteGroup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "bodybinder.validationErrorResponseItem": {
-            "type": "object",
-            "required": [
-                "field",
-                "reason"
-            ],
-            "properties": {
-                "field": {
-                    "type": "string"
-                },
-                "reason": {
-                    "type": "string"
+        "/groups/{id}/members": {
+            "post": {
+                "description": "New member gets access to the vaults of the group once a manager of each vault shares the vault key\nwith them.",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Add user to group",
+                "operationId": "addGroupMember",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New member data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleGroupsAddMember.AddMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/groups/{id}/members/{userId}": {
+            "delete": {
+                "description": "Group managers can remove any member, other members can only remove themselves.",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Remove user from group",
+                "operationId": "removeGroupMember",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/metrics/status": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Get status of the server",
+                "operationId": "getServerStatus",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleMetricsStatus.MetricsStatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/share/{token}": {
+            "get": {
+                "description": "Doesn't require authentication. Every request counts as a view and the share is deleted once it's\nviewed as many times as it's allowed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shares"
+                ],
+                "summary": "Retrieve a shared secret",
+                "operationId": "retrieveSecretShare",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleSecretSharesRetrieve.ShareRetrieveResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/by-email": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by email",
+                "operationId": "getUserByEmail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email of the user",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleGetUserByEmail.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get currently logged-in user",
+                "operationId": "getCurrentUser",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMe.MeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/change-password": {
+            "post": {
+                "description": "Key pair of the user is derived from the password, so every vault key listed in\n/users/me/vault-keys must be encrypted again with the new key pair. Owned vault keys are encrypted\nwith the user's own new public key. private_key is the new private key encrypted with the new\npassword, stored private key is removed if it isn't given. Recovery key and emergency accesses of\nthe user are removed since they are encrypted with the old key pair. Every other session of the\nuser is logged out. The new password must meet the password policy. Wrong old passwords are rate\nlimited per user.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change password",
+                "operationId": "usersMeChangePassword",
+                "parameters": [
+                    {
+                        "description": "Old and new password with the re-encrypted vault keys",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeChangePassword.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-contacts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "List emergency contacts of the user",
+                "operationId": "usersMeEmergencyContactsList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleUsersMeEmergencyContactsList.EmergencyContactResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "The emergency contact can request access to the account, which is granted automatically after\nwaiting_period_days unless the user denies the request. encrypted_private_key is the private key\nof the user encrypted with the private key of the user and the public key of the contact, like\nvault keys.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Add an emergency contact",
+                "operationId": "usersMeEmergencyContactsCreate",
+                "parameters": [
+                    {
+                        "description": "Emergency contact data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeEmergencyContactsCreate.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeEmergencyContactsCreate.CreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-contacts/{id}": {
+            "delete": {
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Remove an emergency contact",
+                "operationId": "usersMeEmergencyContactsDelete",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Emergency access id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-contacts/{id}/approve": {
+            "post": {
+                "description": "Access is granted immediately instead of waiting for the waiting period to pass.",
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Approve access request of an emergency contact",
+                "operationId": "usersMeEmergencyContactsApprove",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Emergency access id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-contacts/{id}/deny": {
+            "post": {
+                "description": "Access which is already granted is revoked too. The contact can request access again later.",
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Deny access request of an emergency contact",
+                "operationId": "usersMeEmergencyContactsDeny",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Emergency access id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-grants": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "List emergency accesses granted to the user",
+                "operationId": "usersMeEmergencyGrantsList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleUsersMeEmergencyGrantsList.EmergencyGrantResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-grants/{id}": {
+            "delete": {
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Give up an emergency access granted to the user",
+                "operationId": "usersMeEmergencyGrantsDelete",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Emergency access id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-grants/{id}/request": {
+            "post": {
+                "description": "Access is granted when the grantor approves the request or the waiting period passes without the\ngrantor denying it.",
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Request emergency access to an account",
+                "operationId": "usersMeEmergencyGrantsRequest",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Emergency access id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/emergency-grants/{id}/takeover": {
+            "get": {
+                "description": "Returns the private key of the grantor encrypted for the user and the vault keys of the grantor\nwhich must be encrypted again with the new key pair of the grantor on takeover. Access must be\ngranted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Get the keys needed to take over an account",
+                "operationId": "usersMeEmergencyGrantsTakeoverGet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Emergency access id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeEmergencyGrantsTakeoverGet.TakeoverResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Works like password change of the grantor, every vault key returned from the takeover GET endpoint\nmust be encrypted again with the new key pair of the grantor. Every session, the recovery key and\nthe emergency accesses of the grantor are removed. Access must be granted.",
+                "tags": [
+                    "emergency access"
+                ],
+                "summary": "Take over an account by setting a new password",
+                "operationId": "usersMeEmergencyGrantsTakeover",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Emergency access id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New password of the grantor with the re-encrypted vault keys",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeEmergencyGrantsTakeover.TakeoverRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/failed-logins": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List failed login attempts to the account of the user",
+                "operationId": "usersMeFailedLoginsList",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleUsersMeFailedLoginsList_FailedLoginResponseItem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/invitations": {
+            "get": {
+                "description": "The list is empty until the user verifies their email address.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List vault invitations sent to the user",
+                "operationId": "listMyVaultInvitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleUsersMeInvitationsList.InvitationResponseItem"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/invitations/{id}/accept": {
+            "post": {
+                "description": "User gets access to the vault once a vault manager completes the invitation.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Accept a vault invitation",
+                "operationId": "acceptVaultInvitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invitation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/invitations/{id}/decline": {
+            "post": {
+                "tags": [
+                    "users"
+                ],
+                "summary": "Decline a vault invitation",
+                "operationId": "declineVaultInvitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invitation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/keys": {
+            "get": {
+                "description": "Returns the same key data as login, for users who logged in without a password like with OpenID\nConnect. has_keys is false if the user hasn't set up their keys yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get keys of the user",
+                "operationId": "usersMeKeysGet",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeKeysGet.KeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Users created on their first OpenID Connect login don't have a password and keys. The password is\nused to derive the key pair on the client like on registration and to confirm sensitive operations.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set up keys of the user",
+                "operationId": "usersMeKeysSetUp",
+                "parameters": [
+                    {
+                        "description": "Password and keys",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeKeysSetUp.KeysSetUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/oidc/link": {
+            "post": {
+                "description": "link_token is given to the frontend when the identity can't be linked automatically on login. The\npassword of the user is required. If the user has a second factor, either code must be a TOTP or\nrecovery code, or challenge_token and credential must be the result of a passkey challenge, see\n/users/me/oidc/link/passkey-challenge.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Link an OpenID Connect identity to the user",
+                "operationId": "usersMeOIDCLink",
+                "parameters": [
+                    {
+                        "description": "Link token, password and second factor",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeOIDCLink.LinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/oidc/link/passkey-challenge": {
+            "post": {
+                "description": "options are passed to navigator.credentials.get(), the result is sent to /users/me/oidc/link\ntogether with the token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Start confirming an OpenID Connect link with a passkey",
+                "operationId": "usersMeOIDCLinkPasskeyChallenge",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeOIDCLinkPasskeyChallenge.PasskeyChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/private-key": {
+            "put": {
+                "description": "Stores the private key of the user encrypted on the client, so it is returned on login from a new\ndevice. Can be used by users registered before private keys were stored and to move the private key\nto a newer envelope version.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Store encrypted private key",
+                "operationId": "usersMePrivateKeyUpdate",
+                "parameters": [
+                    {
+                        "description": "Password and private key envelope",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMePrivateKeyUpdate.PrivateKeyUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.TooManyRequestsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/recovery-key": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get recovery key status of the user",
+                "operationId": "usersMeRecoveryKeyGet",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeRecoveryKeyGet.RecoveryKeyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "description": "The recovery key is generated on the client and never sent to the server. private_key is the\nprivate key of the user encrypted with the recovery key and recovery_key_verifier is a value derived\nfrom the recovery key which is required to recover the account. Previous recovery key of the user\nstops working.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set up a new recovery key",
+                "operationId": "usersMeRecoveryKeyUpdate",
+                "parameters": [
+                    {
+                        "description": "Password, verifier and the encrypted private key",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeRecoveryKeyUpdate.RecoveryKeyUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete recovery key of the user",
+                "operationId": "usersMeRecoveryKeyDelete",
+                "parameters": [
+                    {
+                        "description": "Current password of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeRecoveryKeyDelete.DeleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List active sessions of the user",
+                "operationId": "usersMeSessionsList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleUsersMeSessionsList.SessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/revoke-others": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Log out everywhere else",
+                "operationId": "usersMeSessionsRevokeOthers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeSessionsRevokeOthers.RevokeOthersResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{id}": {
+            "delete": {
+                "description": "The current session can't be revoked with this endpoint, logout should be used instead.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke a session of the user",
+                "operationId": "usersMeSessionsDelete",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/tokens": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List personal access tokens of the user",
+                "operationId": "usersMeTokensList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleUsersMeTokensList.TokenResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "The token is sent in Authorization: Bearer header. It can only read the given vaults and can't be\nused to manage the account. The token is only returned in this response.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a personal access token",
+                "operationId": "usersMeTokensCreate",
+                "parameters": [
+                    {
+                        "description": "Token data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTokensCreate.CreateTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTokensCreate.CreateTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/tokens/{id}": {
+            "delete": {
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke a personal access token",
+                "operationId": "usersMeTokensRevoke",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/totp/confirm": {
+            "post": {
+                "description": "Enables TOTP if the code is valid and returns the recovery codes. Recovery codes can't be retrieved\nagain later.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirm TOTP enrollment",
+                "operationId": "usersMeTOTPConfirm",
+                "parameters": [
+                    {
+                        "description": "Code generated by the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTOTPConfirm.ConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTOTPConfirm.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/totp/disable": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Disable TOTP",
+                "operationId": "usersMeTOTPDisable",
+                "parameters": [
+                    {
+                        "description": "Current password and a TOTP or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTOTPDisable.DisableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/totp/enroll": {
+            "post": {
+                "description": "Generates a new TOTP secret. TOTP is not required on login until the enrollment is confirmed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Start TOTP enrollment",
+                "operationId": "usersMeTOTPEnroll",
+                "parameters": [
+                    {
+                        "description": "Current password of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTOTPEnroll.EnrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTOTPEnroll.EnrollResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/totp/recovery-codes": {
+            "post": {
+                "description": "Replaces the recovery codes of the user with new ones. Old recovery codes stop working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Regenerate recovery codes",
+                "operationId": "usersMeTOTPRecoveryCodes",
+                "parameters": [
+                    {
+                        "description": "A TOTP or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTOTPRecoveryCodes.RegenerateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeTOTPRecoveryCodes.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/vault-keys": {
+            "get": {
+                "description": "owned vault keys are decrypted with the inviter's public key and issued vault keys are the ones the\nuser encrypted for other users. All of them must be encrypted again with the new key pair on\npassword change.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List vault keys encrypted with the key pair of the user",
+                "operationId": "usersMeVaultKeysList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeVaultKeysList.VaultKeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/webauthn/credentials": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List passkeys of the user",
+                "operationId": "usersMeWebAuthnCredentialsList",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleUsersMeWebAuthnCredentialsList.CredentialResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/webauthn/credentials/{id}": {
+            "delete": {
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a passkey of the user",
+                "operationId": "usersMeWebAuthnCredentialsDelete",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Passkey id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Current password of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeWebAuthnCredentialsDelete.DeleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/webauthn/register/begin": {
+            "post": {
+                "description": "options are passed to navigator.credentials.create(), the result is sent to the register finish\nendpoint.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Start registering a passkey",
+                "operationId": "usersMeWebAuthnRegisterBegin",
+                "parameters": [
+                    {
+                        "description": "Current password of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeWebAuthnRegisterBegin.RegisterBeginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeWebAuthnRegisterBegin.RegisterBeginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/me/webauthn/register/finish": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Finish registering a passkey",
+                "operationId": "usersMeWebAuthnRegisterFinish",
+                "parameters": [
+                    {
+                        "description": "Name of the passkey and the result of the authenticator",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeWebAuthnRegisterFinish.RegisterFinishRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleUsersMeWebAuthnRegisterFinish.CredentialResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "List vaults that user has read access to",
+                "operationId": "listVaults",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "description": "Ordering",
+                        "name": "ordering",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultsList_VaultResponseItem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "Create a new vault",
+                "operationId": "createVault",
+                "parameters": [
+                    {
+                        "description": "New vault data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsCreate.VaultCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsCreate.VaultCreateResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/trash": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault trash"
+                ],
+                "summary": "List deleted vaults that user can restore",
+                "operationId": "listDeletedVaults",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultsListTrash_DeletedVaultResponseItem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "Retrieve vault by id",
+                "operationId": "retrieveVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsCreate.VaultCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "Delete vault by id",
+                "operationId": "deleteVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "List items of a vault",
+                "operationId": "listVaultItems",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "title",
+                            "-title",
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "description": "Ordering",
+                        "name": "ordering",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultItemsList_VaultItemResponseItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "type is one of login, secure_note, credit_card, api_key and ssh_key, it is login if it isn't given.\ndata holds the fields of the item, encrypted_username, encrypted_password and encrypted_note are\nkept for older clients.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Create a new vault item",
+                "operationId": "createVaultItem",
+                "parameters": [
+                    {
+                        "description": "New vault item data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemsCreate.VaultItemCreateRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemsCreate.VaultItemCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items/{itemId}": {
+            "get": {
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Retrieve a new vault item",
+                "operationId": "retrieveVaultItem",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemsRetrieve.VaultItemRetrieveResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "description": "type of the item is kept if it isn't given. data must be given if the item already has data, so\nolder clients which only update the legacy fields can't leave it stale. encryption_iv and\ndata.encryption_iv must be new IVs, since the previous payload is kept as a revision which is\nencrypted with the same vault key.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Update a new vault item",
+                "operationId": "updateVaultItem",
+                "parameters": [
+                    {
+                        "description": "New vault item data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemsUpdate.VaultItemUpdateRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemsUpdate.VaultItemUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Delete a vault item",
+                "operationId": "deleteVaultItem",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items/{itemId}/attachments": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "List attachments of a vault item",
+                "operationId": "listVaultItemAttachments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleVaultItemAttachmentsList.AttachmentResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Request body is the file content encrypted on the client, it is streamed to the storage as it is.\nX-Encrypted-Metadata should hold the file name, the content type and the key the content is\nencrypted with, encrypted with the vault key. Attachments can't be larger than the maximum\nattachment size and can't exceed the attachment quota of the vault.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Upload an attachment to a vault item",
+                "operationId": "uploadVaultItemAttachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Encryption IV of the metadata",
+                        "name": "X-Encryption-IV",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encrypted metadata of the attachment",
+                        "name": "X-Encrypted-Metadata",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Encrypted file content",
+                        "name": "content",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemAttachmentsUpload.AttachmentUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.PayloadTooLargeResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items/{itemId}/attachments/{attachmentId}": {
+            "get": {
+                "description": "Response body is the encrypted file content as it was uploaded.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Download an attachment of a vault item",
+                "operationId": "downloadVaultItemAttachment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment id",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "The encrypted file content is removed from the storage shortly after.",
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Delete an attachment of a vault item",
+                "operationId": "deleteVaultItemAttachment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment id",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items/{itemId}/revisions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "List previous revisions of a vault item",
+                "operationId": "listVaultItemRevisions",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultItemRevisionsList_RevisionResponseItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items/{itemId}/revisions/{revId}/restore": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Restore a vault item to one of its previous revisions",
+                "operationId": "restoreVaultItemRevision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item Revision id",
+                        "name": "revId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemRevisionsRestore.VaultItemRestoreResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items/{itemId}/shares": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "List active share links of a vault item",
+                "operationId": "listVaultItemShares",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleVaultItemSharesList.ShareResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "encrypted_data should be encrypted with a key which is not sent to the server, e.g. a key kept in\nthe fragment of the share link. Returned token can't be retrieved again. max_views is at most 10\nand expires_at must be within the maximum share lifetime configured on the server.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Create a share link for a vault item",
+                "operationId": "createVaultItemShare",
+                "parameters": [
+                    {
+                        "description": "Share data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemSharesCreate.ShareCreateRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultItemSharesCreate.ShareCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/items/{itemId}/shares/{shareId}": {
+            "delete": {
+                "description": "Share creator or users with manage_items permission can revoke a share.",
+                "tags": [
+                    "vault items"
+                ],
+                "summary": "Revoke a share link of a vault item",
+                "operationId": "revokeVaultItemShare",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Share id",
+                        "name": "shareId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/key": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "Retrieve current user's vault key record for the vault",
+                "operationId": "retrieveMyVaultKey",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsMyKey.VaultKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/leave": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "Leave from the vault",
+                "operationId": "leaveVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/logs": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "List audit logs of vault",
+                "operationId": "listVaultAuditLogs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultAuditLogsList_AuditLogResponseItem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/add-user": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Add user to vault",
+                "operationId": "addUserToVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New user data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageAddUser.AddUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/groups": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "List groups that have access to vault",
+                "operationId": "listVaultGroups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleVaultsManageListGroups.GroupsResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Read permission is given to the group in addition to the given permissions. keys should contain the\nvault key encrypted for the group members who don't have the vault key yet, members left out can\nbe given the vault key later through the pending keys endpoint. Only the managers of the group can\nadd it to a vault.",
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Give a group access to vault",
+                "operationId": "addGroupToVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Group and its permissions",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageAddGroup.AddGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/groups/{groupId}": {
+            "delete": {
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Remove a group's access to vault",
+                "operationId": "removeGroupFromVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group id",
+                        "name": "groupId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/invitations": {
+            "get": {
+                "description": "Accepted invitations contain the public key of the invitee which the vault key should be encrypted\nwith to complete the invitation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "List open invitations of vault",
+                "operationId": "listVaultInvitations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleVaultsManageListInvitations.InvitationResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Unlike add-user, the invited email doesn't need to belong to a registered user. Once the invitee\naccepts the invitation, a vault manager completes it by sharing the vault key with the invitee.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Invite an email address to vault",
+                "operationId": "inviteUserToVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitation data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageInviteUser.InviteUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageInviteUser.InviteUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/invitations/{invitationId}": {
+            "delete": {
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Revoke an invitation of vault",
+                "operationId": "revokeVaultInvitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Invitation id",
+                        "name": "invitationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/invitations/{invitationId}/complete": {
+            "post": {
+                "description": "Gives the invitee access to the vault with the permissions in the invitation. Vault key must be\nencrypted with the public key of the invitee.",
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Complete an accepted invitation of vault",
+                "operationId": "completeVaultInvitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Invitation id",
+                        "name": "invitationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vault key encrypted for the invitee",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageCompleteInvitation.CompleteInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/pending-keys": {
+            "get": {
+                "description": "Lists the users who have access to the vault through a group but don't have the vault key yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "List users waiting for the vault key",
+                "operationId": "listVaultPendingKeys",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleVaultsManageListPendingKeys.PendingKeyResponseItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Share the vault key with users waiting for it",
+                "operationId": "shareVaultPendingKeys",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vault key encrypted for each user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageSharePendingKeys.SharePendingKeysRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/rename": {
+            "post": {
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Rename vault",
+                "operationId": "renameVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name of the vault",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageRename.RenameVaultRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageRename.RenameVaultResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/rotate-key": {
+            "post": {
+                "description": "Replaces the vault key of every vault user and re-encrypts every vault item with the new vault key.\nRequest must contain exactly the current users and items of the vault. Revisions and deleted items\nof the vault are permanently deleted since they are encrypted with the old vault key. data of the\nitems which have one and the metadata of every attachment of the vault must be re-encrypted too.",
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Rotate the vault key",
+                "operationId": "rotateVaultKey",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Re-encrypted vault items and vault keys",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageRotateKey.RotateKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/transfer-ownership": {
+            "post": {
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Transfer ownership of the vault to another vault user",
+                "operationId": "transferVaultOwnership",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID of the new owner",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageTransferOwnership.TransferOwnershipRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/users": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "List users who have access to vault",
+                "operationId": "listVaultUsers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.HandleVaultsManageListUsers.UsersResponseItem"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Remove user from vault",
+                "operationId": "removeUserFromVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID of the user which will be removed",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageRemoveUser.RemoveUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/manage/users/{userId}": {
+            "patch": {
+                "tags": [
+                    "vault manage"
+                ],
+                "summary": "Replace permissions of a vault user",
+                "operationId": "updateVaultUserPermissions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New permissions of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageUpdateUserPermissions.UpdatePermissionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HandleVaultsManageUpdateUserPermissions.UpdatePermissionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/bodybinder.validationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/my-permissions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaults"
+                ],
+                "summary": "List current user's permission on vault",
+                "operationId": "listMyVaultPermissions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/restore": {
+            "post": {
+                "tags": [
+                    "vault trash"
+                ],
+                "summary": "Restore a deleted vault",
+                "operationId": "restoreVault",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/trash": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vault trash"
+                ],
+                "summary": "List deleted items of a vault",
+                "operationId": "listDeletedVaultItems",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Item count per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.StandardPaginationResponse-controllers_HandleVaultTrashList_DeletedVaultItemResponseItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vaults/{id}/trash/{itemId}/restore": {
+            "post": {
+                "description": "409 is returned if the attachments of the item don't fit in the attachment quota of the vault.",
+                "tags": [
+                    "vault trash"
+                ],
+                "summary": "Restore a deleted vault item",
+                "operationId": "restoreVaultItem",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Vault id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vault Item id",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.NotFoundResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "bodybinder.validationErrorResponse": {
+            "type": "object",
+            "required": [
+                "errors"
+            ],
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/bodybinder.validationErrorResponseItem"
+                    }
+                }
+            }
+        },
+        "bodybinder.validationErrorResponseItem": {
+            "type": "object",
+            "required": [
+                "field",
+                "reason"
+            ],
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAdminInviteCodesCreate.CreateInviteCodeRequest": {
+            "type": "object",
+            "required": [
+                "expires_in_days"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 90,
+                    "minimum": 1
+                }
+            }
+        },
+        "controllers.HandleAdminInviteCodesCreate.CreateInviteCodeResponse": {
+            "type": "object",
+            "required": [
+                "code",
+                "expires_at",
+                "id"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleAdminInviteCodesList.InviteCodeResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "created_by_user_id",
+                "expires_at",
+                "id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_user_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "used_at": {
+                    "type": "string"
+                },
+                "used_by_user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleAdminUsersList.UserResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "email",
+                "id",
+                "is_active",
+                "name",
+                "role",
+                "totp_enabled"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deactivated_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.HandleAuthLogin.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthLogin.LoginResponse": {
+            "type": "object",
+            "required": [
+                "email",
+                "key_derivation_salt",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                }
+            }
+        },
+        "controllers.HandleAuthLogin.TwoFactorRequiredResponse": {
+            "type": "object",
+            "required": [
+                "methods",
+                "two_factor_token"
+            ],
+            "properties": {
+                "methods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "two_factor_token": {
+                    "type": "string"
+                },
+                "webauthn_options": {
+                    "description": "WebAuthnOptions is set if webauthn is one of the methods.",
+                    "type": "object"
+                }
+            }
+        },
+        "controllers.HandleAuthLoginTOTP.LoginResponse": {
+            "type": "object",
+            "required": [
+                "email",
+                "key_derivation_salt",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                }
+            }
+        },
+        "controllers.HandleAuthLoginTOTP.LoginTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "two_factor_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "two_factor_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthLoginWebAuthn.LoginResponse": {
+            "type": "object",
+            "required": [
+                "email",
+                "key_derivation_salt",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                }
+            }
+        },
+        "controllers.HandleAuthLoginWebAuthn.LoginWebAuthnRequest": {
+            "type": "object",
+            "required": [
+                "credential",
+                "two_factor_token"
+            ],
+            "properties": {
+                "credential": {
+                    "type": "object"
+                },
+                "two_factor_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthPasskeyBegin.PasskeyBeginResponse": {
+            "type": "object",
+            "required": [
+                "options",
+                "token"
+            ],
+            "properties": {
+                "options": {
+                    "type": "object"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthPasskeyFinish.LoginResponse": {
+            "type": "object",
+            "required": [
+                "email",
+                "key_derivation_salt",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                }
+            }
+        },
+        "controllers.HandleAuthPasskeyFinish.PasskeyFinishRequest": {
+            "type": "object",
+            "required": [
+                "credential",
+                "token"
+            ],
+            "properties": {
+                "credential": {
+                    "type": "object"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthPasswordReset.PasswordResetRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthRecoveryBegin.RecoveryBeginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "recovery_key_verifier"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "recovery_key_verifier": {
+                    "type": "string",
+                    "maxLength": 72
+                }
+            }
+        },
+        "controllers.HandleAuthRecoveryBegin.RecoveryBeginResponse": {
+            "type": "object",
+            "required": [
+                "issued",
+                "owned",
+                "private_key"
+            ],
+            "properties": {
+                "issued": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.issuedVaultKey"
+                    }
+                },
+                "owned": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ownedVaultKey"
+                    }
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                }
+            }
+        },
+        "controllers.HandleAuthRecoveryComplete.RecoveryCompleteRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "key_derivation_salt",
+                "new_password",
+                "public_key",
+                "recovery_key_verifier",
+                "vault_keys"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                },
+                "public_key": {
+                    "type": "string"
+                },
+                "recovery_key_verifier": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "vault_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.rewrappedVaultKey"
+                    }
+                }
+            }
+        },
+        "controllers.HandleAuthRegister.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "key_derivation_salt",
+                "name",
+                "password",
+                "public_key"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "invite_code": {
+                    "type": "string"
+                },
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                },
+                "public_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthRegister.RegisterResponse": {
+            "type": "object",
+            "required": [
+                "email_verification_required"
+            ],
+            "properties": {
+                "email_verification_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.HandleAuthRegistrationPolicy.RegistrationPolicyResponse": {
+            "type": "object",
+            "required": [
+                "allowed_domains",
+                "mode"
+            ],
+            "properties": {
+                "allowed_domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mode": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthVerifyEmail.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleAuthVerifyEmailResend.VerifyEmailResendRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleGetUserByEmail.UserResponse": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "public_key"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleGroupsAddMember.AddMemberRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "is_manager": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.HandleGroupsCreate.GroupCreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleGroupsCreate.GroupCreateResponse": {
+            "type": "object",
+            "required": [
+                "id",
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleGroupsList.GroupResponseItem": {
+            "type": "object",
+            "required": [
+                "id",
+                "is_manager",
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "is_manager": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleGroupsRetrieve.GroupMemberResponseItem": {
+            "type": "object",
+            "required": [
+                "email",
+                "id",
+                "is_manager",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_manager": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleGroupsRetrieve.GroupResponse": {
+            "type": "object",
+            "required": [
+                "id",
+                "members",
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleGroupsRetrieve.GroupMemberResponseItem"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleMetricsStatus.MetricsStatusResponse": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleSecretSharesRetrieve.ShareRetrieveResponse": {
+            "type": "object",
+            "required": [
+                "encrypted_data",
+                "expires_at",
+                "remaining_views"
+            ],
+            "properties": {
+                "encrypted_data": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "remaining_views": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleUsersMe.MeResponse": {
+            "type": "object",
+            "required": [
+                "email",
+                "has_keys",
+                "name",
+                "role",
+                "totp_enabled"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "has_keys": {
+                    "description": "HasKeys is false if the user was created with OpenID Connect and hasn't set up their keys yet.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.HandleUsersMeChangePassword.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "key_derivation_salt",
+                "new_password",
+                "old_password",
+                "public_key",
+                "vault_keys"
+            ],
+            "properties": {
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "old_password": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                },
+                "public_key": {
+                    "type": "string"
+                },
+                "vault_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.rewrappedVaultKey"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeEmergencyContactsCreate.CreateRequest": {
+            "type": "object",
+            "required": [
+                "encrypted_private_key",
+                "encryption_iv",
+                "grantee_email",
+                "password",
+                "waiting_period_days"
+            ],
+            "properties": {
+                "encrypted_private_key": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "grantee_email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "waiting_period_days": {
+                    "type": "integer",
+                    "maximum": 90,
+                    "minimum": 1
+                }
+            }
+        },
+        "controllers.HandleUsersMeEmergencyContactsCreate.CreateResponse": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleUsersMeEmergencyContactsList.EmergencyContactResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "grantee_email",
+                "grantee_name",
+                "id",
+                "is_granted",
+                "status",
+                "waiting_period_days"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "grantee_email": {
+                    "type": "string"
+                },
+                "grantee_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_granted": {
+                    "type": "boolean"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "waiting_period_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleUsersMeEmergencyGrantsList.EmergencyGrantResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "grantor_email",
+                "grantor_name",
+                "id",
+                "is_granted",
+                "status",
+                "waiting_period_days"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "grantor_email": {
+                    "type": "string"
+                },
+                "grantor_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_granted": {
+                    "type": "boolean"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "waiting_period_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleUsersMeEmergencyGrantsTakeover.TakeoverRequest": {
+            "type": "object",
+            "required": [
+                "key_derivation_salt",
+                "new_password",
+                "public_key",
+                "vault_keys"
+            ],
+            "properties": {
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                },
+                "public_key": {
+                    "type": "string"
+                },
+                "vault_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.rewrappedVaultKey"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeEmergencyGrantsTakeoverGet.TakeoverResponse": {
+            "type": "object",
+            "required": [
+                "encrypted_private_key",
+                "encryption_iv",
+                "grantor_public_key",
+                "issued",
+                "owned"
+            ],
+            "properties": {
+                "encrypted_private_key": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "grantor_public_key": {
+                    "type": "string"
+                },
+                "issued": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.issuedVaultKey"
+                    }
+                },
+                "owned": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ownedVaultKey"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeFailedLoginsList.FailedLoginResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "ip_address",
+                "user_agent"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeInvitationsList.InvitationResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "id",
+                "inviter_email",
+                "is_accepted",
+                "permissions",
+                "vault_name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inviter_email": {
+                    "type": "string"
+                },
+                "is_accepted": {
+                    "type": "boolean"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "vault_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeKeysGet.KeysResponse": {
+            "type": "object",
+            "required": [
+                "has_keys",
+                "key_derivation_salt"
+            ],
+            "properties": {
+                "has_keys": {
+                    "type": "boolean"
+                },
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                }
+            }
+        },
+        "controllers.HandleUsersMeKeysSetUp.KeysSetUpRequest": {
+            "type": "object",
+            "required": [
+                "key_derivation_salt",
+                "password",
+                "public_key"
+            ],
+            "properties": {
+                "key_derivation_salt": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                },
+                "public_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeOIDCLink.LinkRequest": {
+            "type": "object",
+            "required": [
+                "link_token",
+                "password"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "credential": {
+                    "type": "object"
+                },
+                "link_token": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeOIDCLinkPasskeyChallenge.PasskeyChallengeResponse": {
+            "type": "object",
+            "required": [
+                "options",
+                "token"
+            ],
+            "properties": {
+                "options": {
+                    "type": "object"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMePrivateKeyUpdate.PrivateKeyUpdateRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "private_key"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                }
+            }
+        },
+        "controllers.HandleUsersMeRecoveryKeyDelete.DeleteRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeRecoveryKeyGet.RecoveryKeyResponse": {
+            "type": "object",
+            "required": [
+                "created_at"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeRecoveryKeyUpdate.RecoveryKeyUpdateRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "private_key",
+                "recovery_key_verifier"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "private_key": {
+                    "$ref": "#/definitions/controllers.privateKeyEnvelope"
+                },
+                "recovery_key_verifier": {
+                    "type": "string",
+                    "maxLength": 72
+                }
+            }
+        },
+        "controllers.HandleUsersMeSessionsList.SessionResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "expires_at",
+                "id",
+                "is_current",
+                "last_seen_at",
+                "last_seen_ip",
+                "user_agent"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_current": {
+                    "type": "boolean"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "last_seen_ip": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeSessionsRevokeOthers.RevokeOthersResponse": {
+            "type": "object",
+            "required": [
+                "revoked_count"
+            ],
+            "properties": {
+                "revoked_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleUsersMeTOTPConfirm.ConfirmRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeTOTPConfirm.RecoveryCodesResponse": {
+            "type": "object",
+            "required": [
+                "recovery_codes"
+            ],
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeTOTPDisable.DisableRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeTOTPEnroll.EnrollRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeTOTPEnroll.EnrollResponse": {
+            "type": "object",
+            "required": [
+                "provisioning_uri",
+                "secret"
+            ],
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeTOTPRecoveryCodes.RecoveryCodesResponse": {
+            "type": "object",
+            "required": [
+                "recovery_codes"
+            ],
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeTOTPRecoveryCodes.RegenerateRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeTokensCreate.CreateTokenRequest": {
+            "type": "object",
+            "required": [
+                "expires_in_days",
+                "name",
+                "vault_ids"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "vault_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeTokensCreate.CreateTokenResponse": {
+            "type": "object",
+            "required": [
+                "expires_at",
+                "id",
+                "token"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeTokensList.TokenResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "expires_at",
+                "id",
+                "name",
+                "token_prefix",
+                "vault_ids"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "token_prefix": {
+                    "type": "string"
+                },
+                "vault_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeVaultKeysList.VaultKeysResponse": {
+            "type": "object",
+            "required": [
+                "issued",
+                "owned"
+            ],
+            "properties": {
+                "issued": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.issuedVaultKey"
+                    }
+                },
+                "owned": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ownedVaultKey"
+                    }
+                }
+            }
+        },
+        "controllers.HandleUsersMeWebAuthnCredentialsDelete.DeleteRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeWebAuthnCredentialsList.CredentialResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "id",
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeWebAuthnRegisterBegin.RegisterBeginRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeWebAuthnRegisterBegin.RegisterBeginResponse": {
+            "type": "object",
+            "required": [
+                "options"
+            ],
+            "properties": {
+                "options": {
+                    "type": "object"
+                }
+            }
+        },
+        "controllers.HandleUsersMeWebAuthnRegisterFinish.CredentialResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "id",
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleUsersMeWebAuthnRegisterFinish.RegisterFinishRequest": {
+            "type": "object",
+            "required": [
+                "credential",
+                "name"
+            ],
+            "properties": {
+                "credential": {
+                    "type": "object"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "controllers.HandleVaultAuditLogsList.AuditLogResponseItem": {
+            "type": "object",
+            "required": [
+                "action_code",
+                "action_data",
+                "created_at",
+                "id",
+                "user"
+            ],
+            "properties": {
+                "action_code": {
+                    "$ref": "#/definitions/models.AuditLogAction"
+                },
+                "action_data": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/controllers.HandleVaultAuditLogsList.UserData"
+                },
+                "vault_item": {
+                    "$ref": "#/definitions/controllers.HandleVaultAuditLogsList.VaultItemData"
+                }
+            }
+        },
+        "controllers.HandleVaultAuditLogsList.UserData": {
+            "type": "object",
+            "required": [
+                "email",
+                "id"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleVaultAuditLogsList.VaultItemData": {
+            "type": "object",
+            "required": [
+                "id",
+                "title"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemAttachmentsList.AttachmentResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "encrypted_metadata",
+                "encryption_iv",
+                "id",
+                "size",
+                "uploader"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "encrypted_metadata": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "uploader": {
+                    "$ref": "#/definitions/controllers.HandleVaultItemAttachmentsList.AttachmentUploaderData"
+                }
+            }
+        },
+        "controllers.HandleVaultItemAttachmentsList.AttachmentUploaderData": {
+            "type": "object",
+            "required": [
+                "email",
+                "id"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleVaultItemAttachmentsUpload.AttachmentUploadResponse": {
+            "type": "object",
+            "required": [
+                "id",
+                "size"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleVaultItemRevisionsList.RevisionResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "encrypted_note",
+                "encrypted_password",
+                "encrypted_username",
+                "encryption_iv",
+                "id",
+                "replaced_by",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
+                "encrypted_note": {
+                    "type": "string"
+                },
+                "encrypted_password": {
+                    "type": "string"
+                },
+                "encrypted_username": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "replaced_by": {
+                    "$ref": "#/definitions/controllers.HandleVaultItemRevisionsList.RevisionUserData"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemRevisionsList.RevisionUserData": {
+            "type": "object",
+            "required": [
+                "email",
+                "id"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleVaultItemRevisionsRestore.VaultItemRestoreResponse": {
+            "type": "object",
+            "required": [
+                "encrypted_note",
+                "encrypted_password",
+                "encrypted_username",
+                "encryption_iv",
+                "id",
+                "title",
+                "type",
+                "updated_at"
+            ],
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
+                "encrypted_note": {
+                    "type": "string"
+                },
+                "encrypted_password": {
+                    "type": "string"
+                },
+                "encrypted_username": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemSharesCreate.ShareCreateRequest": {
+            "type": "object",
+            "required": [
+                "encrypted_data",
+                "expires_at",
+                "max_views"
+            ],
+            "properties": {
+                "encrypted_data": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_views": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                }
+            }
+        },
+        "controllers.HandleVaultItemSharesCreate.ShareCreateResponse": {
+            "type": "object",
+            "required": [
+                "expires_at",
+                "id",
+                "token"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemSharesList.ShareCreatorData": {
+            "type": "object",
+            "required": [
+                "email",
+                "id"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleVaultItemSharesList.ShareResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "creator",
+                "expires_at",
+                "id",
+                "max_views",
+                "view_count"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "creator": {
+                    "$ref": "#/definitions/controllers.HandleVaultItemSharesList.ShareCreatorData"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_views": {
+                    "type": "integer"
+                },
+                "view_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.HandleVaultItemsCreate.VaultItemCreateRequest": {
+            "type": "object",
+            "required": [
+                "encryption_iv",
+                "title"
+            ],
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
+                "encrypted_note": {
+                    "type": "string"
+                },
+                "encrypted_password": {
+                    "type": "string"
+                },
+                "encrypted_username": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemsCreate.VaultItemCreateResponse": {
+            "type": "object",
+            "required": [
+                "encrypted_note",
+                "encrypted_password",
+                "encrypted_username",
+                "encryption_iv",
+                "id",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
+                "encrypted_note": {
+                    "type": "string"
+                },
+                "encrypted_password": {
+                    "type": "string"
+                },
+                "encrypted_username": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemsList.VaultItemResponseItem": {
+            "type": "object",
+            "required": [
+                "id",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemsRetrieve.VaultItemRetrieveResponse": {
+            "type": "object",
+            "required": [
+                "encrypted_note",
+                "encrypted_password",
+                "encrypted_username",
+                "encryption_iv",
+                "id",
+                "title",
+                "type",
+                "updated_at"
+            ],
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
+                "encrypted_note": {
+                    "type": "string"
+                },
+                "encrypted_password": {
+                    "type": "string"
+                },
+                "encrypted_username": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemsUpdate.VaultItemUpdateRequest": {
+            "type": "object",
+            "required": [
+                "encryption_iv",
+                "title"
+            ],
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
+                "encrypted_note": {
+                    "type": "string"
+                },
+                "encrypted_password": {
+                    "type": "string"
+                },
+                "encrypted_username": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultItemsUpdate.VaultItemUpdateResponse": {
+            "type": "object",
+            "required": [
+                "encrypted_note",
+                "encrypted_password",
+                "encrypted_username",
+                "encryption_iv",
+                "id",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
+                "encrypted_note": {
+                    "type": "string"
+                },
+                "encrypted_password": {
+                    "type": "string"
+                },
+                "encrypted_username": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultTrashList.DeletedVaultItemResponseItem": {
+            "type": "object",
+            "required": [
+                "deleted_at",
+                "id",
+                "title"
+            ],
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultsCreate.VaultCreateRequest": {
+            "type": "object",
+            "required": [
+                "encrypted_vault_key",
+                "encryption_iv",
+                "name"
+            ],
+            "properties": {
+                "encrypted_vault_key": {
+                    "type": "string"
+                },
+                "encryption_iv": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultsCreate.VaultCreateResponse": {
+            "type": "object",
+            "required": [
+                "id",
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultsList.VaultResponseItem": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "id",
+                "name",
+                "updated_at"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultsListTrash.DeletedVaultResponseItem": {
+            "type": "object",
+            "required": [
+                "deleted_at",
+                "id",
+                "name"
+            ],
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultsManageAddGroup.AddGroupRequest": {
+            "type": "object",
+            "required": [
+                "group_id",
+                "permissions"
+            ],
+            "properties": {
+                "group_id": {
+                    "type": "integer"
+                },
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.sharedVaultKey"
+                    }
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.HandleVaultsManageAddUser.AddUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "encrypted_vault_key",
+                "permissions",
+                "vault_key_encryption_iv"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "encrypted_vault_key": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "vault_key_encryption_iv": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultsManageCompleteInvitation.CompleteInvitationRequest": {
+            "type": "object",
+            "required": [
+                "encrypted_vault_key",
+                "vault_key_encryption_iv"
+            ],
+            "properties": {
+                "encrypted_vault_key": {
+                    "type": "string"
+                },
+                "vault_key_encryption_iv": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.HandleVaultsManageInviteUser.InviteUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "permissions"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.HandleVaultsManageInviteUser.InviteUserResponse": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleAuthLogin.LoginRequest": {
+        "controllers.HandleVaultsManageListGroups.GroupsResponseItem": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "id",
+                "name",
+                "permissions"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "password": {
+                "name": {
                     "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "controllers.HandleAuthLogin.LoginResponse": {
+        "controllers.HandleVaultsManageListInvitations.InvitationResponseItem": {
             "type": "object",
             "required": [
+                "created_at",
                 "email",
-                "key_derivation_salt",
-                "name"
+                "id",
+                "inviter_email",
+                "is_accepted",
+                "permissions"
             ],
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
-                "key_derivation_salt": {
+                "id": {
+                    "type": "integer"
+                },
+                "invitee_user_id": {
+                    "type": "integer"
+                },
+                "invitee_user_public_key": {
                     "type": "string"
                 },
-                "name": {
+                "inviter_email": {
                     "type": "string"
+                },
+                "is_accepted": {
+                    "type": "boolean"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "controllers.HandleAuthRegister.RegisterRequest": {
+        "controllers.HandleVaultsManageListPendingKeys.PendingKeyResponseItem": {
             "type": "object",
             "required": [
                 "email",
-                "key_derivation_salt",
-                "name",
-                "password",
-                "public_key"
+                "public_key",
+                "user_id"
             ],
             "properties": {
                 "email": {
                     "type": "string"
                 },
-                "key_derivation_salt": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
-                },
                 "public_key": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleGetUserByEmail.UserResponse": {
+        "controllers.HandleVaultsManageListUsers.UsersResponseItem": {
             "type": "object",
             "required": [
                 "email",
-                "name",
-                "public_key"
+                "id",
+                "is_owner",
+                "permissions"
             ],
             "properties": {
                 "email": {
                     "type": "string"
                 },
-                "name": {
+                "expires_at": {
                     "type": "string"
                 },
-                "public_key": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
+                },
+                "is_owner": {
+                    "type": "boolean"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "controllers.HandleMetricsStatus.MetricsStatusResponse": {
+        "controllers.HandleVaultsManageRemoveUser.RemoveUserRequest": {
             "type": "object",
             "required": [
-                "status"
+                "user_id"
             ],
             "properties": {
-                "status": {
-                    "type": "string"
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleUsersMe.MeResponse": {
+        "controllers.HandleVaultsManageRename.RenameVaultRequest": {
             "type": "object",
             "required": [
-                "email",
                 "name"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
                 "name": {
                     "type": "string"
                 }
             }
         },
-        "controllers.HandleVaultAuditLogsList.AuditLogResponseItem": {
+        "controllers.HandleVaultsManageRename.RenameVaultResponse": {
             "type": "object",
             "required": [
-                "action_code",
-                "action_data",
-                "created_at",
-                "id",
-                "user"
+                "name"
             ],
             "properties": {
-                "action_code": {
-                    "$ref": "#/definitions/models.AuditLogAction"
-                },
-                "action_data": {
-                    "type": "object",
-                    "additionalProperties": {}
-                },
-                "created_at": {
+                "name": {
                     "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "user": {
-                    "$ref": "#/definitions/controllers.HandleVaultAuditLogsList.UserData"
-                },
-                "vault_item": {
-                    "$ref": "#/definitions/controllers.HandleVaultAuditLogsList.VaultItemData"
                 }
             }
         },
-        "controllers.HandleVaultAuditLogsList.UserData": {
+        "controllers.HandleVaultsManageRotateKey.RotateKeyRequest": {
             "type": "object",
             "required": [
-                "email",
-                "id"
+                "items",
+                "keys"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleVaultsManageRotateKey.RotatedAttachment"
+                    }
                 },
-                "id": {
-                    "type": "integer"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleVaultsManageRotateKey.RotatedItem"
+                    }
+                },
+                "keys": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleVaultsManageRotateKey.RotatedKey"
+                    }
                 }
             }
         },
-        "controllers.HandleVaultAuditLogsList.VaultItemData": {
+        "controllers.HandleVaultsManageRotateKey.RotatedAttachment": {
             "type": "object",
             "required": [
-                "id",
-                "title"
+                "encrypted_metadata",
+                "encryption_iv",
+                "id"
             ],
             "properties": {
-                "id": {
-                    "type": "integer"
+                "encrypted_metadata": {
+                    "type": "string"
                 },
-                "title": {
+                "encryption_iv": {
                     "type": "string"
+                },
+                "id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultItemsCreate.VaultItemCreateRequest": {
+        "controllers.HandleVaultsManageRotateKey.RotatedItem": {
             "type": "object",
             "required": [
                 "encryption_iv",
-                "title"
+                "id"
             ],
             "properties": {
+                "data": {
+                    "$ref": "#/definitions/controllers.vaultItemData"
+                },
                 "encrypted_note": {
                     "type": "string"
                 },
@@ -1303,145 +7691,96 @@ const docTemplate = `{
                 "encryption_iv": {
                     "type": "string"
                 },
-                "title": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultItemsCreate.VaultItemCreateResponse": {
+        "controllers.HandleVaultsManageRotateKey.RotatedKey": {
             "type": "object",
             "required": [
-                "encrypted_note",
-                "encrypted_password",
-                "encrypted_username",
+                "encrypted_vault_key",
                 "encryption_iv",
-                "id",
-                "title"
+                "user_id"
             ],
             "properties": {
-                "encrypted_note": {
-                    "type": "string"
-                },
-                "encrypted_password": {
-                    "type": "string"
-                },
-                "encrypted_username": {
+                "encrypted_vault_key": {
                     "type": "string"
                 },
                 "encryption_iv": {
                     "type": "string"
                 },
-                "id": {
+                "user_id": {
                     "type": "integer"
-                },
-                "title": {
-                    "type": "string"
                 }
             }
         },
-        "controllers.HandleVaultItemsList.VaultItemResponseItem": {
+        "controllers.HandleVaultsManageSharePendingKeys.SharePendingKeysRequest": {
             "type": "object",
             "required": [
-                "id",
-                "title"
+                "keys"
             ],
             "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "title": {
-                    "type": "string"
+                "keys": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/controllers.sharedVaultKey"
+                    }
                 }
             }
         },
-        "controllers.HandleVaultItemsRetrieve.VaultItemRetrieveResponse": {
+        "controllers.HandleVaultsManageTransferOwnership.TransferOwnershipRequest": {
             "type": "object",
             "required": [
-                "encrypted_note",
-                "encrypted_password",
-                "encrypted_username",
-                "encryption_iv",
-                "id",
-                "title",
-                "updated_at"
+                "user_id"
             ],
             "properties": {
-                "encrypted_note": {
-                    "type": "string"
-                },
-                "encrypted_password": {
-                    "type": "string"
-                },
-                "encrypted_username": {
-                    "type": "string"
-                },
-                "encryption_iv": {
-                    "type": "string"
-                },
-                "id": {
+                "user_id": {
                     "type": "integer"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
                 }
             }
         },
-        "controllers.HandleVaultItemsUpdate.VaultItemUpdateRequest": {
+        "controllers.HandleVaultsManageUpdateUserPermissions.UpdatePermissionsRequest": {
             "type": "object",
             "required": [
-                "title"
+                "permissions"
             ],
             "properties": {
-                "encrypted_note": {
-                    "type": "string"
-                },
-                "encrypted_password": {
-                    "type": "string"
-                },
-                "encrypted_username": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "controllers.HandleVaultItemsUpdate.VaultItemUpdateResponse": {
+        "controllers.HandleVaultsManageUpdateUserPermissions.UpdatePermissionsResponse": {
             "type": "object",
             "required": [
-                "encrypted_note",
-                "encrypted_password",
-                "encrypted_username",
                 "id",
-                "title"
+                "permissions"
             ],
             "properties": {
-                "encrypted_note": {
-                    "type": "string"
-                },
-                "encrypted_password": {
-                    "type": "string"
-                },
-                "encrypted_username": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "integer"
                 },
-                "title": {
-                    "type": "string"
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "controllers.HandleVaultsCreate.VaultCreateRequest": {
+        "controllers.HandleVaultsMyKey.VaultKeyResponse": {
             "type": "object",
             "required": [
                 "encrypted_vault_key",
                 "encryption_iv",
-                "name"
+                "inviter_user_id",
+                "inviter_user_public_key",
+                "key_owner_user_id"
             ],
             "properties": {
                 "encrypted_vault_key": {
@@ -1450,153 +7789,139 @@ const docTemplate = `{
                 "encryption_iv": {
                     "type": "string"
                 },
-                "name": {
+                "inviter_user_id": {
+                    "type": "integer"
+                },
+                "inviter_user_public_key": {
                     "type": "string"
+                },
+                "key_owner_user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultsCreate.VaultCreateResponse": {
+        "controllers.issuedVaultKey": {
             "type": "object",
             "required": [
                 "id",
-                "name"
+                "key_owner_user_id",
+                "key_owner_user_public_key",
+                "vault_id"
             ],
             "properties": {
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "key_owner_user_id": {
+                    "type": "integer"
+                },
+                "key_owner_user_public_key": {
                     "type": "string"
+                },
+                "vault_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultsList.VaultResponseItem": {
+        "controllers.ownedVaultKey": {
             "type": "object",
             "required": [
-                "created_at",
+                "encrypted_vault_key",
+                "encryption_iv",
                 "id",
-                "name",
-                "updated_at"
+                "inviter_user_public_key",
+                "vault_id"
             ],
             "properties": {
-                "created_at": {
+                "encrypted_vault_key": {
+                    "type": "string"
+                },
+                "encryption_iv": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "inviter_user_public_key": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
+                "vault_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultsManageAddUser.AddUserRequest": {
+        "controllers.privateKeyEnvelope": {
             "type": "object",
             "required": [
-                "email",
-                "encrypted_vault_key",
-                "permissions",
-                "vault_key_encryption_iv"
+                "encrypted_private_key",
+                "encryption_iv",
+                "version"
             ],
             "properties": {
-                "email": {
+                "encrypted_private_key": {
                     "type": "string"
                 },
-                "encrypted_vault_key": {
+                "encryption_iv": {
                     "type": "string"
                 },
-                "permissions": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "vault_key_encryption_iv": {
-                    "type": "string"
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultsManageListUsers.UsersResponseItem": {
+        "controllers.rewrappedVaultKey": {
             "type": "object",
             "required": [
-                "email",
-                "id",
-                "permissions"
+                "encrypted_vault_key",
+                "encryption_iv",
+                "vault_key_id"
             ],
             "properties": {
-                "email": {
+                "encrypted_vault_key": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "encryption_iv": {
+                    "type": "string"
                 },
-                "permissions": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                }
-            }
-        },
-        "controllers.HandleVaultsManageRemoveUser.RemoveUserRequest": {
-            "type": "object",
-            "required": [
-                "user_id"
-            ],
-            "properties": {
-                "user_id": {
+                "vault_key_id": {
                     "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultsManageRename.RenameVaultRequest": {
+        "controllers.sharedVaultKey": {
             "type": "object",
             "required": [
-                "name"
+                "encrypted_vault_key",
+                "encryption_iv",
+                "user_id"
             ],
             "properties": {
-                "name": {
+                "encrypted_vault_key": {
                     "type": "string"
-                }
-            }
-        },
-        "controllers.HandleVaultsManageRename.RenameVaultResponse": {
-            "type": "object",
-            "required": [
-                "name"
-            ],
-            "properties": {
-                "name": {
+                },
+                "encryption_iv": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.HandleVaultsMyKey.VaultKeyResponse": {
+        "controllers.vaultItemData": {
             "type": "object",
             "required": [
-                "encrypted_vault_key",
+                "encrypted_data",
                 "encryption_iv",
-                "inviter_user_id",
-                "inviter_user_public_key",
-                "key_owner_user_id"
+                "version"
             ],
             "properties": {
-                "encrypted_vault_key": {
+                "encrypted_data": {
                     "type": "string"
                 },
                 "encryption_iv": {
                     "type": "string"
                 },
-                "inviter_user_id": {
-                    "type": "integer"
-                },
-                "inviter_user_public_key": {
-                    "type": "string"
-                },
-                "key_owner_user_id": {
+                "version": {
                     "type": "integer"
                 }
             }
@@ -1612,7 +7937,26 @@ const docTemplate = `{
                 "vault_user_left",
                 "vault_item_create",
                 "vault_item_update",
-                "vault_item_delete"
+                "vault_item_delete",
+                "vault_item_restore_revision",
+                "vault_item_restore",
+                "vault_restore",
+                "vault_update_permissions",
+                "vault_transfer_ownership",
+                "vault_key_rotated",
+                "vault_add_group",
+                "vault_remove_group",
+                "vault_share_keys",
+                "vault_invite_user",
+                "vault_revoke_invitation",
+                "vault_accept_invitation",
+                "vault_decline_invitation",
+                "vault_access_expired",
+                "vault_item_share_create",
+                "vault_item_share_view",
+                "vault_item_share_revoke",
+                "vault_item_attachment_add",
+                "vault_item_attachment_delete"
             ],
             "x-enum-varnames": [
                 "AuditLogActionVaultCreate",
@@ -1623,9 +7967,82 @@ const docTemplate = `{
                 "AuditLogActionVaultUserLeft",
                 "AuditLogActionVaultItemCreate",
                 "AuditLogActionVaultItemUpdate",
-                "AuditLogActionVaultItemDelete"
+                "AuditLogActionVaultItemDelete",
+                "AuditLogActionVaultItemRestoreRevision",
+                "AuditLogActionVaultItemRestore",
+                "AuditLogActionVaultRestore",
+                "AuditLogActionVaultUpdatePermissions",
+                "AuditLogActionVaultTransferOwnership",
+                "AuditLogActionVaultKeyRotated",
+                "AuditLogActionVaultAddGroup",
+                "AuditLogActionVaultRemoveGroup",
+                "AuditLogActionVaultShareKeys",
+                "AuditLogActionVaultInviteUser",
+                "AuditLogActionVaultRevokeInvitation",
+                "AuditLogActionVaultAcceptInvitation",
+                "AuditLogActionVaultDeclineInvitation",
+                "AuditLogActionVaultAccessExpired",
+                "AuditLogActionVaultItemShareCreate",
+                "AuditLogActionVaultItemShareView",
+                "AuditLogActionVaultItemShareRevoke",
+                "AuditLogActionVaultItemAttachmentAdd",
+                "AuditLogActionVaultItemAttachmentDelete"
             ]
         },
+        "pagination.StandardPaginationResponse-controllers_HandleAdminInviteCodesList_InviteCodeResponseItem": {
+            "type": "object",
+            "required": [
+                "count",
+                "results"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleAdminInviteCodesList.InviteCodeResponseItem"
+                    }
+                }
+            }
+        },
+        "pagination.StandardPaginationResponse-controllers_HandleAdminUsersList_UserResponseItem": {
+            "type": "object",
+            "required": [
+                "count",
+                "results"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleAdminUsersList.UserResponseItem"
+                    }
+                }
+            }
+        },
+        "pagination.StandardPaginationResponse-controllers_HandleUsersMeFailedLoginsList_FailedLoginResponseItem": {
+            "type": "object",
+            "required": [
+                "count",
+                "results"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleUsersMeFailedLoginsList.FailedLoginResponseItem"
+                    }
+                }
+            }
+        },
         "pagination.StandardPaginationResponse-controllers_HandleVaultAuditLogsList_AuditLogResponseItem": {
             "type": "object",
             "required": [
@@ -1644,6 +8061,24 @@ const docTemplate = `{
                 }
             }
         },
+        "pagination.StandardPaginationResponse-controllers_HandleVaultItemRevisionsList_RevisionResponseItem": {
+            "type": "object",
+            "required": [
+                "count",
+                "results"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleVaultItemRevisionsList.RevisionResponseItem"
+                    }
+                }
+            }
+        },
         "pagination.StandardPaginationResponse-controllers_HandleVaultItemsList_VaultItemResponseItem": {
             "type": "object",
             "required": [
@@ -1662,6 +8097,42 @@ const docTemplate = `{
                 }
             }
         },
+        "pagination.StandardPaginationResponse-controllers_HandleVaultTrashList_DeletedVaultItemResponseItem": {
+            "type": "object",
+            "required": [
+                "count",
+                "results"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleVaultTrashList.DeletedVaultItemResponseItem"
+                    }
+                }
+            }
+        },
+        "pagination.StandardPaginationResponse-controllers_HandleVaultsListTrash_DeletedVaultResponseItem": {
+            "type": "object",
+            "required": [
+                "count",
+                "results"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.HandleVaultsListTrash.DeletedVaultResponseItem"
+                    }
+                }
+            }
+        },
         "pagination.StandardPaginationResponse-controllers_HandleVaultsList_VaultResponseItem": {
             "type": "object",
             "required": [
@@ -1691,6 +8162,28 @@ const docTemplate = `{
                 }
             }
         },
+        "schemas.ConflictResponse": {
+            "type": "object",
+            "required": [
+                "error"
+            ],
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.ForbiddenResponse": {
+            "type": "object",
+            "required": [
+                "error"
+            ],
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
         "schemas.NotFoundResponse": {
             "type": "object",
             "required": [
@@ -1701,6 +8194,28 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "schemas.PayloadTooLargeResponse": {
+            "type": "object",
+            "required": [
+                "error"
+            ],
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.TooManyRequestsResponse": {
+            "type": "object",
+            "required": [
+                "error"
+            ],
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
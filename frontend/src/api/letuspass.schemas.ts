@@ -168,6 +168,7 @@ export interface ControllersHandleVaultItemsUpdateVaultItemUpdateResponse {
   encrypted_note: string;
   encrypted_password: string;
   encrypted_username: string;
+  encryption_iv: string;
   id: number;
   title: string;
 }
@@ -176,6 +177,7 @@ export interface ControllersHandleVaultItemsUpdateVaultItemUpdateRequest {
   encrypted_note?: string;
   encrypted_password?: string;
   encrypted_username?: string;
+  encryption_iv: string;
   title: string;
 }
 
@@ -26,13 +26,11 @@ export default function EditItemButtonAndModal({
   vaultId,
   vaultItemId,
   vaultKey,
-  vaultItemEncryptionIV,
   currentPlainValues,
 }: {
   vaultId: number;
   vaultItemId: number;
   vaultKey: string;
-  vaultItemEncryptionIV: string;
   currentPlainValues: {
     title: string;
     username: string;
@@ -91,29 +89,20 @@ export default function EditItemButtonAndModal({
   });
 
   const handleSubmit = async (values: typeof form.values) => {
+    // a new IV is required on every edit, reusing the previous one would leak the previous values
+    const encryptionIV = AESService.generateRandomIV();
     updateVaultItemMutation.mutate({
       title: values.title,
+      encryption_iv: encryptionIV,
       encrypted_username:
         values.username &&
-        (await AESService.encrypt(
-          vaultKey,
-          vaultItemEncryptionIV,
-          values.username
-        )),
+        (await AESService.encrypt(vaultKey, encryptionIV, values.username)),
       encrypted_password:
         values.password &&
-        (await AESService.encrypt(
-          vaultKey,
-          vaultItemEncryptionIV,
-          values.password
-        )),
+        (await AESService.encrypt(vaultKey, encryptionIV, values.password)),
       encrypted_note:
         values.notes &&
-        (await AESService.encrypt(
-          vaultKey,
-          vaultItemEncryptionIV,
-          values.notes
-        )),
+        (await AESService.encrypt(vaultKey, encryptionIV, values.notes)),
     });
   };
 